@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"service_cart/dto"
 	"service_cart/internal/usecase"
 	"time"
 
@@ -34,19 +35,30 @@ func ValidationResponseConsumer(redis *redis.Client, usecase usecase.CartUsecase
 				continue
 			}
 
-			corrID := payload["correlation_id"].(string)
-			deleted := payload["deleted"].(bool)
-			stock := payload["stock"].(int)
-			productId := payload["product_id"].(uint)
+			// angka dari json selalu float64
+			corrID, _ := payload["correlation_id"].(string)
+			deleted, _ := payload["deleted"].(bool)
+			stock, _ := payload["stock"].(float64)
+			price, _ := payload["price"].(float64)
+			productIdRaw, _ := payload["product_id"].(float64)
+			productId := uint(productIdRaw)
 
 			if _, errBreaker := breaker.Execute(func() (interface{}, error) {
+				// product yang dihapus dikeluarkan dari cart dan wishlist, balasan
+				// tetap dikirim karena bisa jadi jawaban validasi yang sedang ditunggu
 				if deleted {
-					return nil, usecase.UpdateIsDeleteProduct(productId)
+					if err := usecase.UpdateIsDeleteProduct(productId); err != nil {
+						return nil, err
+					}
+				}
+				if corrID == "" {
+					return nil, nil
 				}
 
 				data := map[string]interface{}{
 					"deleted": deleted,
-					"stock":   stock,
+					"stock":   int(stock),
+					"price":   int64(price),
 				}
 				jsonData, _ := json.Marshal(data)
 
@@ -55,10 +67,433 @@ func ValidationResponseConsumer(redis *redis.Client, usecase usecase.CartUsecase
 				return redis.Set(context.Background(), key, jsonData, 10*time.Second).Result()
 
 			}); errBreaker != nil {
-				fmt.Println("update produt failed or redis failed or breaker open:", err)
+				fmt.Println("update produt failed or redis failed or breaker open:", errBreaker)
+				continue
+			}
+
+		}
+	}()
+}
+
+func PriceChangedConsumer(usecase usecase.CartUsecase, breaker *gobreaker.CircuitBreaker) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   "product-price-changed",
+		GroupID: "cart-service",
+	})
+
+	go func() {
+		for {
+			msg, err := r.ReadMessage(context.Background())
+			if err != nil {
+				fmt.Println("error reading message:", err)
+				continue
+			}
+
+			var payload dto.PriceChangedKafka
+			if err := json.Unmarshal(msg.Value, &payload); err != nil {
+				fmt.Println("error unmarshaling:", err)
+				continue
+			}
+
+			if _, errBreaker := breaker.Execute(func() (interface{}, error) {
+				return nil, usecase.UpdateCurrentPrice(payload.ProductID, payload.NewPrice)
+			}); errBreaker != nil {
+				fmt.Println("update price failed or breaker open:", errBreaker)
+				continue
+			}
+		}
+	}()
+}
+
+func ProductDetailResponseConsumer(redisClient *redis.Client, breaker *gobreaker.CircuitBreaker) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   "products-detail-response",
+		GroupID: "cart-service",
+	})
+
+	go func() {
+		for {
+			msg, err := r.ReadMessage(context.Background())
+			if err != nil {
+				fmt.Println("error reading message:", err)
+				continue
+			}
+
+			var payload map[string]interface{}
+			if err := json.Unmarshal(msg.Value, &payload); err != nil {
+				fmt.Println("error unmarshaling:", err)
+				continue
+			}
+
+			corrID, _ := payload["correlation_id"].(string)
+			data, _ := json.Marshal(payload["data"])
+
+			key := fmt.Sprintf("response:%s", corrID)
+			if _, errBreaker := breaker.Execute(func() (interface{}, error) {
+				return redisClient.Set(context.Background(), key, data, 10*time.Second).Result()
+			}); errBreaker != nil {
+				fmt.Println("redis SET failed or breaker open:", errBreaker)
+				continue
+			}
+		}
+	}()
+}
+
+func StockReserveResponseConsumer(saga usecase.CheckoutSaga, breaker *gobreaker.CircuitBreaker) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   "stock-reserve-response",
+		GroupID: "cart-service",
+	})
+
+	go func() {
+		for {
+			msg, err := r.ReadMessage(context.Background())
+			if err != nil {
+				fmt.Println("error reading message:", err)
+				continue
+			}
+
+			var payload dto.SagaReplyKafka
+			if err := json.Unmarshal(msg.Value, &payload); err != nil {
+				fmt.Println("error unmarshaling:", err)
+				continue
+			}
+
+			if _, errBreaker := breaker.Execute(func() (interface{}, error) {
+				return nil, saga.HandleStockReserved(&payload)
+			}); errBreaker != nil {
+				fmt.Println("saga stock reply failed or breaker open:", errBreaker)
 				continue
 			}
+		}
+	}()
+}
 
+func PaymentChargeResponseConsumer(saga usecase.CheckoutSaga, breaker *gobreaker.CircuitBreaker) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   "payment-charge-response",
+		GroupID: "cart-service",
+	})
+
+	go func() {
+		for {
+			msg, err := r.ReadMessage(context.Background())
+			if err != nil {
+				fmt.Println("error reading message:", err)
+				continue
+			}
+
+			var payload dto.SagaReplyKafka
+			if err := json.Unmarshal(msg.Value, &payload); err != nil {
+				fmt.Println("error unmarshaling:", err)
+				continue
+			}
+
+			if _, errBreaker := breaker.Execute(func() (interface{}, error) {
+				return nil, saga.HandlePaymentCharged(&payload)
+			}); errBreaker != nil {
+				fmt.Println("saga payment reply failed or breaker open:", errBreaker)
+				continue
+			}
+		}
+	}()
+}
+
+func PaymentChargeRequestConsumer(usecase usecase.PaymentUsecase, breaker *gobreaker.CircuitBreaker) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   "payment-charge-request",
+		GroupID: "cart-service",
+	})
+
+	go func() {
+		for {
+			msg, err := r.ReadMessage(context.Background())
+			if err != nil {
+				fmt.Println("error reading message:", err)
+				continue
+			}
+
+			var payload dto.PaymentChargeKafka
+			if err := json.Unmarshal(msg.Value, &payload); err != nil {
+				fmt.Println("error unmarshaling:", err)
+				continue
+			}
+
+			if _, errBreaker := breaker.Execute(func() (interface{}, error) {
+				return nil, usecase.Charge(&payload)
+			}); errBreaker != nil {
+				fmt.Println("payment charge failed or breaker open:", errBreaker)
+				continue
+			}
+		}
+	}()
+}
+
+func PaymentRefundRequestConsumer(usecase usecase.PaymentUsecase, breaker *gobreaker.CircuitBreaker) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   "payment-refund-request",
+		GroupID: "cart-service",
+	})
+
+	go func() {
+		for {
+			msg, err := r.ReadMessage(context.Background())
+			if err != nil {
+				fmt.Println("error reading message:", err)
+				continue
+			}
+
+			var payload dto.PaymentRefundKafka
+			if err := json.Unmarshal(msg.Value, &payload); err != nil {
+				fmt.Println("error unmarshaling:", err)
+				continue
+			}
+
+			if _, errBreaker := breaker.Execute(func() (interface{}, error) {
+				return nil, usecase.Refund(&payload)
+			}); errBreaker != nil {
+				fmt.Println("payment refund failed or breaker open:", errBreaker)
+				continue
+			}
+		}
+	}()
+}
+
+func StoreValidationResponseConsumer(redisClient *redis.Client, breaker *gobreaker.CircuitBreaker) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   "store-validation-response",
+		GroupID: "cart-service",
+	})
+
+	go func() {
+		for {
+			msg, err := r.ReadMessage(context.Background())
+			if err != nil {
+				fmt.Println("error reading message:", err)
+				continue
+			}
+
+			var payload map[string]interface{}
+			if err := json.Unmarshal(msg.Value, &payload); err != nil {
+				fmt.Println("error unmarshaling:", err)
+				continue
+			}
+
+			corrID, _ := payload["correlation_id"].(string)
+			data, _ := json.Marshal(payload["is_valid"])
+
+			key := fmt.Sprintf("response:%s", corrID)
+			if _, errBreaker := breaker.Execute(func() (interface{}, error) {
+				return redisClient.Set(context.Background(), key, data, 10*time.Second).Result()
+			}); errBreaker != nil {
+				fmt.Println("redis SET failed or breaker open:", errBreaker)
+				continue
+			}
+		}
+	}()
+}
+
+func StoresDetailResponseConsumer(redisClient *redis.Client, breaker *gobreaker.CircuitBreaker) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   "stores-detail-response",
+		GroupID: "cart-service",
+	})
+
+	go func() {
+		for {
+			msg, err := r.ReadMessage(context.Background())
+			if err != nil {
+				fmt.Println("error reading message:", err)
+				continue
+			}
+
+			var payload map[string]interface{}
+			if err := json.Unmarshal(msg.Value, &payload); err != nil {
+				fmt.Println("error unmarshaling:", err)
+				continue
+			}
+
+			corrID, _ := payload["correlation_id"].(string)
+			data, _ := json.Marshal(payload["data"])
+
+			key := fmt.Sprintf("response:%s", corrID)
+			if _, errBreaker := breaker.Execute(func() (interface{}, error) {
+				return redisClient.Set(context.Background(), key, data, 10*time.Second).Result()
+			}); errBreaker != nil {
+				fmt.Println("redis SET failed or breaker open:", errBreaker)
+				continue
+			}
+		}
+	}()
+}
+
+func AddressResponseConsumer(redisClient *redis.Client, breaker *gobreaker.CircuitBreaker) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   "address-response",
+		GroupID: "cart-service",
+	})
+
+	go func() {
+		for {
+			msg, err := r.ReadMessage(context.Background())
+			if err != nil {
+				fmt.Println("error reading message:", err)
+				continue
+			}
+
+			var payload map[string]interface{}
+			if err := json.Unmarshal(msg.Value, &payload); err != nil {
+				fmt.Println("error unmarshaling:", err)
+				continue
+			}
+
+			corrID, _ := payload["correlation_id"].(string)
+			data, _ := json.Marshal(payload["data"])
+
+			key := fmt.Sprintf("response:%s", corrID)
+			if _, errBreaker := breaker.Execute(func() (interface{}, error) {
+				return redisClient.Set(context.Background(), key, data, 10*time.Second).Result()
+			}); errBreaker != nil {
+				fmt.Println("redis SET failed or breaker open:", errBreaker)
+				continue
+			}
+		}
+	}()
+}
+
+func UserContactResponseConsumer(redisClient *redis.Client, breaker *gobreaker.CircuitBreaker) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   "user-contact-response",
+		GroupID: "cart-service",
+	})
+
+	go func() {
+		for {
+			msg, err := r.ReadMessage(context.Background())
+			if err != nil {
+				fmt.Println("error reading message:", err)
+				continue
+			}
+
+			var payload map[string]interface{}
+			if err := json.Unmarshal(msg.Value, &payload); err != nil {
+				fmt.Println("error unmarshaling:", err)
+				continue
+			}
+
+			corrID, _ := payload["correlation_id"].(string)
+			data, _ := json.Marshal(payload["data"])
+
+			key := fmt.Sprintf("response:%s", corrID)
+			if _, errBreaker := breaker.Execute(func() (interface{}, error) {
+				return redisClient.Set(context.Background(), key, data, 10*time.Second).Result()
+			}); errBreaker != nil {
+				fmt.Println("redis SET failed or breaker open:", errBreaker)
+				continue
+			}
+		}
+	}()
+}
+
+func ReturnRefundResponseConsumer(usecase usecase.ReturnUsecase, breaker *gobreaker.CircuitBreaker) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   "return-refund-response",
+		GroupID: "cart-service",
+	})
+
+	go func() {
+		for {
+			msg, err := r.ReadMessage(context.Background())
+			if err != nil {
+				fmt.Println("error reading message:", err)
+				continue
+			}
+
+			var payload dto.ReturnRefundReplyKafka
+			if err := json.Unmarshal(msg.Value, &payload); err != nil {
+				fmt.Println("error unmarshaling:", err)
+				continue
+			}
+
+			if _, errBreaker := breaker.Execute(func() (interface{}, error) {
+				return nil, usecase.CompleteRefund(&payload)
+			}); errBreaker != nil {
+				fmt.Println("complete return refund failed or breaker open:", errBreaker)
+				continue
+			}
+		}
+	}()
+}
+
+func StoreShipmentRequestConsumer(usecase usecase.ShippingUsecase, breaker *gobreaker.CircuitBreaker) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   "store-shipment-request",
+		GroupID: "cart-service",
+	})
+
+	go func() {
+		for {
+			msg, err := r.ReadMessage(context.Background())
+			if err != nil {
+				fmt.Println("error reading message:", err)
+				continue
+			}
+
+			var payload dto.StoreShipmentReqKafka
+			if err := json.Unmarshal(msg.Value, &payload); err != nil {
+				fmt.Println("error unmarshaling:", err)
+				continue
+			}
+
+			if _, errBreaker := breaker.Execute(func() (interface{}, error) {
+				return nil, usecase.SendStoreShipmentResponse(&payload)
+			}); errBreaker != nil {
+				fmt.Println("store shipment request failed or breaker open:", errBreaker)
+				continue
+			}
+		}
+	}()
+}
+
+func GuestCartMergeConsumer(usecase usecase.GuestCartUsecase, breaker *gobreaker.CircuitBreaker) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   "guest-cart-merge",
+		GroupID: "cart-service",
+	})
+
+	go func() {
+		for {
+			msg, err := r.ReadMessage(context.Background())
+			if err != nil {
+				fmt.Println("error reading message:", err)
+				continue
+			}
+
+			var payload dto.GuestCartMergeKafka
+			if err := json.Unmarshal(msg.Value, &payload); err != nil {
+				fmt.Println("error unmarshaling:", err)
+				continue
+			}
+
+			if _, errBreaker := breaker.Execute(func() (interface{}, error) {
+				return usecase.MergeIntoUser(payload.UserID, payload.Email, payload.GuestToken)
+			}); errBreaker != nil {
+				fmt.Println("merge guest cart failed or breaker open:", errBreaker)
+				continue
+			}
 		}
 	}()
 }
@@ -8,6 +8,10 @@ import (
 	"service_cart/cmd/database"
 	kafkaconsumer "service_cart/cmd/kafka_consumer"
 	"service_cart/cmd/route"
+	"service_cart/cmd/scheduler"
+	"service_cart/helper/payment"
+	"service_cart/helper/shipping"
+	"service_cart/helper/tax"
 	"service_cart/internal/handler"
 	"service_cart/internal/repository"
 	"service_cart/internal/usecase"
@@ -26,16 +30,145 @@ func main() {
 	cartRepo := repository.NewCartRepo(db, rdb)
 
 	writes := map[string]*kafka.Writer{
-		"product-validation-response": kafka.NewWriter(kafka.WriterConfig{
+		"product-validation-request": kafka.NewWriter(kafka.WriterConfig{
 			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
-			Topic:    "product-validation-response",
+			Topic:    "product-validation-request",
+			Balancer: &kafka.LeastBytes{},
+		}),
+		"cart-item-paid": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "cart-item-paid",
+			Balancer: &kafka.LeastBytes{},
+		}),
+		"products-detail-request": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "products-detail-request",
+			Balancer: &kafka.LeastBytes{},
+		}),
+		"notification-request": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "notification-request",
+			Balancer: &kafka.LeastBytes{},
+		}),
+		"stock-reserve-request": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "stock-reserve-request",
+			Balancer: &kafka.LeastBytes{},
+		}),
+		"stock-release-request": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "stock-release-request",
+			Balancer: &kafka.LeastBytes{},
+		}),
+		"payment-charge-request": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "payment-charge-request",
+			Balancer: &kafka.LeastBytes{},
+		}),
+		"payment-refund-request": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "payment-refund-request",
+			Balancer: &kafka.LeastBytes{},
+		}),
+		"payment-charge-response": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "payment-charge-response",
+			Balancer: &kafka.LeastBytes{},
+		}),
+		"store-validation-request": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "store-validation-request",
+			Balancer: &kafka.LeastBytes{},
+		}),
+		"stores-detail-request": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "stores-detail-request",
+			Balancer: &kafka.LeastBytes{},
+		}),
+		"address-request": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "address-request",
+			Balancer: &kafka.LeastBytes{},
+		}),
+		"user-contact-request": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "user-contact-request",
+			Balancer: &kafka.LeastBytes{},
+		}),
+		"return-refund-response": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "return-refund-response",
+			Balancer: &kafka.LeastBytes{},
+		}),
+		"stock-restock-request": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "stock-restock-request",
+			Balancer: &kafka.LeastBytes{},
+		}),
+		"store-order-event": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "store-order-event",
+			Balancer: &kafka.Hash{},
+		}),
+		"store-shipment-response": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "store-shipment-response",
 			Balancer: &kafka.LeastBytes{},
 		}),
 	}
 	cartUC := usecase.NewCartUsecase(cartRepo, writes)
 	cartHandler := handler.NewCartpHandler(cartUC)
 
-	r := route.SetupRoute(cartHandler)
+	orderRepo := repository.NewOrderRepo(db, rdb)
+	sagaRepo := repository.NewSagaRepo(db)
+	invoiceRepo := repository.NewInvoiceRepo(db)
+	invoiceUC := usecase.NewInvoiceUsecase(invoiceRepo, orderRepo, cartRepo, writes)
+	invoiceHandler := handler.NewInvoiceHandler(invoiceUC)
+	sagaUC := usecase.NewCheckoutSaga(sagaRepo, orderRepo, cartRepo, invoiceUC, writes)
+	couponRepo := repository.NewCouponRepo(db)
+	couponUC := usecase.NewCouponUsecase(couponRepo, cartRepo, writes)
+	couponHandler := handler.NewCouponHandler(couponUC)
+
+	shippingRepo := repository.NewShippingRepo(db)
+	shippingUC := usecase.NewShippingUsecase(shippingRepo, orderRepo, cartRepo, shipping.NewTableCalculator(shippingRepo), writes)
+	shippingHandler := handler.NewShippingHandler(shippingUC)
+
+	returnRepo := repository.NewReturnRepo(db)
+	returnUC := usecase.NewReturnUsecase(returnRepo, orderRepo, cartRepo, writes)
+	returnHandler := handler.NewReturnHandler(returnUC)
+
+	taxRepo := repository.NewTaxRepo(db)
+	taxUC := usecase.NewTaxUsecase(taxRepo, cartRepo, tax.NewTableResolver(taxRepo), writes)
+	taxHandler := handler.NewTaxHandler(taxUC)
+
+	orderUC := usecase.NewOrderUsecase(orderRepo, cartRepo, couponUC, shippingUC, taxUC, sagaUC, writes)
+	orderHandler := handler.NewOrderHandler(orderUC)
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "3003"
+	}
+
+	webhookURL := os.Getenv("PAYMENT_WEBHOOK_URL")
+	if webhookURL == "" {
+		webhookURL = "http://localhost:" + port + "/payment/webhook"
+	}
+	mockDelay, _ := time.ParseDuration(os.Getenv("PAYMENT_MOCK_DELAY"))
+	gateway := payment.NewMockGateway(os.Getenv("PAYMENT_MOCK_MODE"), mockDelay, os.Getenv("PAYMENT_WEBHOOK_SECRET"), webhookURL)
+
+	paymentRepo := repository.NewPaymentRepo(db)
+	paymentUC := usecase.NewPaymentUsecase(paymentRepo, gateway, writes)
+	paymentHandler := handler.NewPaymentHandler(paymentUC)
+
+	guestRepo := repository.NewGuestCartRepo(rdb)
+	guestUC := usecase.NewGuestCartUsecase(guestRepo, cartRepo, writes)
+	guestHandler := handler.NewGuestCartHandler(guestUC)
+
+	wishlistRepo := repository.NewWishlistRepo(db, rdb)
+	wishlistUC := usecase.NewWishlistUsecase(wishlistRepo, cartRepo, cartUC, writes)
+	wishlistHandler := handler.NewWishlistHandler(wishlistUC)
+
+	r := route.SetupRoute(cartHandler, orderHandler, paymentHandler, couponHandler, guestHandler, wishlistHandler, invoiceHandler, shippingHandler, returnHandler, taxHandler)
 	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
 		Name:        "ConsumerBreaker",
 		MaxRequests: 3,
@@ -46,11 +179,36 @@ func main() {
 		},
 	})
 	go kafkaconsumer.ValidationResponseConsumer(rdb, cartUC, cb)
+	go kafkaconsumer.PriceChangedConsumer(cartUC, cb)
+	go kafkaconsumer.ProductDetailResponseConsumer(rdb, cb)
+	go kafkaconsumer.StoreValidationResponseConsumer(rdb, cb)
+	go kafkaconsumer.StoresDetailResponseConsumer(rdb, cb)
+	go kafkaconsumer.AddressResponseConsumer(rdb, cb)
+	go kafkaconsumer.UserContactResponseConsumer(rdb, cb)
+	go kafkaconsumer.GuestCartMergeConsumer(guestUC, cb)
+	go kafkaconsumer.StockReserveResponseConsumer(sagaUC, cb)
+	go kafkaconsumer.PaymentChargeResponseConsumer(sagaUC, cb)
+	go kafkaconsumer.PaymentChargeRequestConsumer(paymentUC, cb)
+	go kafkaconsumer.PaymentRefundRequestConsumer(paymentUC, cb)
+	go kafkaconsumer.ReturnRefundResponseConsumer(returnUC, cb)
+	go kafkaconsumer.StoreShipmentRequestConsumer(shippingUC, cb)
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "3003"
+	if err := sagaUC.Recover(); err != nil {
+		log.Printf("recover checkout saga err : %s", err)
+	}
+	scheduler.SagaTimeoutScheduler(sagaUC, 10*time.Second)
+
+	abandonAfter, err := time.ParseDuration(os.Getenv("CART_ABANDON_AFTER"))
+	if err != nil {
+		abandonAfter = 24 * time.Hour
+	}
+	expireAfter, err := time.ParseDuration(os.Getenv("CART_EXPIRE_AFTER"))
+	if err != nil {
+		expireAfter = 30 * 24 * time.Hour
 	}
+	abandonedRepo := repository.NewAbandonedCartRepo(db, rdb)
+	abandonedUC := usecase.NewAbandonedCartUsecase(abandonedRepo, cartRepo, abandonAfter, expireAfter, writes)
+	scheduler.AbandonedCartScheduler(abandonedUC, 10*time.Minute)
 
 	fmt.Printf("service cart berjalan pada port:%s", port)
 	http.ListenAndServe(":"+port, r)
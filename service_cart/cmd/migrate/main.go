@@ -13,7 +13,7 @@ func main() {
 		log.Fatal(err)
 	}
 
-	if err := db.AutoMigrate(&entity.CartItem{}); err != nil {
+	if err := db.AutoMigrate(&entity.CartItem{}, &entity.CartActivity{}, &entity.Wishlist{}, &entity.WishlistItem{}, &entity.Invoice{}, &entity.InvoiceSequence{}, &entity.ShippingRate{}, &entity.Shipment{}, &entity.Order{}, &entity.OrderItem{}, &entity.TaxRate{}, &entity.Saga{}, &entity.Payment{}, &entity.PaymentEvent{}, &entity.PaymentRefund{}, &entity.ReturnRequest{}, &entity.ReturnPhoto{}, &entity.Coupon{}, &entity.CouponRedemption{}, &entity.CartCoupon{}, &entity.OrderDiscount{}); err != nil {
 		log.Fatal(err)
 	}
 
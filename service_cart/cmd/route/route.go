@@ -8,7 +8,7 @@ import (
 	"github.com/gorilla/mux"
 )
 
-func SetupRoute(cart *handler.CartHandler) *mux.Router {
+func SetupRoute(cart *handler.CartHandler, order *handler.OrderHandler, payment *handler.PaymentHandler, coupon *handler.CouponHandler, guest *handler.GuestCartHandler, wishlist *handler.WishlistHandler, invoice *handler.InvoiceHandler, shipping *handler.ShippingHandler, ret *handler.ReturnHandler, tax *handler.TaxHandler) *mux.Router {
 	r := mux.NewRouter()
 
 	useM := r.PathPrefix("/cart").Subrouter()
@@ -16,9 +16,81 @@ func SetupRoute(cart *handler.CartHandler) *mux.Router {
 
 	useM.HandleFunc("/create/{productId}", cart.CreateCartItem).Methods(http.MethodPost)
 	useM.HandleFunc("/update-amount/{cartItemId}/{productId}", cart.UpdateAmountCartItem).Methods(http.MethodPut)
-	useM.HandleFunc("/update-paid/{cartItemId}/{productId}", cart.UpdatePaidCartItem).Methods(http.MethodPut)
 	useM.HandleFunc("/delete/{cartItemId}", cart.DeleteCartItem).Methods(http.MethodDelete)
 	useM.HandleFunc("/me", cart.GetMyCartItems).Methods(http.MethodGet)
+	useM.HandleFunc("/merge-guest", guest.MergeGuestCart).Methods(http.MethodPost)
+	useM.HandleFunc("/coupon", coupon.GetCartCoupons).Methods(http.MethodGet)
+	useM.HandleFunc("/coupon/{code}", coupon.ApplyToCart).Methods(http.MethodPost)
+	useM.HandleFunc("/coupon/{code}", coupon.RemoveFromCart).Methods(http.MethodDelete)
+
+	wishlistM := r.PathPrefix("/wishlist").Subrouter()
+	wishlistM.Use(middleware.AuthMiddleware)
+
+	wishlistM.HandleFunc("/create", wishlist.CreateWishlist).Methods(http.MethodPost)
+	wishlistM.HandleFunc("/me", wishlist.GetMyWishlists).Methods(http.MethodGet)
+	wishlistM.HandleFunc("/delete/{wishlistId}", wishlist.DeleteWishlist).Methods(http.MethodDelete)
+	wishlistM.HandleFunc("/{wishlistId}/item/{productId}", wishlist.AddItem).Methods(http.MethodPost)
+	wishlistM.HandleFunc("/{wishlistId}/item/{productId}", wishlist.RemoveItem).Methods(http.MethodDelete)
+	wishlistM.HandleFunc("/{wishlistId}/item/{productId}/to-cart", wishlist.MoveToCart).Methods(http.MethodPost)
+	wishlistM.HandleFunc("/from-cart/{cartItemId}/{wishlistId}", wishlist.MoveFromCart).Methods(http.MethodPost)
+
+	r.HandleFunc("/guest/token", guest.IssueToken).Methods(http.MethodPost)
+
+	guestM := r.PathPrefix("/guest/cart").Subrouter()
+	guestM.Use(middleware.GuestMiddleware)
+
+	guestM.HandleFunc("", guest.GetCart).Methods(http.MethodGet)
+	guestM.HandleFunc("/{productId}", guest.AddItem).Methods(http.MethodPost)
+	guestM.HandleFunc("/{productId}", guest.UpdateItem).Methods(http.MethodPut)
+	guestM.HandleFunc("/{productId}", guest.RemoveItem).Methods(http.MethodDelete)
+
+	r.Handle("/checkout", middleware.AuthMiddleware(http.HandlerFunc(order.Checkout))).Methods(http.MethodPost)
+
+	orderM := r.PathPrefix("/order").Subrouter()
+	orderM.Use(middleware.AuthMiddleware)
+
+	orderM.HandleFunc("/me", order.GetMyOrders).Methods(http.MethodGet)
+	orderM.HandleFunc("/get/{orderId}", order.GetOrder).Methods(http.MethodGet)
+	orderM.HandleFunc("/saga/{orderId}", order.GetOrderSaga).Methods(http.MethodGet)
+	orderM.HandleFunc("/invoice/{orderId}", invoice.DownloadInvoice).Methods(http.MethodGet)
+	orderM.HandleFunc("/shipment/{orderId}", shipping.GetOrderShipments).Methods(http.MethodGet)
+	orderM.HandleFunc("/cancel/{orderId}", order.CancelOrder).Methods(http.MethodPut)
+	orderM.HandleFunc("/complete/{orderId}", order.CompleteOrder).Methods(http.MethodPut)
+
+	returnM := r.PathPrefix("/return").Subrouter()
+	returnM.Use(middleware.AuthMiddleware)
+
+	returnM.HandleFunc("/create/{orderId}/{orderItemId}", ret.RequestReturn).Methods(http.MethodPost)
+	returnM.HandleFunc("/me", ret.GetMyReturns).Methods(http.MethodGet)
+	returnM.HandleFunc("/store/{storeId}", ret.GetStoreReturns).Methods(http.MethodGet)
+	returnM.HandleFunc("/approve/{returnId}", ret.ApproveReturn).Methods(http.MethodPut)
+	returnM.HandleFunc("/reject/{returnId}", ret.RejectReturn).Methods(http.MethodPut)
+
+	// webhook dipanggil provider, diverifikasi lewat signature bukan jwt
+	r.HandleFunc("/payment/webhook", payment.Webhook).Methods(http.MethodPost)
+
+	paymentM := r.PathPrefix("/payment").Subrouter()
+	paymentM.Use(middleware.AuthMiddleware)
+
+	paymentM.HandleFunc("/pay/{orderId}", payment.Pay).Methods(http.MethodPost)
+	paymentM.HandleFunc("/order/{orderId}", payment.GetPayment).Methods(http.MethodGet)
+
+	r.HandleFunc("/shipping/rate/{storeId}", shipping.GetRates).Methods(http.MethodGet)
+
+	shippingM := r.PathPrefix("/shipping").Subrouter()
+	shippingM.Use(middleware.AuthMiddleware)
+
+	shippingM.HandleFunc("/quote", shipping.Quote).Methods(http.MethodGet)
+	shippingM.HandleFunc("/rate/{storeId}", shipping.CreateRate).Methods(http.MethodPost)
+	shippingM.HandleFunc("/rate/{storeId}/{rateId}", shipping.DeleteRate).Methods(http.MethodDelete)
+
+	r.Handle("/shipment/status/{shipmentId}", middleware.AuthMiddleware(http.HandlerFunc(shipping.UpdateShipmentStatus))).Methods(http.MethodPut)
+
+	r.HandleFunc("/tax/rate", tax.GetRates).Methods(http.MethodGet)
+	r.Handle("/tax/rate", middleware.AuthMiddleware(http.HandlerFunc(tax.SaveRate))).Methods(http.MethodPost)
+	r.Handle("/tax/rate/{rateId}", middleware.AuthMiddleware(http.HandlerFunc(tax.DeleteRate))).Methods(http.MethodDelete)
+
+	r.Handle("/coupon/create", middleware.AuthMiddleware(http.HandlerFunc(coupon.CreateCoupon))).Methods(http.MethodPost)
 
 	return r
 }
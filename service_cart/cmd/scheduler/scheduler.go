@@ -0,0 +1,34 @@
+package scheduler
+
+import (
+	"fmt"
+	"service_cart/internal/usecase"
+	"time"
+)
+
+func SagaTimeoutScheduler(saga usecase.CheckoutSaga, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		for range ticker.C {
+			if err := saga.ExpireTimedOut(); err != nil {
+				fmt.Println("expire checkout saga failed:", err)
+			}
+		}
+	}()
+}
+
+func AbandonedCartScheduler(usecase usecase.AbandonedCartUsecase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		for range ticker.C {
+			if err := usecase.NotifyAbandonedCarts(); err != nil {
+				fmt.Println("notify abandoned cart failed:", err)
+			}
+			if err := usecase.ExpireStaleCarts(); err != nil {
+				fmt.Println("expire stale cart failed:", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+type CreateCouponReq struct {
+	Code         string    `json:"code"`
+	StoreID      *uint     `json:"store_id"`
+	DiscountType string    `json:"discount_type"`
+	Value        int64     `json:"value"`
+	MinSpend     int64     `json:"min_spend"`
+	UsageLimit   int       `json:"usage_limit"`
+	PerUserLimit int       `json:"per_user_limit"`
+	StartsAt     time.Time `json:"starts_at"`
+	EndsAt       time.Time `json:"ends_at"`
+	UserID       uint      `json:"-"`
+}
+
+type Coupon struct {
+	ID           uint      `json:"id"`
+	Code         string    `json:"code"`
+	StoreID      *uint     `json:"store_id"`
+	DiscountType string    `json:"discount_type"`
+	Value        int64     `json:"value"`
+	MinSpend     int64     `json:"min_spend"`
+	UsageLimit   int       `json:"usage_limit"`
+	PerUserLimit int       `json:"per_user_limit"`
+	UsedCount    int       `json:"used_count"`
+	StartsAt     time.Time `json:"starts_at"`
+	EndsAt       time.Time `json:"ends_at"`
+}
@@ -3,37 +3,126 @@ package dto
 import "time"
 
 type CartItem struct {
-	ID               uint `json:"id"`
-	UserID           uint `json:"user_id"`
-	ProductID        uint `json:"product_id"`
-	PurchaseAmount   int  `json:"purchase_amount"`
-	IsPaid           bool `json:"id_paid"`
+	ID               uint  `json:"id"`
+	UserID           uint  `json:"user_id"`
+	ProductID        uint  `json:"product_id"`
+	PurchaseAmount   int   `json:"purchase_amount"`
+	UnitPrice        int64 `json:"unit_price"`
+	CurrentPrice     int64 `json:"current_price"`
+	PriceChanged     bool  `json:"price_changed" gorm:"-"`
+	IsPaid           bool  `json:"id_paid"`
 	CreatedAt        time.Time
 	IsProductDeleted bool `json:"is_product_deleted"`
 }
 
 type CreateCartItemReq struct {
-	UserID         uint `json:"-"`
-	ProductID      uint `json:"-"`
-	PurchaseAmount int  `json:"purchase_amount"`
+	UserID         uint   `json:"-"`
+	Email          string `json:"-"`
+	ProductID      uint   `json:"-"`
+	UnitPrice      int64  `json:"-"`
+	PurchaseAmount int    `json:"purchase_amount"`
 }
 
 type UpdateAmountCartItemReq struct {
-	UserID         uint `json:"-"`
-	ID             uint `json:"-"`
-	ProductID      uint `json:"-"`
-	PurchaseAmount int  `json:"purchase_amount"`
-}
-
-type UpdatePaidCartItemReq struct {
-	Email          string `json:"-"`
 	UserID         uint   `json:"-"`
+	Email          string `json:"-"`
 	ID             uint   `json:"-"`
 	ProductID      uint   `json:"-"`
 	PurchaseAmount int    `json:"purchase_amount"`
 }
 
 type ValidationProductKafka struct {
-	Stock   int  `json:"stock"`
-	Deleted bool `json:"deleted"`
+	Stock   int   `json:"stock"`
+	Price   int64 `json:"price"`
+	Deleted bool  `json:"deleted"`
+}
+
+type PriceChangedKafka struct {
+	ProductID uint      `json:"product_id"`
+	StoreID   uint      `json:"store_id"`
+	OldPrice  int64     `json:"old_price"`
+	NewPrice  int64     `json:"new_price"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+type CartItemPaidKafka struct {
+	CartItemID     uint      `json:"cart_item_id"`
+	UserID         uint      `json:"user_id"`
+	ProductID      uint      `json:"product_id"`
+	PurchaseAmount int       `json:"purchase_amount"`
+	PaidAt         time.Time `json:"paid_at"`
+}
+
+type CartLine struct {
+	CartItemID     uint   `json:"cart_item_id"`
+	ProductID      uint   `json:"product_id"`
+	ProductName    string `json:"product_name"`
+	PurchaseAmount int    `json:"purchase_amount"`
+	UnitPrice      int64  `json:"unit_price"`
+	CurrentPrice   int64  `json:"current_price"`
+	PriceChanged   bool   `json:"price_changed"`
+	Stock          int    `json:"stock"`
+	UnderStocked   bool   `json:"under_stocked"`
+	Deleted        bool   `json:"deleted"`
+	LineSubtotal   int64  `json:"line_subtotal"`
+}
+
+type CartStore struct {
+	StoreID  uint       `json:"store_id"`
+	Items    []CartLine `json:"items"`
+	Subtotal int64      `json:"subtotal"`
+}
+
+// cart dikelompokkan per toko, product yang sudah dihapus dipisah ke Unavailable
+type Cart struct {
+	Stores      []CartStore `json:"stores"`
+	Unavailable []CartLine  `json:"unavailable"`
+	Total       int64       `json:"total"`
+}
+
+type GuestCartItemReq struct {
+	PurchaseAmount int `json:"purchase_amount"`
+}
+
+// hasil merge cart guest, berisi product_id per kategori
+type GuestMergeResult struct {
+	Merged   []uint `json:"merged"`
+	Adjusted []uint `json:"adjusted"`
+	Skipped  []uint `json:"skipped"`
+}
+
+type GuestCartMergeKafka struct {
+	UserID     uint   `json:"user_id"`
+	Email      string `json:"email"`
+	GuestToken string `json:"guest_token"`
+}
+
+type AbandonedCartItem struct {
+	ProductID      uint   `json:"product_id"`
+	ProductName    string `json:"product_name"`
+	PurchaseAmount int    `json:"purchase_amount"`
+	CurrentPrice   int64  `json:"current_price"`
+}
+
+type AbandonedCart struct {
+	UserID         uint                `json:"user_id"`
+	Items          []AbandonedCartItem `json:"items"`
+	Total          int64               `json:"total"`
+	LastActivityAt time.Time           `json:"last_activity_at"`
+}
+
+type WishlistReq struct {
+	Name string `json:"name"`
+}
+
+type WishlistItem struct {
+	ProductID uint      `json:"product_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Wishlist struct {
+	ID        uint           `json:"id"`
+	Name      string         `json:"name"`
+	Items     []WishlistItem `json:"items"`
+	CreatedAt time.Time      `json:"created_at"`
 }
@@ -0,0 +1,54 @@
+package dto
+
+import "time"
+
+type InvoiceStore struct {
+	StoreID  uint        `json:"store_id"`
+	Name     string      `json:"name"`
+	Items    []OrderItem `json:"items"`
+	Subtotal int64       `json:"subtotal"`
+}
+
+// ringkasan pajak per tarif, Inclusive berarti sudah termasuk di harga
+type InvoiceTax struct {
+	Name   string `json:"name"`
+	Rate   int    `json:"rate"`
+	Mode   string `json:"mode"`
+	Amount int64  `json:"amount"`
+}
+
+type Invoice struct {
+	Number         string          `json:"number"`
+	OrderID        uint            `json:"order_id"`
+	IssuedAt       time.Time       `json:"issued_at"`
+	BuyerID        uint            `json:"buyer_id"`
+	BuyerEmail     string          `json:"buyer_email"`
+	Stores         []InvoiceStore  `json:"stores"`
+	Discounts      []OrderDiscount `json:"discounts"`
+	Subtotal       int64           `json:"subtotal"`
+	DiscountAmount int64           `json:"discount_amount"`
+	ShippingAmount int64           `json:"shipping_amount"`
+	TaxAmount      int64           `json:"tax_amount"`
+	Taxes          []InvoiceTax    `json:"taxes"`
+	TotalAmount    int64           `json:"total_amount"`
+}
+
+// file pdf yang siap diunduh atau dilampirkan ke email
+type InvoiceFile struct {
+	Number   string `json:"number"`
+	Filename string `json:"filename"`
+	Content  []byte `json:"content"`
+}
+
+// lampiran email di payload notification-request, content di-encode base64 oleh json
+type Attachment struct {
+	Filename string `json:"filename"`
+	Content  []byte `json:"content"`
+}
+
+type StoreDetailKafka struct {
+	ID      uint   `json:"id"`
+	Name    string `json:"name"`
+	AdminID uint   `json:"admin_id"`
+	Region  string `json:"region"`
+}
@@ -0,0 +1,63 @@
+package dto
+
+import "time"
+
+type OrderItem struct {
+	ID          uint   `json:"id"`
+	CartItemID  uint   `json:"cart_item_id"`
+	ProductID   uint   `json:"product_id"`
+	StoreID     uint   `json:"store_id"`
+	ProductName string `json:"product_name"`
+	UnitPrice   int64  `json:"unit_price"`
+	Quantity    int    `json:"quantity"`
+	Subtotal    int64  `json:"subtotal"`
+	Category    string `json:"category"`
+
+	DiscountAmount int64  `json:"discount_amount"`
+	TaxRegion      string `json:"tax_region"`
+	TaxName        string `json:"tax_name"`
+	TaxRate        int    `json:"tax_rate"`
+	TaxMode        string `json:"tax_mode"`
+	TaxAmount      int64  `json:"tax_amount"`
+}
+
+type OrderDiscount struct {
+	CouponID uint   `json:"coupon_id"`
+	Code     string `json:"code"`
+	StoreID  *uint  `json:"store_id"`
+	Amount   int64  `json:"amount"`
+}
+
+type Order struct {
+	ID             uint            `json:"id"`
+	UserID         uint            `json:"user_id"`
+	Status         string          `json:"status"`
+	TotalAmount    int64           `json:"total_amount"`
+	DiscountAmount int64           `json:"discount_amount"`
+	Items          []OrderItem     `json:"items"`
+	Discounts      []OrderDiscount `json:"discounts"`
+	ShippingAmount int64           `json:"shipping_amount"`
+	Shipments      []Shipment      `json:"shipments"`
+	TaxAmount      int64           `json:"tax_amount"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+type CheckoutReq struct {
+	CouponCodes []string `json:"coupon_codes"`
+
+	//address_id 0 memakai alamat default
+	AddressID      uint   `json:"address_id"`
+	ShippingMethod string `json:"shipping_method"`
+}
+
+type ProductDetailKafka struct {
+	ProductID uint   `json:"product_id"`
+	StoreID   uint   `json:"store_id"`
+	Name      string `json:"name"`
+	Price     int64  `json:"price"`
+	Stock     int    `json:"stock"`
+	Weight    int    `json:"weight"`
+	Category  string `json:"category"`
+	Deleted   bool   `json:"deleted"`
+}
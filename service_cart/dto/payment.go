@@ -0,0 +1,13 @@
+package dto
+
+import "time"
+
+type Payment struct {
+	ID            uint      `json:"id"`
+	OrderID       uint      `json:"order_id"`
+	Amount        int64     `json:"amount"`
+	Status        string    `json:"status"`
+	IntentID      string    `json:"intent_id"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
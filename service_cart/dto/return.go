@@ -0,0 +1,55 @@
+package dto
+
+import "time"
+
+type ReturnReq struct {
+	UserID      uint     `json:"-"`
+	Email       string   `json:"-"`
+	OrderID     uint     `json:"-"`
+	OrderItemID uint     `json:"-"`
+	Quantity    int      `json:"quantity"`
+	Reason      string   `json:"reason"`
+	Photos      []string `json:"photos"`
+}
+
+type ReturnDecisionReq struct {
+	UserID   uint   `json:"-"`
+	ReturnID uint   `json:"-"`
+	Approve  bool   `json:"-"`
+	Note     string `json:"note"`
+}
+
+type Return struct {
+	ID            uint       `json:"id"`
+	OrderID       uint       `json:"order_id"`
+	OrderItemID   uint       `json:"order_item_id"`
+	UserID        uint       `json:"user_id"`
+	StoreID       uint       `json:"store_id"`
+	ProductID     uint       `json:"product_id"`
+	ProductName   string     `json:"product_name"`
+	Quantity      int        `json:"quantity"`
+	Reason        string     `json:"reason"`
+	Photos        []string   `json:"photos"`
+	Status        string     `json:"status"`
+	RefundAmount  int64      `json:"refund_amount"`
+	SellerNote    string     `json:"seller_note"`
+	RefundID      string     `json:"refund_id"`
+	FailureReason string     `json:"failure_reason"`
+	DecidedAt     *time.Time `json:"decided_at"`
+	RefundedAt    *time.Time `json:"refunded_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+type StockRestockKafka struct {
+	ReturnID  uint `json:"return_id"`
+	OrderID   uint `json:"order_id"`
+	ProductID uint `json:"product_id"`
+	Quantity  int  `json:"quantity"`
+}
+
+type UserContactKafka struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
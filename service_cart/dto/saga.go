@@ -0,0 +1,60 @@
+package dto
+
+import "time"
+
+type Saga struct {
+	ID            string    `json:"id"`
+	OrderID       uint      `json:"order_id"`
+	Step          string    `json:"step"`
+	Status        string    `json:"status"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type StockItemKafka struct {
+	ProductID uint `json:"product_id"`
+	Quantity  int  `json:"quantity"`
+}
+
+type StockReserveKafka struct {
+	CorrelationID string           `json:"correlation_id"`
+	OrderID       uint             `json:"order_id"`
+	Items         []StockItemKafka `json:"items"`
+}
+
+type StockReleaseKafka struct {
+	CorrelationID string `json:"correlation_id"`
+	OrderID       uint   `json:"order_id"`
+}
+
+type PaymentChargeKafka struct {
+	CorrelationID string `json:"correlation_id"`
+	OrderID       uint   `json:"order_id"`
+	UserID        uint   `json:"user_id"`
+	Amount        int64  `json:"amount"`
+}
+
+type PaymentRefundKafka struct {
+	CorrelationID string `json:"correlation_id"`
+	OrderID       uint   `json:"order_id"`
+	PaymentID     string `json:"payment_id"`
+	Amount        int64  `json:"amount"`
+
+	//diisi untuk refund sebagian dari retur
+	ReturnID uint `json:"return_id,omitempty"`
+	UserID   uint `json:"user_id,omitempty"`
+}
+
+type ReturnRefundReplyKafka struct {
+	ReturnID uint   `json:"return_id"`
+	Success  bool   `json:"success"`
+	RefundID string `json:"refund_id,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+type SagaReplyKafka struct {
+	CorrelationID string `json:"correlation_id"`
+	Success       bool   `json:"success"`
+	Reason        string `json:"reason,omitempty"`
+	PaymentID     string `json:"payment_id,omitempty"`
+}
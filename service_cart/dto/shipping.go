@@ -0,0 +1,79 @@
+package dto
+
+import "time"
+
+// alamat dari service user
+type ShippingAddress struct {
+	ID         uint   `json:"id"`
+	UserID     uint   `json:"user_id"`
+	Label      string `json:"label"`
+	Recipient  string `json:"recipient"`
+	Phone      string `json:"phone"`
+	Street     string `json:"street"`
+	City       string `json:"city"`
+	Province   string `json:"province"`
+	PostalCode string `json:"postal_code"`
+	IsDefault  bool   `json:"is_default"`
+}
+
+type ShippingRateReq struct {
+	UserID     uint   `json:"-"`
+	StoreID    uint   `json:"-"`
+	Method     string `json:"method"`
+	Zone       string `json:"zone"`
+	MinWeight  int    `json:"min_weight"`
+	MaxWeight  int    `json:"max_weight"`
+	BasePrice  int64  `json:"base_price"`
+	PerKgPrice int64  `json:"per_kg_price"`
+}
+
+type ShippingRate struct {
+	ID         uint   `json:"id"`
+	StoreID    uint   `json:"store_id"`
+	Method     string `json:"method"`
+	Zone       string `json:"zone"`
+	MinWeight  int    `json:"min_weight"`
+	MaxWeight  int    `json:"max_weight"`
+	BasePrice  int64  `json:"base_price"`
+	PerKgPrice int64  `json:"per_kg_price"`
+}
+
+type ShippingOption struct {
+	Method string `json:"method"`
+	Cost   int64  `json:"cost"`
+}
+
+// pilihan ongkir satu toko untuk isi cart saat ini
+type ShippingQuote struct {
+	StoreID uint             `json:"store_id"`
+	Weight  int              `json:"weight"`
+	Options []ShippingOption `json:"options"`
+}
+
+type Shipment struct {
+	ID             uint       `json:"id"`
+	OrderID        uint       `json:"order_id"`
+	StoreID        uint       `json:"store_id"`
+	Method         string     `json:"method"`
+	Weight         int        `json:"weight"`
+	Cost           int64      `json:"cost"`
+	Status         string     `json:"status"`
+	TrackingNumber string     `json:"tracking_number"`
+	Recipient      string     `json:"recipient"`
+	Phone          string     `json:"phone"`
+	Street         string     `json:"street"`
+	City           string     `json:"city"`
+	Province       string     `json:"province"`
+	PostalCode     string     `json:"postal_code"`
+	PackedAt       *time.Time `json:"packed_at"`
+	ShippedAt      *time.Time `json:"shipped_at"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type ShipmentStatusReq struct {
+	UserID         uint   `json:"-"`
+	ShipmentID     uint   `json:"-"`
+	Status         string `json:"status"`
+	TrackingNumber string `json:"tracking_number"`
+}
@@ -0,0 +1,48 @@
+package dto
+
+import "time"
+
+// StoreOrderEventKafka potongan order milik satu toko, dikirim ke service store
+// setiap kali order dibuat atau berubah status. Isinya snapshot lengkap supaya
+// read model di service store cukup menyimpan event terbaru.
+type StoreOrderEventKafka struct {
+	Event          string                `json:"event"`
+	OrderID        uint                  `json:"order_id"`
+	StoreID        uint                  `json:"store_id"`
+	BuyerID        uint                  `json:"buyer_id"`
+	Status         string                `json:"status"`
+	Items          []StoreOrderItemKafka `json:"items"`
+	Subtotal       int64                 `json:"subtotal"`
+	DiscountAmount int64                 `json:"discount_amount"`
+	TaxAmount      int64                 `json:"tax_amount"`
+	Shipment       *Shipment             `json:"shipment"`
+	OrderedAt      time.Time             `json:"ordered_at"`
+	OccurredAt     time.Time             `json:"occurred_at"`
+}
+
+type StoreOrderItemKafka struct {
+	OrderItemID    uint   `json:"order_item_id"`
+	ProductID      uint   `json:"product_id"`
+	ProductName    string `json:"product_name"`
+	UnitPrice      int64  `json:"unit_price"`
+	Quantity       int    `json:"quantity"`
+	Subtotal       int64  `json:"subtotal"`
+	DiscountAmount int64  `json:"discount_amount"`
+	TaxAmount      int64  `json:"tax_amount"`
+}
+
+// StoreShipmentReqKafka permintaan seller dari service store, admin toko sudah
+// dicek di sana
+type StoreShipmentReqKafka struct {
+	CorrelationID  string `json:"correlation_id"`
+	StoreID        uint   `json:"store_id"`
+	OrderID        uint   `json:"order_id"`
+	Status         string `json:"status"`
+	TrackingNumber string `json:"tracking_number"`
+}
+
+type StoreShipmentReplyKafka struct {
+	Success  bool      `json:"success"`
+	Reason   string    `json:"reason,omitempty"`
+	Shipment *Shipment `json:"shipment,omitempty"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+type TaxRateReq struct {
+	UserID   uint   `json:"-"`
+	Region   string `json:"region"`
+	Category string `json:"category"`
+	Name     string `json:"name"`
+	Rate     int    `json:"rate"`
+	Mode     string `json:"mode"`
+}
+
+type TaxRate struct {
+	ID        uint      `json:"id"`
+	Region    string    `json:"region"`
+	Category  string    `json:"category"`
+	Name      string    `json:"name"`
+	Rate      int       `json:"rate"`
+	Mode      string    `json:"mode"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -3,11 +3,353 @@ package entity
 import "time"
 
 type CartItem struct {
-	ID               uint `gorm:"primaryKey"`
-	PurchaseAmount   int  `gorm:"not null"`
-	IsPaid           bool `gorm:"default:false"`
+	ID               uint  `gorm:"primaryKey"`
+	PurchaseAmount   int   `gorm:"not null"`
+	UnitPrice        int64 `gorm:"not null;default:0"`
+	CurrentPrice     int64 `gorm:"not null;default:0"`
+	IsPaid           bool  `gorm:"default:false"`
 	CreatedAt        time.Time
 	IsProductDeleted bool  `gorm:"default:false"`
 	UserID           uint  `gorm:"index"`
 	ProductID        *uint `gorm:"index"`
+
+	//terisi setelah checkout
+	OrderID *uint `gorm:"index"`
+}
+
+const (
+	OrderPending   = "pending"
+	OrderPaid      = "paid"
+	OrderFulfilled = "fulfilled"
+	OrderCancelled = "cancelled"
+)
+
+const (
+	ShipmentPending   = "pending"
+	ShipmentPacked    = "packed"
+	ShipmentShipped   = "shipped"
+	ShipmentDelivered = "delivered"
+	ShipmentCancelled = "cancelled"
+)
+
+// tabel tarif ongkir per toko, lihat helper/shipping untuk aturan pencocokan
+type ShippingRate struct {
+	ID         uint   `gorm:"primaryKey"`
+	StoreID    uint   `gorm:"index:idx_shipping_rate_store_method"`
+	Method     string `gorm:"type:varchar(30);index:idx_shipping_rate_store_method;not null"`
+	Zone       string `gorm:"type:varchar(100);not null;default:''"`
+	MinWeight  int    `gorm:"not null;default:0"`
+	MaxWeight  int    `gorm:"not null;default:0"`
+	BasePrice  int64  `gorm:"not null"`
+	PerKgPrice int64  `gorm:"not null;default:0"`
+	CreatedAt  time.Time
+}
+
+// satu pengiriman per toko per order, alamat disalin saat checkout
+type Shipment struct {
+	ID             uint   `gorm:"primaryKey"`
+	OrderID        uint   `gorm:"index"`
+	StoreID        uint   `gorm:"index"`
+	UserID         uint   `gorm:"index"`
+	BuyerEmail     string `gorm:"type:varchar(255)"`
+	Method         string `gorm:"type:varchar(30);not null"`
+	Weight         int    `gorm:"not null"`
+	Cost           int64  `gorm:"not null"`
+	Status         string `gorm:"type:varchar(20);index;not null"`
+	TrackingNumber string `gorm:"type:varchar(64);index"`
+
+	Recipient  string `gorm:"type:varchar(100)"`
+	Phone      string `gorm:"type:varchar(20)"`
+	Street     string `gorm:"type:varchar(255)"`
+	City       string `gorm:"type:varchar(100)"`
+	Province   string `gorm:"type:varchar(100)"`
+	PostalCode string `gorm:"type:varchar(10)"`
+
+	PackedAt    *time.Time
+	ShippedAt   *time.Time
+	DeliveredAt *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// invoice dibuat sekali per order, nomornya berurutan per tahun
+type Invoice struct {
+	ID             uint   `gorm:"primaryKey"`
+	OrderID        uint   `gorm:"uniqueIndex"`
+	UserID         uint   `gorm:"index"`
+	Number         string `gorm:"type:varchar(32);uniqueIndex;not null"`
+	BuyerEmail     string `gorm:"type:varchar(255)"`
+	Subtotal       int64  `gorm:"not null"`
+	DiscountAmount int64  `gorm:"not null;default:0"`
+	ShippingAmount int64  `gorm:"not null;default:0"`
+	TaxAmount      int64  `gorm:"not null;default:0"`
+	TotalAmount    int64  `gorm:"not null"`
+	FilePath       string `gorm:"type:varchar(255)"`
+	IssuedAt       time.Time
+}
+
+type InvoiceSequence struct {
+	Year       int  `gorm:"primaryKey;autoIncrement:false"`
+	LastNumber uint `gorm:"not null"`
+}
+
+// wishlist bernama milik user, nama unik per user
+type Wishlist struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"uniqueIndex:idx_wishlist_user_name"`
+	Name      string `gorm:"type:varchar(100);uniqueIndex:idx_wishlist_user_name;not null"`
+	Items     []WishlistItem
+	CreatedAt time.Time
+}
+
+type WishlistItem struct {
+	ID         uint `gorm:"primaryKey"`
+	WishlistID uint `gorm:"uniqueIndex:idx_wishlist_product"`
+	ProductID  uint `gorm:"uniqueIndex:idx_wishlist_product;index"`
+	CreatedAt  time.Time
+}
+
+// aktivitas terakhir cart user, dipakai untuk deteksi cart terbengkalai
+type CartActivity struct {
+	UserID         uint `gorm:"primaryKey"`
+	Email          string
+	LastActivityAt time.Time `gorm:"index"`
+	RemindedAt     *time.Time
+}
+
+type Order struct {
+	ID          uint   `gorm:"primaryKey"`
+	UserID      uint   `gorm:"index"`
+	Status      string `gorm:"type:varchar(20);index;not null"`
+	TotalAmount int64  `gorm:"not null"`
+	Items       []OrderItem
+
+	//potongan kupon, TotalAmount sudah dikurangi
+	DiscountAmount int64 `gorm:"not null;default:0"`
+	Discounts      []OrderDiscount
+
+	//ongkir semua toko, sudah termasuk di TotalAmount
+	ShippingAmount int64 `gorm:"not null;default:0"`
+	Shipments      []Shipment
+
+	//pajak semua baris, hanya pajak exclusive yang ditambahkan ke TotalAmount
+	TaxAmount int64 `gorm:"not null;default:0"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type OrderItem struct {
+	ID         uint `gorm:"primaryKey"`
+	OrderID    uint `gorm:"index"`
+	CartItemID uint
+	ProductID  uint `gorm:"index"`
+	StoreID    uint `gorm:"index"`
+
+	//snapshot saat checkout
+	ProductName string `gorm:"not null"`
+	UnitPrice   int64  `gorm:"not null"`
+	Quantity    int    `gorm:"not null"`
+	Subtotal    int64  `gorm:"not null"`
+	Category    string `gorm:"type:varchar(50)"`
+
+	//bagian diskon order untuk baris ini, dasar pajak = Subtotal - DiscountAmount
+	DiscountAmount int64 `gorm:"not null;default:0"`
+
+	//audit pajak saat checkout
+	TaxRegion string `gorm:"type:varchar(20)"`
+	TaxName   string `gorm:"type:varchar(50)"`
+	TaxRate   int    `gorm:"not null;default:0"`
+	TaxMode   string `gorm:"type:varchar(10)"`
+	TaxAmount int64  `gorm:"not null;default:0"`
+}
+
+// tarif pajak per wilayah toko dan kategori product, Rate dalam basis poin
+type TaxRate struct {
+	ID        uint   `gorm:"primaryKey"`
+	Region    string `gorm:"type:varchar(20);not null;uniqueIndex:idx_tax_region_category"`
+	Category  string `gorm:"type:varchar(50);not null;default:'';uniqueIndex:idx_tax_region_category"`
+	Name      string `gorm:"type:varchar(50);not null"`
+	Rate      int    `gorm:"not null"`
+	Mode      string `gorm:"type:varchar(10);not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+const (
+	SagaStepReserveStock = "reserve_stock"
+	SagaStepCharge       = "charge"
+	SagaStepConfirm      = "confirm"
+	SagaStepNotify       = "notify"
+	SagaStepDone         = "done"
+
+	SagaRunning      = "running"
+	SagaCompensating = "compensating"
+	SagaCompleted    = "completed"
+	SagaFailed       = "failed"
+)
+
+// state saga checkout, ID dipakai sebagai correlation_id di semua command
+type Saga struct {
+	ID      string `gorm:"primaryKey;type:varchar(36)"`
+	OrderID uint   `gorm:"uniqueIndex"`
+	UserID  uint   `gorm:"index"`
+	Email   string
+	Step    string `gorm:"type:varchar(20);not null"`
+	Status  string `gorm:"type:varchar(20);index;not null"`
+
+	StockReserved  bool `gorm:"default:false"`
+	PaymentCharged bool `gorm:"default:false"`
+	PaymentID      string
+	FailureReason  string
+
+	Deadline  time.Time `gorm:"index"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+const (
+	PaymentPending    = "pending"
+	PaymentProcessing = "processing"
+	PaymentSucceeded  = "succeeded"
+	PaymentFailed     = "failed"
+	PaymentRefunded   = "refunded"
+)
+
+// satu payment per charge request saga
+type Payment struct {
+	ID            uint   `gorm:"primaryKey"`
+	CorrelationID string `gorm:"type:varchar(36);uniqueIndex"`
+	OrderID       uint   `gorm:"index"`
+	UserID        uint   `gorm:"index"`
+	Amount        int64  `gorm:"not null"`
+	Status        string `gorm:"type:varchar(20);not null"`
+	IntentID      string `gorm:"type:varchar(64);uniqueIndex"`
+	RefundID      string
+	FailureReason string
+
+	//total refund retur, Amount dikurangi ini yang masih bisa direfund
+	RefundedAmount int64 `gorm:"not null;default:0"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// refund sebagian untuk satu retur, unik per retur supaya tidak dobel
+const (
+	RefundPending   = "pending"
+	RefundSucceeded = "succeeded"
+	RefundFailed    = "failed"
+)
+
+// refund per retur. Amount sudah dihitung ke refunded_amount payment sejak
+// pending supaya refund retur lain tidak melewati nilai payment.
+type PaymentRefund struct {
+	ID            uint   `gorm:"primaryKey"`
+	PaymentID     uint   `gorm:"index"`
+	ReturnID      uint   `gorm:"uniqueIndex"`
+	Amount        int64  `gorm:"not null"`
+	Status        string `gorm:"type:varchar(20);not null;default:'succeeded'"`
+	RefundID      string `gorm:"type:varchar(64)"`
+	FailureReason string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+const (
+	ReturnRequested = "requested"
+	ReturnApproved  = "approved"
+	ReturnRejected  = "rejected"
+	ReturnRefunded  = "refunded"
+)
+
+// permintaan retur per baris order, diputuskan oleh admin toko
+type ReturnRequest struct {
+	ID          uint   `gorm:"primaryKey"`
+	OrderID     uint   `gorm:"index"`
+	OrderItemID uint   `gorm:"index"`
+	UserID      uint   `gorm:"index"`
+	StoreID     uint   `gorm:"index"`
+	ProductID   uint   `gorm:"index"`
+	ProductName string `gorm:"not null"`
+	Quantity    int    `gorm:"not null"`
+	Reason      string `gorm:"type:text;not null"`
+	Status      string `gorm:"type:varchar(20);index;not null"`
+	BuyerEmail  string
+
+	//nilai yang dibayar pembeli untuk qty ini, sudah dipotong diskon
+	RefundAmount int64 `gorm:"not null"`
+
+	SellerNote    string `gorm:"type:text"`
+	RefundID      string `gorm:"type:varchar(64)"`
+	FailureReason string
+	Photos        []ReturnPhoto
+
+	DecidedAt  *time.Time
+	RefundedAt *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type ReturnPhoto struct {
+	ID              uint   `gorm:"primaryKey"`
+	ReturnRequestID uint   `gorm:"index"`
+	URL             string `gorm:"type:varchar(500);not null"`
+}
+
+// event webhook yang sudah diproses, untuk dedup pengiriman ulang provider
+type PaymentEvent struct {
+	ID         string `gorm:"primaryKey;type:varchar(64)"`
+	PaymentID  uint   `gorm:"index"`
+	Type       string `gorm:"type:varchar(30)"`
+	ReceivedAt time.Time
+}
+
+const (
+	CouponPercentage = "percentage"
+	CouponFixed      = "fixed"
+)
+
+// StoreID nil berarti kupon platform
+type Coupon struct {
+	ID           uint   `gorm:"primaryKey"`
+	Code         string `gorm:"type:varchar(32);uniqueIndex;not null"`
+	StoreID      *uint  `gorm:"index"`
+	CreatedBy    uint
+	DiscountType string `gorm:"type:varchar(20);not null"`
+	Value        int64  `gorm:"not null"`
+	MinSpend     int64  `gorm:"not null;default:0"`
+
+	//0 berarti tanpa batas
+	UsageLimit   int `gorm:"not null;default:0"`
+	PerUserLimit int `gorm:"not null;default:0"`
+	UsedCount    int `gorm:"not null;default:0"`
+
+	StartsAt  time.Time
+	EndsAt    time.Time
+	CreatedAt time.Time
+}
+
+type CouponRedemption struct {
+	ID        uint `gorm:"primaryKey"`
+	CouponID  uint `gorm:"index:idx_coupon_user"`
+	UserID    uint `gorm:"index:idx_coupon_user"`
+	OrderID   uint `gorm:"index"`
+	CreatedAt time.Time
+}
+
+// kupon yang dipasang user di cart sebelum checkout
+type CartCoupon struct {
+	UserID    uint `gorm:"primaryKey"`
+	CouponID  uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+}
+
+type OrderDiscount struct {
+	ID       uint `gorm:"primaryKey"`
+	OrderID  uint `gorm:"index"`
+	CouponID uint
+	Code     string `gorm:"type:varchar(32)"`
+	StoreID  *uint
+	Amount   int64 `gorm:"not null"`
 }
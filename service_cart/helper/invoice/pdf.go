@@ -0,0 +1,134 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"service_cart/dto"
+	"service_cart/helper/tax"
+	"strconv"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// FormatRupiah menulis nominal dengan pemisah ribuan, contoh Rp 1.250.000
+func FormatRupiah(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	var out []byte
+	for i := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			out = append(out, '.')
+		}
+		out = append(out, digits[i])
+	}
+
+	return fmt.Sprintf("%sRp %s", sign, out)
+}
+
+// FormatRate mengubah basis poin ke persen, contoh 1100 jadi 11 dan 1150 jadi 11,5
+func FormatRate(rate int) string {
+	whole := strconv.Itoa(rate / 100)
+	frac := rate % 100
+	if frac == 0 {
+		return whole
+	}
+	return strings.TrimRight(fmt.Sprintf("%s,%02d", whole, frac), "0")
+}
+
+// Render membuat pdf invoice A4. Font bawaan fpdf hanya cp1252,
+// jadi teks dilewatkan ke translator supaya karakter non ascii tidak rusak.
+func Render(inv *dto.Invoice) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(inv.Number, true)
+	pdf.SetMargins(15, 15, 15)
+	pdf.AddPage()
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, 10, "INVOICE", "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(40, 6, "Nomor", "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 6, tr(inv.Number), "", 1, "L", false, 0, "")
+	pdf.CellFormat(40, 6, "Order", "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 6, fmt.Sprintf("#%d", inv.OrderID), "", 1, "L", false, 0, "")
+	pdf.CellFormat(40, 6, "Tanggal", "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 6, inv.IssuedAt.Format("02 Jan 2006 15:04 MST"), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(0, 7, "Pembeli", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, fmt.Sprintf("User #%d", inv.BuyerID), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, tr(inv.BuyerEmail), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	widths := []float64{70, 30, 15, 30, 35}
+	for _, store := range inv.Stores {
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.CellFormat(0, 7, tr(fmt.Sprintf("Toko: %s (#%d)", store.Name, store.StoreID)), "", 1, "L", false, 0, "")
+
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetFillColor(235, 235, 235)
+		for i, title := range []string{"Produk", "Harga", "Qty", "Pajak", "Subtotal"} {
+			align := "R"
+			if i == 0 {
+				align = "L"
+			}
+			pdf.CellFormat(widths[i], 7, title, "1", 0, align, true, 0, "")
+		}
+		pdf.Ln(-1)
+
+		pdf.SetFont("Helvetica", "", 9)
+		for _, item := range store.Items {
+			pdf.CellFormat(widths[0], 6, tr(item.ProductName), "1", 0, "L", false, 0, "")
+			pdf.CellFormat(widths[1], 6, FormatRupiah(item.UnitPrice), "1", 0, "R", false, 0, "")
+			pdf.CellFormat(widths[2], 6, strconv.Itoa(item.Quantity), "1", 0, "R", false, 0, "")
+			pdf.CellFormat(widths[3], 6, FormatRupiah(item.TaxAmount), "1", 0, "R", false, 0, "")
+			pdf.CellFormat(widths[4], 6, FormatRupiah(item.Subtotal), "1", 1, "R", false, 0, "")
+		}
+
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.CellFormat(widths[0]+widths[1]+widths[2]+widths[3], 6, "Subtotal toko", "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[4], 6, FormatRupiah(store.Subtotal), "1", 1, "R", false, 0, "")
+		pdf.Ln(3)
+	}
+
+	label := widths[0] + widths[1] + widths[2] + widths[3]
+	row := func(name string, amount int64) {
+		pdf.CellFormat(label, 6, tr(name), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[4], 6, FormatRupiah(amount), "", 1, "R", false, 0, "")
+	}
+
+	pdf.SetFont("Helvetica", "", 10)
+	row("Subtotal", inv.Subtotal)
+	for _, d := range inv.Discounts {
+		row("Diskon "+d.Code, -d.Amount)
+	}
+	row("Ongkos kirim", inv.ShippingAmount)
+	if len(inv.Taxes) == 0 {
+		row("Pajak", 0)
+	}
+	for _, t := range inv.Taxes {
+		name := fmt.Sprintf("%s %s%%", t.Name, FormatRate(t.Rate))
+		if t.Mode == tax.ModeInclusive {
+			name += " (termasuk dalam harga)"
+		}
+		row(name, t.Amount)
+	}
+	pdf.SetFont("Helvetica", "B", 11)
+	row("Total", inv.TotalAmount)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
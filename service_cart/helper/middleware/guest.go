@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"service_cart/helper/utils"
+)
+
+const GuestContextKey key = 1
+
+const GuestTokenHeader = "X-Guest-Token"
+
+func GuestMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenString := r.Header.Get(GuestTokenHeader)
+		if tokenString == "" {
+			utils.WriteError(w, http.StatusUnauthorized, "tak ada guest token")
+			return
+		}
+
+		guestId, err := utils.ValidateGuestToken(tokenString)
+		if err != nil {
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), GuestContextKey, guestId)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
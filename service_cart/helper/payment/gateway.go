@@ -0,0 +1,66 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+var ErrInvalidSignature = errors.New("signature webhook tidak valid")
+
+const (
+	EventSucceeded = "payment.succeeded"
+	EventFailed    = "payment.failed"
+)
+
+type Intent struct {
+	ID        string
+	Amount    int64
+	Reference string
+}
+
+type Refund struct {
+	ID       string
+	IntentID string
+	Amount   int64
+}
+
+// Event adalah callback dari provider setelah intent dikonfirmasi
+type Event struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	IntentID  string    `json:"intent_id"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PaymentGateway membungkus provider pembayaran. Hasil confirm tidak
+// dikembalikan langsung, tapi dikirim lewat webhook. Refund dengan
+// idempotencyKey yang sama mengembalikan refund yang sudah dibuat.
+type PaymentGateway interface {
+	CreateIntent(ctx context.Context, amount int64, reference string) (*Intent, error)
+	Confirm(ctx context.Context, intentId string) error
+	Refund(ctx context.Context, intentId string, amount int64, idempotencyKey string) (*Refund, error)
+	ParseWebhook(payload []byte, signature string) (*Event, error)
+}
+
+// Sign menghasilkan HMAC-SHA256 hex dari payload webhook
+func Sign(secret, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func Verify(secret, payload []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
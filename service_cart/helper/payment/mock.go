@@ -0,0 +1,143 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MockSucceed = "succeed"
+	MockFail    = "fail"
+	MockDelay   = "delay"
+)
+
+const SignatureHeader = "X-Payment-Signature"
+
+// MockGateway mensimulasikan provider lokal. Confirm mengirim webhook
+// bertanda tangan ke webhookURL, sukses atau gagal sesuai mode.
+type MockGateway struct {
+	mode       string
+	delay      time.Duration
+	secret     []byte
+	webhookURL string
+	client     *http.Client
+
+	mu      sync.Mutex
+	refunds map[string]*Refund
+}
+
+func NewMockGateway(mode string, delay time.Duration, secret, webhookURL string) *MockGateway {
+	if mode == "" {
+		mode = MockSucceed
+	}
+	return &MockGateway{
+		mode:       mode,
+		delay:      delay,
+		secret:     []byte(secret),
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: 5 * time.Second},
+		refunds:    make(map[string]*Refund),
+	}
+}
+
+func (g *MockGateway) CreateIntent(ctx context.Context, amount int64, reference string) (*Intent, error) {
+	if amount <= 0 {
+		return nil, errors.New("amount harus lebih dari 0")
+	}
+
+	return &Intent{
+		ID:        "pi_mock_" + uuid.NewString(),
+		Amount:    amount,
+		Reference: reference,
+	}, nil
+}
+
+func (g *MockGateway) Confirm(ctx context.Context, intentId string) error {
+	event := Event{
+		ID:        "evt_mock_" + uuid.NewString(),
+		Type:      EventSucceeded,
+		IntentID:  intentId,
+		CreatedAt: time.Now(),
+	}
+	if g.mode == MockFail {
+		event.Type = EventFailed
+		event.Reason = "pembayaran ditolak provider"
+	}
+
+	delay := time.Duration(0)
+	if g.mode == MockDelay {
+		delay = g.delay
+	}
+
+	go func() {
+		time.Sleep(delay)
+		if err := g.send(event); err != nil {
+			log.Printf("mock payment: kirim webhook %s gagal: %v", event.ID, err)
+		}
+	}()
+
+	return nil
+}
+
+func (g *MockGateway) Refund(ctx context.Context, intentId string, amount int64, idempotencyKey string) (*Refund, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if refund, ok := g.refunds[idempotencyKey]; ok {
+		return refund, nil
+	}
+
+	refund := &Refund{
+		ID:       "re_mock_" + uuid.NewString(),
+		IntentID: intentId,
+		Amount:   amount,
+	}
+	g.refunds[idempotencyKey] = refund
+	return refund, nil
+}
+
+func (g *MockGateway) ParseWebhook(payload []byte, signature string) (*Event, error) {
+	if !Verify(g.secret, payload, signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+func (g *MockGateway) send(event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, g.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(g.secret, body))
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return errors.New("webhook ditolak: " + resp.Status)
+	}
+	return nil
+}
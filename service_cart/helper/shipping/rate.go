@@ -0,0 +1,89 @@
+package shipping
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrNoRate = errors.New("tidak ada tarif pengiriman untuk tujuan ini")
+
+// Rule satu baris tabel tarif toko. Zone kosong berlaku untuk semua zona,
+// MaxWeight 0 berarti tanpa batas atas. Berat dalam gram.
+type Rule struct {
+	Zone       string
+	MinWeight  int
+	MaxWeight  int
+	BasePrice  int64
+	PerKgPrice int64
+}
+
+// RateCalculator dipakai checkout untuk menghitung ongkir per toko,
+// bisa diganti implementasi lain misalnya api kurir
+type RateCalculator interface {
+	Quote(storeId uint, method, zone string, weight int) (int64, error)
+	Methods(storeId uint) ([]string, error)
+}
+
+type RuleSource interface {
+	GetShippingRules(storeId uint, method string) ([]Rule, error)
+	GetShippingMethods(storeId uint) ([]string, error)
+}
+
+// TableCalculator menghitung ongkir dari tabel tarif yang diisi seller
+type TableCalculator struct {
+	source RuleSource
+}
+
+func NewTableCalculator(source RuleSource) *TableCalculator {
+	return &TableCalculator{source}
+}
+
+// zona diambil dari provinsi alamat tujuan
+func NormalizeZone(zone string) string {
+	return strings.ToLower(strings.TrimSpace(zone))
+}
+
+func (r Rule) matches(weight int) bool {
+	return weight >= r.MinWeight && (r.MaxWeight == 0 || weight <= r.MaxWeight)
+}
+
+// Cost dibulatkan ke atas per kg, minimal 1 kg
+func (r Rule) Cost(weight int) int64 {
+	kg := int64((weight + 999) / 1000)
+	if kg < 1 {
+		kg = 1
+	}
+	return r.BasePrice + r.PerKgPrice*kg
+}
+
+func (c *TableCalculator) Methods(storeId uint) ([]string, error) {
+	return c.source.GetShippingMethods(storeId)
+}
+
+// Quote mendahulukan tarif zona yang cocok persis, baru tarif semua zona
+func (c *TableCalculator) Quote(storeId uint, method, zone string, weight int) (int64, error) {
+	rules, err := c.source.GetShippingRules(storeId, method)
+	if err != nil {
+		return 0, err
+	}
+
+	zone = NormalizeZone(zone)
+	var fallback *Rule
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.matches(weight) {
+			continue
+		}
+		if NormalizeZone(rule.Zone) == zone {
+			return rule.Cost(weight), nil
+		}
+		if rule.Zone == "" && fallback == nil {
+			fallback = rule
+		}
+	}
+
+	if fallback == nil {
+		return 0, ErrNoRate
+	}
+	return fallback.Cost(weight), nil
+}
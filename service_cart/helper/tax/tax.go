@@ -0,0 +1,95 @@
+package tax
+
+import "strings"
+
+const (
+	// harga produk belum termasuk pajak, pajak ditambahkan ke total
+	ModeExclusive = "exclusive"
+	// harga produk sudah termasuk pajak, pajak hanya dicatat
+	ModeInclusive = "inclusive"
+)
+
+// Rule satu baris tabel tarif pajak. Category kosong berlaku untuk semua
+// kategori di wilayah itu. Rate dalam basis poin, 1100 berarti 11%.
+type Rule struct {
+	Region   string
+	Category string
+	Name     string
+	Rate     int
+	Mode     string
+}
+
+// Line hasil pajak satu baris order, disimpan apa adanya untuk audit
+type Line struct {
+	Name   string
+	Rate   int
+	Mode   string
+	Amount int64
+}
+
+// Resolver dipakai checkout untuk mencari tarif per wilayah toko dan kategori
+// product, bisa diganti implementasi lain misalnya layanan pajak eksternal
+type Resolver interface {
+	Resolve(region, category string) (*Rule, error)
+}
+
+type RuleSource interface {
+	GetTaxRules(region string) ([]Rule, error)
+}
+
+// TableResolver mencari tarif dari tabel yang diisi admin platform
+type TableResolver struct {
+	source RuleSource
+}
+
+func NewTableResolver(source RuleSource) *TableResolver {
+	return &TableResolver{source}
+}
+
+func Normalize(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// Resolve mendahulukan tarif kategori yang cocok persis, baru tarif semua kategori.
+// Hasil nil berarti wilayah itu tidak dikenai pajak.
+func (t *TableResolver) Resolve(region, category string) (*Rule, error) {
+	rules, err := t.source.GetTaxRules(Normalize(region))
+	if err != nil {
+		return nil, err
+	}
+
+	category = Normalize(category)
+	var fallback *Rule
+	for i := range rules {
+		rule := &rules[i]
+		if Normalize(rule.Category) == category {
+			return rule, nil
+		}
+		if rule.Category == "" && fallback == nil {
+			fallback = rule
+		}
+	}
+
+	return fallback, nil
+}
+
+// Apply menghitung pajak dari nilai setelah diskon, dibulatkan ke rupiah terdekat.
+// Pada mode inclusive pajak diambil dari dalam nilai itu sendiri.
+func (r *Rule) Apply(amount int64) Line {
+	line := Line{Name: r.Name, Rate: r.Rate, Mode: r.Mode}
+	if r.Rate <= 0 || amount <= 0 {
+		return line
+	}
+
+	rate := int64(r.Rate)
+	if r.Mode == ModeInclusive {
+		line.Amount = roundDiv(amount*rate, 10000+rate)
+	} else {
+		line.Amount = roundDiv(amount*rate, 10000)
+	}
+	return line
+}
+
+func roundDiv(a, b int64) int64 {
+	return (a + b/2) / b
+}
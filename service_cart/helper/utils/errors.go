@@ -3,13 +3,46 @@ package utils
 import "errors"
 
 var (
-	ErrInternal         = errors.New("internal error")
-	ErrInvalidEmail     = errors.New("email tidak sesuai")
-	ErrNotAdmin         = errors.New("kau bukan admin")
-	ErrNoStore          = errors.New("tidak ada store")
-	ErrNoTopic          = errors.New("bukan ada topic ini")
-	ErrFailedKafkaWrite = errors.New("gagal  mengirim message ")
-	ErrUnavaible        = errors.New("tidak ada hasil")
-	ErrStocknotEnough   = errors.New("stok product tidak cukup")
-	ErrProductDeleted   = errors.New("product dihapus")
+	ErrInternal             = errors.New("internal error")
+	ErrInvalidEmail         = errors.New("email tidak sesuai")
+	ErrNotAdmin             = errors.New("kau bukan admin")
+	ErrNoStore              = errors.New("tidak ada store")
+	ErrNoTopic              = errors.New("bukan ada topic ini")
+	ErrFailedKafkaWrite     = errors.New("gagal  mengirim message ")
+	ErrUnavaible            = errors.New("tidak ada hasil")
+	ErrStocknotEnough       = errors.New("stok product tidak cukup")
+	ErrProductDeleted       = errors.New("product dihapus")
+	ErrEmptyCart            = errors.New("cart kosong")
+	ErrCartChanged          = errors.New("cart berubah, coba checkout ulang")
+	ErrOrderNotFound        = errors.New("order tidak ditemukan")
+	ErrInvalidStatus        = errors.New("status order tidak bisa diubah")
+	ErrSagaNotFound         = errors.New("saga checkout tidak ditemukan")
+	ErrCheckoutRunning      = errors.New("checkout order sedang diproses")
+	ErrPaymentNotFound      = errors.New("pembayaran tidak ditemukan")
+	ErrPaymentStatus        = errors.New("status pembayaran tidak bisa diproses")
+	ErrCartItemNotFound     = errors.New("cart item tidak ditemukan")
+	ErrWishlistNotFound     = errors.New("wishlist tidak ditemukan")
+	ErrWishlistExists       = errors.New("nama wishlist sudah dipakai")
+	ErrWishlistItemNotFound = errors.New("product tidak ada di wishlist")
+	ErrInvoiceNotFound      = errors.New("invoice tidak ditemukan")
+	ErrInvoiceUnavailable   = errors.New("invoice hanya tersedia untuk order yang sudah dibayar")
+	ErrAddressNotFound      = errors.New("alamat pengiriman tidak ditemukan")
+	ErrShippingMethod       = errors.New("metode pengiriman tidak tersedia untuk salah satu toko")
+	ErrShippingRateNotFound = errors.New("tarif pengiriman tidak ditemukan")
+	ErrShipmentNotFound     = errors.New("pengiriman tidak ditemukan")
+	ErrShipmentStatus       = errors.New("status pengiriman tidak bisa diubah")
+	ErrReturnNotFound       = errors.New("retur tidak ditemukan")
+	ErrReturnNotAllowed     = errors.New("retur hanya untuk order yang sudah dibayar")
+	ErrReturnQuantity       = errors.New("jumlah retur melebihi barang yang bisa diretur")
+	ErrReturnStatus         = errors.New("status retur tidak bisa diubah")
+	ErrRefundExceeded       = errors.New("refund melebihi nilai pembayaran")
+	ErrTaxRateNotFound      = errors.New("tarif pajak tidak ditemukan")
+	ErrCouponNotFound       = errors.New("kupon tidak ditemukan")
+	ErrCouponExists         = errors.New("kode kupon sudah dipakai")
+	ErrCouponInactive       = errors.New("kupon belum atau sudah tidak berlaku")
+	ErrCouponMinSpend       = errors.New("belanja belum memenuhi minimum kupon")
+	ErrCouponExhausted      = errors.New("kuota kupon habis")
+	ErrCouponUserLimit      = errors.New("batas pemakaian kupon untuk user ini tercapai")
+	ErrCouponScope          = errors.New("hanya satu kupon per toko dan satu kupon platform")
+	ErrCouponNotApply       = errors.New("kupon tidak berlaku untuk isi cart")
 )
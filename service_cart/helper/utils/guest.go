@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// secret terpisah supaya token guest tidak bisa dipakai sebagai jwt user
+var guest_secret = []byte(os.Getenv("GUEST_TOKEN_SECRET"))
+
+const GuestCartTTL = 7 * 24 * time.Hour
+
+type GuestClaims struct {
+	GuestID string `json:"guest_id"`
+	jwt.RegisteredClaims
+}
+
+func GenerateGuestToken() (string, string, error) {
+	guestId := uuid.NewString()
+	claims := GuestClaims{
+		GuestID: guestId,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(GuestCartTTL)),
+			IssuedAt:  &jwt.NumericDate{Time: time.Now()},
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString(guest_secret)
+	if err != nil {
+		return "", "", err
+	}
+
+	return signed, guestId, nil
+}
+
+func ValidateGuestToken(tokenstring string) (string, error) {
+	token, err := jwt.ParseWithClaims(tokenstring, &GuestClaims{}, func(t *jwt.Token) (interface{}, error) {
+		return guest_secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return "", err
+	}
+
+	claims, ok := token.Claims.(*GuestClaims)
+	if !ok || !token.Valid || claims.GuestID == "" {
+		return "", errors.New("invalid guest token")
+	}
+
+	return claims.GuestID, nil
+}
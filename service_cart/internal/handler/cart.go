@@ -28,7 +28,7 @@ func (h *CartHandler) GetMyCartItems(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response, err := h.cartUsecase.GetMyCartItems(claims.UserID)
+	response, err := h.cartUsecase.GetMyCart(claims.UserID)
 	if err != nil {
 		switch err {
 		case utils.ErrUnavaible:
@@ -66,6 +66,7 @@ func (h *CartHandler) CreateCartItem(w http.ResponseWriter, r *http.Request) {
 	}
 
 	req.UserID = claims.UserID
+	req.Email = claims.Email
 	req.ProductID = uint(paramsProductId)
 	if err := h.cartUsecase.CreateCartItem(&req); err != nil {
 		switch err {
@@ -90,8 +91,16 @@ func (h *CartHandler) UpdateAmountCartItem(w http.ResponseWriter, r *http.Reques
 	}
 
 	params := mux.Vars(r)
-	paramsCartItemId, _ := strconv.Atoi(params["cartItemId"])
-	paramsProductId, _ := strconv.Atoi(params["productId"])
+	paramsCartItemId, err := strconv.Atoi(params["cartItemId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+	paramsProductId, err := strconv.Atoi(params["productId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
 
 	var req dto.UpdateAmountCartItemReq
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -105,6 +114,7 @@ func (h *CartHandler) UpdateAmountCartItem(w http.ResponseWriter, r *http.Reques
 	}
 
 	req.UserID = claims.UserID
+	req.Email = claims.Email
 	req.ID = uint(paramsCartItemId)
 	req.ProductID = uint(paramsProductId)
 	if err := h.cartUsecase.UpdateAmountCartItem(&req); err != nil {
@@ -112,6 +122,9 @@ func (h *CartHandler) UpdateAmountCartItem(w http.ResponseWriter, r *http.Reques
 		case utils.ErrStocknotEnough:
 			utils.WriteError(w, http.StatusBadRequest, "stock tak cukup")
 			return
+		case utils.ErrCartItemNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
 		default:
 			utils.WriteError(w, http.StatusInternalServerError, err.Error())
 			return
@@ -122,7 +135,7 @@ func (h *CartHandler) UpdateAmountCartItem(w http.ResponseWriter, r *http.Reques
 	utils.WriteJSON(w, http.StatusOK, nil)
 }
 
-func (h *CartHandler) UpdatePaidCartItem(w http.ResponseWriter, r *http.Request) {
+func (h *CartHandler) DeleteCartItem(w http.ResponseWriter, r *http.Request) {
 	claimsRaw := r.Context().Value(middleware.UserContextKey)
 	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
 	if !ok {
@@ -131,52 +144,21 @@ func (h *CartHandler) UpdatePaidCartItem(w http.ResponseWriter, r *http.Request)
 	}
 
 	params := mux.Vars(r)
-	paramsId, _ := strconv.Atoi(params["cartItemId"])
-	paramsProductId, _ := strconv.Atoi(params["productId"])
-
-	var req dto.UpdatePaidCartItemReq
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid")
-		return
-	}
-	if req.PurchaseAmount < 1 {
-		utils.WriteError(w, http.StatusBadRequest, "invalid stock")
+	paramsId, err := strconv.Atoi(params["cartItemId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
 		return
 	}
 
-	req.UserID = claims.UserID
-	req.ID = uint(paramsId)
-	req.ProductID = uint(paramsProductId)
-	if err := h.cartUsecase.UpdatePaidCartItem(&req); err != nil {
+	if err := h.cartUsecase.DeleteCartItem(claims.UserID, uint(paramsId)); err != nil {
 		switch err {
-		case utils.ErrStocknotEnough:
-			utils.WriteError(w, http.StatusBadRequest, "stock tak cukup")
+		case utils.ErrCartItemNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
 			return
 		default:
 			utils.WriteError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
-
-	}
-
-	utils.WriteJSON(w, http.StatusOK, nil)
-
-}
-
-func (h *CartHandler) DeleteCartItem(w http.ResponseWriter, r *http.Request) {
-	claimsRaw := r.Context().Value(middleware.UserContextKey)
-	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
-	if !ok {
-		utils.WriteError(w, http.StatusUnauthorized, "protected api")
-		return
-	}
-
-	params := mux.Vars(r)
-	paramsId, _ := strconv.Atoi(params["cartItemId"])
-
-	if err := h.cartUsecase.DeleteCartItem(claims.UserID, uint(paramsId)); err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, err.Error())
-		return
 	}
 
 	utils.WriteJSON(w, http.StatusOK, nil)
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"service_cart/dto"
+	"service_cart/helper/middleware"
+	"service_cart/helper/utils"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// stubCartUsecase mencatat argumen yang diterima dan mengembalikan err apa adanya.
+// Aturan merge dan kepemilikan item diuji di repository, di sini hanya pemetaan
+// request ke usecase dan error ke status http.
+type stubCartUsecase struct {
+	err error
+
+	createReq *dto.CreateCartItemReq
+	updateReq *dto.UpdateAmountCartItemReq
+	deleted   []uint
+	calls     int
+}
+
+func (s *stubCartUsecase) GetMyCartItems(userId uint) ([]dto.CartItem, error) {
+	s.calls++
+	return nil, s.err
+}
+
+func (s *stubCartUsecase) GetMyCart(userId uint) (*dto.Cart, error) {
+	s.calls++
+	return &dto.Cart{}, s.err
+}
+
+func (s *stubCartUsecase) CreateCartItem(req *dto.CreateCartItemReq) error {
+	s.calls++
+	s.createReq = req
+	return s.err
+}
+
+func (s *stubCartUsecase) UpdateAmountCartItem(req *dto.UpdateAmountCartItemReq) error {
+	s.calls++
+	s.updateReq = req
+	return s.err
+}
+
+func (s *stubCartUsecase) DeleteCartItem(userId, id uint) error {
+	s.calls++
+	s.deleted = []uint{userId, id}
+	return s.err
+}
+
+func (s *stubCartUsecase) UpdateIsDeleteProduct(id uint) error { return s.err }
+
+func (s *stubCartUsecase) UpdateCurrentPrice(productId uint, price int64) error { return s.err }
+
+func (s *stubCartUsecase) WriteKafkaMessage(topic string, key string, payload interface{}) error {
+	return s.err
+}
+
+func newCartRequest(method, path, body string, userId uint, vars map[string]string) *http.Request {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if userId != 0 {
+		claims := &utils.JWTCLAIMS{UserID: userId, Email: "user@mail.com"}
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserContextKey, claims))
+	}
+	return mux.SetURLVars(req, vars)
+}
+
+func TestCreateCartItemHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		userId uint
+		err    error
+		want   int
+	}{
+		{"ok", `{"purchase_amount":3}`, 1, nil, http.StatusOK},
+		{"stock not enough", `{"purchase_amount":3}`, 1, utils.ErrStocknotEnough, http.StatusBadRequest},
+		{"usecase error", `{"purchase_amount":3}`, 1, errors.New("db down"), http.StatusInternalServerError},
+		{"zero amount", `{"purchase_amount":0}`, 1, nil, http.StatusBadRequest},
+		{"invalid body", `{`, 1, nil, http.StatusBadRequest},
+		{"no auth", `{"purchase_amount":3}`, 0, nil, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &stubCartUsecase{err: tt.err}
+			rec := httptest.NewRecorder()
+			h := NewCartpHandler(uc)
+			h.CreateCartItem(rec, newCartRequest(http.MethodPost, "/cart/create/7", tt.body, tt.userId, map[string]string{"productId": "7"}))
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+
+	uc := &stubCartUsecase{}
+	NewCartpHandler(uc).CreateCartItem(httptest.NewRecorder(), newCartRequest(http.MethodPost, "/cart/create/7", `{"purchase_amount":3}`, 1, map[string]string{"productId": "7"}))
+	want := dto.CreateCartItemReq{UserID: 1, Email: "user@mail.com", ProductID: 7, PurchaseAmount: 3}
+	if uc.createReq == nil || *uc.createReq != want {
+		t.Fatalf("usecase req = %+v, want %+v", uc.createReq, want)
+	}
+}
+
+func TestUpdateAmountCartItemHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		userId uint
+		vars   map[string]string
+		err    error
+		want   int
+	}{
+		{"ok", 1, map[string]string{"cartItemId": "3", "productId": "7"}, nil, http.StatusOK},
+		{"not found", 1, map[string]string{"cartItemId": "3", "productId": "7"}, utils.ErrCartItemNotFound, http.StatusNotFound},
+		{"stock not enough", 1, map[string]string{"cartItemId": "3", "productId": "7"}, utils.ErrStocknotEnough, http.StatusBadRequest},
+		{"invalid id", 1, map[string]string{"cartItemId": "abc", "productId": "7"}, nil, http.StatusBadRequest},
+		{"invalid product", 1, map[string]string{"cartItemId": "3", "productId": "abc"}, nil, http.StatusBadRequest},
+		{"no auth", 0, map[string]string{"cartItemId": "3", "productId": "7"}, nil, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &stubCartUsecase{err: tt.err}
+			rec := httptest.NewRecorder()
+			NewCartpHandler(uc).UpdateAmountCartItem(rec, newCartRequest(http.MethodPut, "/cart/update-amount", `{"purchase_amount":4}`, tt.userId, tt.vars))
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusBadRequest && tt.err == nil && uc.calls != 0 {
+				t.Fatal("usecase called for invalid request")
+			}
+		})
+	}
+
+	uc := &stubCartUsecase{}
+	NewCartpHandler(uc).UpdateAmountCartItem(httptest.NewRecorder(), newCartRequest(http.MethodPut, "/cart/update-amount", `{"purchase_amount":4}`, 1, map[string]string{"cartItemId": "3", "productId": "7"}))
+	want := dto.UpdateAmountCartItemReq{UserID: 1, Email: "user@mail.com", ID: 3, ProductID: 7, PurchaseAmount: 4}
+	if uc.updateReq == nil || *uc.updateReq != want {
+		t.Fatalf("usecase req = %+v, want %+v", uc.updateReq, want)
+	}
+}
+
+func TestDeleteCartItemHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		userId uint
+		id     string
+		err    error
+		want   int
+	}{
+		{"ok", 1, "3", nil, http.StatusOK},
+		{"not found", 1, "3", utils.ErrCartItemNotFound, http.StatusNotFound},
+		{"usecase error", 1, "3", errors.New("db down"), http.StatusInternalServerError},
+		{"invalid id", 1, "abc", nil, http.StatusBadRequest},
+		{"no auth", 0, "3", nil, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &stubCartUsecase{err: tt.err}
+			rec := httptest.NewRecorder()
+			NewCartpHandler(uc).DeleteCartItem(rec, newCartRequest(http.MethodDelete, "/cart/delete/"+tt.id, "", tt.userId, map[string]string{"cartItemId": tt.id}))
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+
+	uc := &stubCartUsecase{}
+	NewCartpHandler(uc).DeleteCartItem(httptest.NewRecorder(), newCartRequest(http.MethodDelete, "/cart/delete/3", "", 1, map[string]string{"cartItemId": "3"}))
+	if len(uc.deleted) != 2 || uc.deleted[0] != 1 || uc.deleted[1] != 3 {
+		t.Fatalf("usecase delete args = %v, want [1 3]", uc.deleted)
+	}
+}
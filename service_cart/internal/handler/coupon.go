@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"service_cart/dto"
+	"service_cart/entity"
+	"service_cart/helper/middleware"
+	"service_cart/helper/utils"
+	"service_cart/internal/usecase"
+
+	"github.com/gorilla/mux"
+)
+
+type CouponHandler struct {
+	couponUsecase usecase.CouponUsecase
+}
+
+func NewCouponHandler(couponUsecase usecase.CouponUsecase) *CouponHandler {
+	return &CouponHandler{couponUsecase}
+}
+
+func (h *CouponHandler) CreateCoupon(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	var req dto.CreateCouponReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	if req.Code == "" || len(req.Code) > 32 {
+		utils.WriteError(w, http.StatusBadRequest, "kode kupon wajib, maksimal 32 karakter")
+		return
+	}
+	if req.DiscountType != entity.CouponPercentage && req.DiscountType != entity.CouponFixed {
+		utils.WriteError(w, http.StatusBadRequest, "discount_type harus percentage atau fixed")
+		return
+	}
+	if req.Value <= 0 || (req.DiscountType == entity.CouponPercentage && req.Value > 100) {
+		utils.WriteError(w, http.StatusBadRequest, "nilai diskon tidak valid")
+		return
+	}
+	if req.MinSpend < 0 || req.UsageLimit < 0 || req.PerUserLimit < 0 {
+		utils.WriteError(w, http.StatusBadRequest, "batas kupon tidak boleh negatif")
+		return
+	}
+	if !req.EndsAt.After(req.StartsAt) {
+		utils.WriteError(w, http.StatusBadRequest, "ends_at harus setelah starts_at")
+		return
+	}
+
+	req.UserID = claims.UserID
+	response, err := h.couponUsecase.CreateCoupon(&req)
+	if err != nil {
+		switch err {
+		case utils.ErrNotAdmin:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		case utils.ErrCouponExists:
+			utils.WriteError(w, http.StatusConflict, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *CouponHandler) ApplyToCart(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	response, err := h.couponUsecase.ApplyToCart(claims.UserID, params["code"])
+	if err != nil {
+		switch err {
+		case utils.ErrCouponNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		case utils.ErrCouponInactive, utils.ErrCouponScope:
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		case utils.ErrCouponExhausted, utils.ErrCouponUserLimit:
+			utils.WriteError(w, http.StatusConflict, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *CouponHandler) RemoveFromCart(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	if err := h.couponUsecase.RemoveFromCart(claims.UserID, params["code"]); err != nil {
+		switch err {
+		case utils.ErrCouponNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
+
+func (h *CouponHandler) GetCartCoupons(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	response, err := h.couponUsecase.GetCartCoupons(claims.UserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"service_cart/dto"
+	"service_cart/helper/middleware"
+	"service_cart/helper/utils"
+	"service_cart/internal/usecase"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type GuestCartHandler struct {
+	guestUsecase usecase.GuestCartUsecase
+}
+
+func NewGuestCartHandler(guestUsecase usecase.GuestCartUsecase) *GuestCartHandler {
+	return &GuestCartHandler{guestUsecase}
+}
+
+func (h *GuestCartHandler) IssueToken(w http.ResponseWriter, r *http.Request) {
+	token, err := h.guestUsecase.IssueToken()
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]string{
+		"guest_token": token,
+	})
+}
+
+func (h *GuestCartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
+	guestId, ok := r.Context().Value(middleware.GuestContextKey).(string)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "guest api")
+		return
+	}
+
+	response, err := h.guestUsecase.GetCart(guestId)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *GuestCartHandler) AddItem(w http.ResponseWriter, r *http.Request) {
+	guestId, ok := r.Context().Value(middleware.GuestContextKey).(string)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "guest api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsProductId, err := strconv.Atoi(params["productId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	var req dto.GuestCartItemReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	if req.PurchaseAmount < 1 {
+		utils.WriteError(w, http.StatusBadRequest, "invalid stock")
+		return
+	}
+
+	if err := h.guestUsecase.AddItem(guestId, uint(paramsProductId), req.PurchaseAmount); err != nil {
+		switch err {
+		case utils.ErrStocknotEnough:
+			utils.WriteError(w, http.StatusBadRequest, "stock tak cukup")
+			return
+		case utils.ErrProductDeleted:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
+
+func (h *GuestCartHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
+	guestId, ok := r.Context().Value(middleware.GuestContextKey).(string)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "guest api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsProductId, err := strconv.Atoi(params["productId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	var req dto.GuestCartItemReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	if req.PurchaseAmount < 1 {
+		utils.WriteError(w, http.StatusBadRequest, "invalid stock")
+		return
+	}
+
+	if err := h.guestUsecase.UpdateItem(guestId, uint(paramsProductId), req.PurchaseAmount); err != nil {
+		switch err {
+		case utils.ErrStocknotEnough:
+			utils.WriteError(w, http.StatusBadRequest, "stock tak cukup")
+			return
+		case utils.ErrProductDeleted, utils.ErrCartItemNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
+
+func (h *GuestCartHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
+	guestId, ok := r.Context().Value(middleware.GuestContextKey).(string)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "guest api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsProductId, err := strconv.Atoi(params["productId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	if err := h.guestUsecase.RemoveItem(guestId, uint(paramsProductId)); err != nil {
+		switch err {
+		case utils.ErrCartItemNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
+
+// MergeGuestCart dipanggil user yang sudah login dengan guest token lamanya
+func (h *GuestCartHandler) MergeGuestCart(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	guestToken := r.Header.Get(middleware.GuestTokenHeader)
+	if guestToken == "" {
+		utils.WriteError(w, http.StatusBadRequest, "tak ada guest token")
+		return
+	}
+
+	response, err := h.guestUsecase.MergeIntoUser(claims.UserID, claims.Email, guestToken)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
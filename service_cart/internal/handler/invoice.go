@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"service_cart/helper/middleware"
+	"service_cart/helper/utils"
+	"service_cart/internal/usecase"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type InvoiceHandler struct {
+	invoiceUsecase usecase.InvoiceUsecase
+}
+
+func NewInvoiceHandler(invoiceUsecase usecase.InvoiceUsecase) *InvoiceHandler {
+	return &InvoiceHandler{invoiceUsecase}
+}
+
+func (h *InvoiceHandler) DownloadInvoice(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsOrderId, err := strconv.Atoi(params["orderId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	file, err := h.invoiceUsecase.GetInvoiceFile(claims.UserID, uint(paramsOrderId), claims.Email)
+	if err != nil {
+		switch err {
+		case utils.ErrOrderNotFound, utils.ErrInvoiceNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		case utils.ErrInvoiceUnavailable:
+			utils.WriteError(w, http.StatusConflict, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file.Filename))
+	w.Header().Set("Content-Length", strconv.Itoa(len(file.Content)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(file.Content)
+}
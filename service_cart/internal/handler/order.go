@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"service_cart/dto"
+	"service_cart/helper/middleware"
+	"service_cart/helper/utils"
+	"service_cart/internal/usecase"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+type OrderHandler struct {
+	orderUsecase usecase.OrderUsecase
+}
+
+func NewOrderHandler(orderUsecase usecase.OrderUsecase) *OrderHandler {
+	return &OrderHandler{orderUsecase}
+}
+
+func (h *OrderHandler) Checkout(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	var req dto.CheckoutReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	if strings.TrimSpace(req.ShippingMethod) == "" {
+		utils.WriteError(w, http.StatusBadRequest, "shipping_method wajib diisi")
+		return
+	}
+
+	response, err := h.orderUsecase.Checkout(claims.UserID, claims.Email, &req)
+	if err != nil {
+		switch err {
+		case utils.ErrCouponNotFound, utils.ErrAddressNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		case utils.ErrCouponInactive, utils.ErrCouponMinSpend, utils.ErrCouponScope, utils.ErrCouponNotApply, utils.ErrShippingMethod:
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		case utils.ErrCouponExhausted, utils.ErrCouponUserLimit:
+			utils.WriteError(w, http.StatusConflict, err.Error())
+			return
+		case utils.ErrEmptyCart:
+			utils.WriteError(w, http.StatusBadRequest, "cart kosong")
+			return
+		case utils.ErrStocknotEnough:
+			utils.WriteError(w, http.StatusBadRequest, "stock tak cukup")
+			return
+		case utils.ErrProductDeleted:
+			utils.WriteError(w, http.StatusBadRequest, "ada product yang sudah dihapus")
+			return
+		case utils.ErrCartChanged:
+			utils.WriteError(w, http.StatusConflict, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusAccepted, response)
+}
+
+func (h *OrderHandler) GetMyOrders(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	response, err := h.orderUsecase.GetMyOrders(claims.UserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *OrderHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsOrderId, err := strconv.Atoi(params["orderId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	response, err := h.orderUsecase.GetOrder(claims.UserID, uint(paramsOrderId))
+	if err != nil {
+		switch err {
+		case utils.ErrOrderNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *OrderHandler) GetOrderSaga(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsOrderId, err := strconv.Atoi(params["orderId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	response, err := h.orderUsecase.GetOrderSaga(claims.UserID, uint(paramsOrderId))
+	if err != nil {
+		switch err {
+		case utils.ErrSagaNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *OrderHandler) CancelOrder(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsOrderId, err := strconv.Atoi(params["orderId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	if err := h.orderUsecase.CancelOrder(claims.UserID, uint(paramsOrderId)); err != nil {
+		switch err {
+		case utils.ErrOrderNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		case utils.ErrInvalidStatus, utils.ErrCheckoutRunning:
+			utils.WriteError(w, http.StatusConflict, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
+
+func (h *OrderHandler) CompleteOrder(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsOrderId, err := strconv.Atoi(params["orderId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	if err := h.orderUsecase.CompleteOrder(claims.UserID, uint(paramsOrderId)); err != nil {
+		switch err {
+		case utils.ErrOrderNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		case utils.ErrInvalidStatus:
+			utils.WriteError(w, http.StatusConflict, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
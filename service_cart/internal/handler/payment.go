@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"service_cart/helper/middleware"
+	"service_cart/helper/payment"
+	"service_cart/helper/utils"
+	"service_cart/internal/usecase"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type PaymentHandler struct {
+	paymentUsecase usecase.PaymentUsecase
+}
+
+func NewPaymentHandler(paymentUsecase usecase.PaymentUsecase) *PaymentHandler {
+	return &PaymentHandler{paymentUsecase}
+}
+
+func (h *PaymentHandler) Pay(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsOrderId, err := strconv.Atoi(params["orderId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	response, err := h.paymentUsecase.Pay(claims.UserID, uint(paramsOrderId))
+	if err != nil {
+		switch err {
+		case utils.ErrPaymentNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		case utils.ErrPaymentStatus:
+			utils.WriteError(w, http.StatusConflict, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusAccepted, response)
+}
+
+func (h *PaymentHandler) GetPayment(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsOrderId, err := strconv.Atoi(params["orderId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	response, err := h.paymentUsecase.GetPayment(claims.UserID, uint(paramsOrderId))
+	if err != nil {
+		switch err {
+		case utils.ErrPaymentNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *PaymentHandler) Webhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "body tidak terbaca")
+		return
+	}
+
+	if err := h.paymentUsecase.HandleWebhook(body, r.Header.Get(payment.SignatureHeader)); err != nil {
+		switch err {
+		case payment.ErrInvalidSignature:
+			utils.WriteError(w, http.StatusUnauthorized, err.Error())
+			return
+		case utils.ErrPaymentNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"service_cart/dto"
+	"service_cart/entity"
+	"service_cart/helper/middleware"
+	"service_cart/helper/utils"
+	"service_cart/internal/usecase"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+const maxReturnPhotos = 5
+
+type ReturnHandler struct {
+	returnUsecase usecase.ReturnUsecase
+}
+
+func NewReturnHandler(returnUsecase usecase.ReturnUsecase) *ReturnHandler {
+	return &ReturnHandler{returnUsecase}
+}
+
+func validateReturn(req *dto.ReturnReq) string {
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Quantity <= 0 {
+		return "quantity harus lebih dari 0"
+	}
+	if req.Reason == "" {
+		return "reason wajib diisi"
+	}
+	if len(req.Reason) > 1000 {
+		return "reason maksimal 1000 karakter"
+	}
+	if len(req.Photos) > maxReturnPhotos {
+		return "photos maksimal 5"
+	}
+	for _, photo := range req.Photos {
+		u, err := url.Parse(photo)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(photo) > 500 {
+			return "photos harus berupa url http atau https"
+		}
+	}
+	return ""
+}
+
+func (h *ReturnHandler) RequestReturn(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsOrderId, err := strconv.Atoi(params["orderId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+	paramsOrderItemId, err := strconv.Atoi(params["orderItemId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	var req dto.ReturnReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	if msg := validateReturn(&req); msg != "" {
+		utils.WriteError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	req.UserID = claims.UserID
+	req.Email = claims.Email
+	req.OrderID = uint(paramsOrderId)
+	req.OrderItemID = uint(paramsOrderItemId)
+	response, err := h.returnUsecase.RequestReturn(&req)
+	if err != nil {
+		switch err {
+		case utils.ErrOrderNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		case utils.ErrReturnNotAllowed, utils.ErrReturnQuantity:
+			utils.WriteError(w, http.StatusConflict, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, response)
+}
+
+func (h *ReturnHandler) GetMyReturns(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	response, err := h.returnUsecase.GetMyReturns(claims.UserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *ReturnHandler) GetStoreReturns(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsStoreId, err := strconv.Atoi(params["storeId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", entity.ReturnRequested, entity.ReturnApproved, entity.ReturnRejected, entity.ReturnRefunded:
+	default:
+		utils.WriteError(w, http.StatusBadRequest, "status tidak valid")
+		return
+	}
+
+	response, err := h.returnUsecase.GetStoreReturns(claims.UserID, uint(paramsStoreId), status)
+	if err != nil {
+		switch err {
+		case utils.ErrNotAdmin:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *ReturnHandler) decide(w http.ResponseWriter, r *http.Request, approve bool) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsReturnId, err := strconv.Atoi(params["returnId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	// catatan wajib saat menolak, opsional saat menyetujui
+	var req dto.ReturnDecisionReq
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "invalid body")
+			return
+		}
+	}
+	req.Note = strings.TrimSpace(req.Note)
+	if !approve && req.Note == "" {
+		utils.WriteError(w, http.StatusBadRequest, "note wajib diisi saat menolak retur")
+		return
+	}
+	if len(req.Note) > 1000 {
+		utils.WriteError(w, http.StatusBadRequest, "note maksimal 1000 karakter")
+		return
+	}
+
+	req.UserID = claims.UserID
+	req.ReturnID = uint(paramsReturnId)
+	req.Approve = approve
+	response, err := h.returnUsecase.DecideReturn(&req)
+	if err != nil {
+		switch err {
+		case utils.ErrReturnNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		case utils.ErrNotAdmin:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		case utils.ErrReturnStatus:
+			utils.WriteError(w, http.StatusConflict, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *ReturnHandler) ApproveReturn(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, true)
+}
+
+func (h *ReturnHandler) RejectReturn(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, false)
+}
@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"service_cart/dto"
+	"service_cart/entity"
+	"service_cart/helper/middleware"
+	"service_cart/helper/utils"
+	"service_cart/internal/usecase"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+type ShippingHandler struct {
+	shippingUsecase usecase.ShippingUsecase
+}
+
+func NewShippingHandler(shippingUsecase usecase.ShippingUsecase) *ShippingHandler {
+	return &ShippingHandler{shippingUsecase}
+}
+
+func (h *ShippingHandler) CreateRate(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsStoreId, err := strconv.Atoi(params["storeId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	var req dto.ShippingRateReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	if method := strings.TrimSpace(req.Method); method == "" || len(method) > 30 {
+		utils.WriteError(w, http.StatusBadRequest, "method wajib, maksimal 30 karakter")
+		return
+	}
+	if len(req.Zone) > 100 {
+		utils.WriteError(w, http.StatusBadRequest, "zone maksimal 100 karakter")
+		return
+	}
+	if req.MinWeight < 0 || req.MaxWeight < 0 || (req.MaxWeight > 0 && req.MaxWeight < req.MinWeight) {
+		utils.WriteError(w, http.StatusBadRequest, "rentang berat tidak valid")
+		return
+	}
+	if req.BasePrice < 0 || req.PerKgPrice < 0 {
+		utils.WriteError(w, http.StatusBadRequest, "tarif tidak boleh negatif")
+		return
+	}
+
+	req.UserID = claims.UserID
+	req.StoreID = uint(paramsStoreId)
+	response, err := h.shippingUsecase.CreateRate(&req)
+	if err != nil {
+		switch err {
+		case utils.ErrNotAdmin:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *ShippingHandler) GetRates(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	paramsStoreId, err := strconv.Atoi(params["storeId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	response, err := h.shippingUsecase.GetRates(uint(paramsStoreId))
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *ShippingHandler) DeleteRate(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsStoreId, err := strconv.Atoi(params["storeId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+	paramsRateId, err := strconv.Atoi(params["rateId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	if err := h.shippingUsecase.DeleteRate(claims.UserID, uint(paramsStoreId), uint(paramsRateId)); err != nil {
+		switch err {
+		case utils.ErrNotAdmin:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		case utils.ErrShippingRateNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
+
+func (h *ShippingHandler) Quote(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	// tanpa address_id memakai alamat default
+	var addressId int
+	if raw := r.URL.Query().Get("address_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil || id < 0 {
+			utils.WriteError(w, http.StatusBadRequest, "address_id tidak valid")
+			return
+		}
+		addressId = id
+	}
+
+	response, err := h.shippingUsecase.Quote(claims.UserID, uint(addressId))
+	if err != nil {
+		switch err {
+		case utils.ErrAddressNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		case utils.ErrEmptyCart:
+			utils.WriteError(w, http.StatusBadRequest, "cart kosong")
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *ShippingHandler) GetOrderShipments(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsOrderId, err := strconv.Atoi(params["orderId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	response, err := h.shippingUsecase.GetOrderShipments(claims.UserID, uint(paramsOrderId))
+	if err != nil {
+		switch err {
+		case utils.ErrOrderNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *ShippingHandler) UpdateShipmentStatus(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsShipmentId, err := strconv.Atoi(params["shipmentId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	var req dto.ShipmentStatusReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	if req.Status != entity.ShipmentPacked && req.Status != entity.ShipmentShipped && req.Status != entity.ShipmentDelivered {
+		utils.WriteError(w, http.StatusBadRequest, "status harus packed, shipped atau delivered")
+		return
+	}
+	if len(req.TrackingNumber) > 64 {
+		utils.WriteError(w, http.StatusBadRequest, "tracking_number maksimal 64 karakter")
+		return
+	}
+
+	req.UserID = claims.UserID
+	req.ShipmentID = uint(paramsShipmentId)
+	response, err := h.shippingUsecase.UpdateShipmentStatus(&req)
+	if err != nil {
+		switch err {
+		case utils.ErrShipmentNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		case utils.ErrNotAdmin:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		case utils.ErrShipmentStatus:
+			utils.WriteError(w, http.StatusConflict, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"service_cart/dto"
+	"service_cart/helper/middleware"
+	"service_cart/helper/tax"
+	"service_cart/helper/utils"
+	"service_cart/internal/usecase"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+type TaxHandler struct {
+	taxUsecase usecase.TaxUsecase
+}
+
+func NewTaxHandler(taxUsecase usecase.TaxUsecase) *TaxHandler {
+	return &TaxHandler{taxUsecase}
+}
+
+// validRegion sama dengan aturan region toko di service store
+func validRegion(region string) bool {
+	if region == "" || len(region) > 20 {
+		return false
+	}
+	for _, c := range region {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *TaxHandler) SaveRate(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	var req dto.TaxRateReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	req.Region = tax.Normalize(req.Region)
+	req.Category = tax.Normalize(req.Category)
+	req.Name = strings.TrimSpace(req.Name)
+	if !validRegion(req.Region) {
+		utils.WriteError(w, http.StatusBadRequest, "region tidak valid")
+		return
+	}
+	if len(req.Category) > 50 {
+		utils.WriteError(w, http.StatusBadRequest, "category maksimal 50 karakter")
+		return
+	}
+	if req.Name == "" || len(req.Name) > 50 {
+		utils.WriteError(w, http.StatusBadRequest, "name wajib, maksimal 50 karakter")
+		return
+	}
+	if req.Rate < 0 || req.Rate > 10000 {
+		utils.WriteError(w, http.StatusBadRequest, "rate dalam basis poin, 0 sampai 10000")
+		return
+	}
+	if req.Mode != tax.ModeInclusive && req.Mode != tax.ModeExclusive {
+		utils.WriteError(w, http.StatusBadRequest, "mode harus inclusive atau exclusive")
+		return
+	}
+
+	req.UserID = claims.UserID
+	response, err := h.taxUsecase.SaveRate(&req)
+	if err != nil {
+		switch err {
+		case utils.ErrNotAdmin:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *TaxHandler) GetRates(w http.ResponseWriter, r *http.Request) {
+	response, err := h.taxUsecase.GetRates(r.URL.Query().Get("region"))
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *TaxHandler) DeleteRate(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsRateId, err := strconv.Atoi(params["rateId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	if err := h.taxUsecase.DeleteRate(claims.UserID, uint(paramsRateId)); err != nil {
+		switch err {
+		case utils.ErrNotAdmin:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		case utils.ErrTaxRateNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"service_cart/dto"
+	"service_cart/helper/middleware"
+	"service_cart/helper/utils"
+	"service_cart/internal/usecase"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+type WishlistHandler struct {
+	wishlistUsecase usecase.WishlistUsecase
+}
+
+func NewWishlistHandler(wishlistUsecase usecase.WishlistUsecase) *WishlistHandler {
+	return &WishlistHandler{wishlistUsecase}
+}
+
+func (h *WishlistHandler) CreateWishlist(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	var req dto.WishlistReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	if name := strings.TrimSpace(req.Name); name == "" || len(name) > 100 {
+		utils.WriteError(w, http.StatusBadRequest, "nama wishlist wajib, maksimal 100 karakter")
+		return
+	}
+
+	response, err := h.wishlistUsecase.CreateWishlist(claims.UserID, req.Name)
+	if err != nil {
+		switch err {
+		case utils.ErrWishlistExists:
+			utils.WriteError(w, http.StatusConflict, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *WishlistHandler) GetMyWishlists(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	response, err := h.wishlistUsecase.GetMyWishlists(claims.UserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *WishlistHandler) DeleteWishlist(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsWishlistId, err := strconv.Atoi(params["wishlistId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	if err := h.wishlistUsecase.DeleteWishlist(claims.UserID, uint(paramsWishlistId)); err != nil {
+		switch err {
+		case utils.ErrWishlistNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
+
+func (h *WishlistHandler) AddItem(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsWishlistId, err := strconv.Atoi(params["wishlistId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+	paramsProductId, err := strconv.Atoi(params["productId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	if err := h.wishlistUsecase.AddItem(claims.UserID, uint(paramsWishlistId), uint(paramsProductId)); err != nil {
+		switch err {
+		case utils.ErrWishlistNotFound, utils.ErrProductDeleted:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
+
+func (h *WishlistHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsWishlistId, err := strconv.Atoi(params["wishlistId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+	paramsProductId, err := strconv.Atoi(params["productId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	if err := h.wishlistUsecase.RemoveItem(claims.UserID, uint(paramsWishlistId), uint(paramsProductId)); err != nil {
+		switch err {
+		case utils.ErrWishlistNotFound, utils.ErrWishlistItemNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
+
+func (h *WishlistHandler) MoveToCart(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsWishlistId, err := strconv.Atoi(params["wishlistId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+	paramsProductId, err := strconv.Atoi(params["productId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	var req dto.CreateCartItemReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	if req.PurchaseAmount < 1 {
+		utils.WriteError(w, http.StatusBadRequest, "invalid stock")
+		return
+	}
+
+	req.UserID = claims.UserID
+	req.Email = claims.Email
+	req.ProductID = uint(paramsProductId)
+	if err := h.wishlistUsecase.MoveToCart(&req, uint(paramsWishlistId)); err != nil {
+		switch err {
+		case utils.ErrWishlistNotFound, utils.ErrWishlistItemNotFound, utils.ErrProductDeleted:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		case utils.ErrStocknotEnough:
+			utils.WriteError(w, http.StatusBadRequest, "stock tak cukup")
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
+
+func (h *WishlistHandler) MoveFromCart(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsCartItemId, err := strconv.Atoi(params["cartItemId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+	paramsWishlistId, err := strconv.Atoi(params["wishlistId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	if err := h.wishlistUsecase.MoveFromCart(claims.UserID, uint(paramsCartItemId), uint(paramsWishlistId)); err != nil {
+		switch err {
+		case utils.ErrWishlistNotFound, utils.ErrCartItemNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
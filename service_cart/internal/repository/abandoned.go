@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"fmt"
+	"service_cart/entity"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type AbandonedCartRepo interface {
+	GetAbandonedCarts(idleBefore time.Time, limit int) ([]entity.CartActivity, error)
+	GetOpenCartItems(userIds []uint) ([]entity.CartItem, error)
+	MarkReminded(userId uint, at time.Time) error
+	ExpireStaleCarts(before time.Time) (int64, error)
+}
+
+type abandonedCartRepo struct {
+	db    *gorm.DB
+	redis *redis.Client
+}
+
+func NewAbandonedCartRepo(db *gorm.DB, redis *redis.Client) AbandonedCartRepo {
+	return &abandonedCartRepo{db, redis}
+}
+
+const hasOpenCartItems = "EXISTS (SELECT 1 FROM cart_items WHERE cart_items.user_id = cart_activities.user_id AND cart_items.order_id IS NULL AND cart_items.is_paid = ? AND cart_items.is_product_deleted = ?)"
+
+// GetAbandonedCarts hanya mengambil cart yang belum diingatkan sejak aktivitas terakhirnya
+func (r *abandonedCartRepo) GetAbandonedCarts(idleBefore time.Time, limit int) ([]entity.CartActivity, error) {
+	var activities []entity.CartActivity
+	if err := r.db.
+		Where("last_activity_at < ? AND (reminded_at IS NULL OR reminded_at < last_activity_at)", idleBefore).
+		Where(hasOpenCartItems, false, false).
+		Order("last_activity_at ASC").
+		Limit(limit).
+		Find(&activities).Error; err != nil {
+		return nil, err
+	}
+
+	return activities, nil
+}
+
+func (r *abandonedCartRepo) GetOpenCartItems(userIds []uint) ([]entity.CartItem, error) {
+	var items []entity.CartItem
+	if err := r.db.Where("user_id IN ? AND order_id IS NULL AND is_paid = ? AND is_product_deleted = ?", userIds, false, false).Order("id ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *abandonedCartRepo) MarkReminded(userId uint, at time.Time) error {
+	return r.db.Model(&entity.CartActivity{}).Where("user_id = ?", userId).Update("reminded_at", at).Error
+}
+
+// ExpireStaleCarts menghapus item cart yang belum checkout dari user yang tidak aktif sejak before
+func (r *abandonedCartRepo) ExpireStaleCarts(before time.Time) (int64, error) {
+	var userIds []uint
+	if err := r.db.Model(&entity.CartActivity{}).Where("last_activity_at < ?", before).Pluck("user_id", &userIds).Error; err != nil {
+		return 0, err
+	}
+	if len(userIds) == 0 {
+		return 0, nil
+	}
+
+	var expired int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// dicek ulang lewat subquery, user yang baru aktif lagi tidak ikut terhapus
+		stale := tx.Model(&entity.CartActivity{}).Select("user_id").Where("last_activity_at < ?", before)
+		result := tx.Where("user_id IN (?) AND order_id IS NULL AND is_paid = ?", stale, false).Delete(&entity.CartItem{})
+		if result.Error != nil {
+			return result.Error
+		}
+		expired = result.RowsAffected
+
+		if err := tx.Where("user_id IN (?)", stale).Delete(&entity.CartCoupon{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("last_activity_at < ?", before).Delete(&entity.CartActivity{}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	keys := make([]string, 0, len(userIds))
+	for _, u := range userIds {
+		keys = append(keys, fmt.Sprintf("user:%d:cart_items", u))
+	}
+	return expired, r.redis.Del(ctx, keys...).Err()
+}
@@ -12,19 +12,21 @@ import (
 
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CartRepo interface {
 
 	// cart item
 	GetMyCartItems(userId uint) ([]dto.CartItem, error)
+	GetOpenCartItemByProduct(userId, productId uint) (*entity.CartItem, error)
 	CreateCartItem(req *dto.CreateCartItemReq) error
 	UpdateAmountCartItem(req *dto.UpdateAmountCartItemReq) error
-	UpdatePaidCartItem(req *dto.UpdatePaidCartItemReq) error
 	DeleteCartItem(userId, id uint) error
 
 	//kafka
 	UpdateIsDeleteProduct(id uint) error
+	UpdateCurrentPrice(productId uint, price int64) error
 	WaitForResponse(correlationID string, out interface{}) error
 }
 
@@ -39,13 +41,65 @@ func NewCartRepo(db *gorm.DB, redis *redis.Client) CartRepo {
 
 var ctx = context.Background()
 
+// item cart yang masih bisa diubah: belum checkout, belum dibayar, product masih ada
+const openCartItem = "order_id IS NULL AND is_paid = ? AND is_product_deleted = ?"
+
+func (r *cartRepo) GetOpenCartItemByProduct(userId, productId uint) (*entity.CartItem, error) {
+	var item entity.CartItem
+	if err := r.db.Where("user_id = ? AND product_id = ? AND "+openCartItem, userId, productId, false, false).First(&item).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrCartItemNotFound
+		}
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// CreateCartItem menambah quantity kalau product sudah ada di cart, bukan membuat baris baru.
+// Baris cart_activities milik user di-upsert lebih dulu di transaksi yang sama sehingga
+// request bersamaan untuk user yang sama antri di baris itu. Belum ada baris cart yang
+// bisa dikunci saat product pertama kali masuk, dan unique key per product tidak dipakai
+// karena cancel order mengembalikan item ke cart.
 func (r *cartRepo) CreateCartItem(req *dto.CreateCartItemReq) error {
-	newCartItem := entity.CartItem{
-		ProductID:      &req.ProductID,
-		UserID:         req.UserID,
-		PurchaseAmount: req.PurchaseAmount}
+	tx := r.db.Begin()
 
-	if err := r.db.Model(&entity.CartItem{}).Create(&newCartItem).Error; err != nil {
+	if err := touchActivity(tx, req.UserID, req.Email); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var existing entity.CartItem
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND product_id = ? AND "+openCartItem, req.UserID, req.ProductID, false, false).
+		First(&existing).Error
+	switch {
+	case err == nil:
+		if err := tx.Model(&entity.CartItem{}).Where("id = ?", existing.ID).Updates(map[string]interface{}{
+			"purchase_amount": gorm.Expr("purchase_amount + ?", req.PurchaseAmount),
+			"current_price":   req.UnitPrice,
+		}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		newCartItem := entity.CartItem{
+			ProductID:      &req.ProductID,
+			UserID:         req.UserID,
+			PurchaseAmount: req.PurchaseAmount,
+			UnitPrice:      req.UnitPrice,
+			CurrentPrice:   req.UnitPrice}
+
+		if err := tx.Model(&entity.CartItem{}).Create(&newCartItem).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	default:
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
 		return err
 	}
 
@@ -60,24 +114,39 @@ func (r *cartRepo) CreateCartItem(req *dto.CreateCartItemReq) error {
 	return nil
 }
 
+// touchActivity mencatat waktu terakhir user mengubah cart
+func touchActivity(db *gorm.DB, userId uint, email string) error {
+	activity := entity.CartActivity{
+		UserID:         userId,
+		Email:          email,
+		LastActivityAt: time.Now(),
+	}
+	columns := []string{"last_activity_at"}
+	if email != "" {
+		columns = append(columns, "email")
+	}
+
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns(columns),
+	}).Create(&activity).Error
+}
+
 func (r *cartRepo) UpdateAmountCartItem(req *dto.UpdateAmountCartItemReq) error {
-	if err := r.db.Model(&entity.CartItem{}).Where("id = ?", req.ID).Update("purchase_amount", req.PurchaseAmount).Error; err != nil {
+	// dicek dulu karena mysql menghitung 0 rows affected kalau nilainya sama
+	var item entity.CartItem
+	if err := r.db.Where("id = ? AND user_id = ? AND product_id = ? AND "+openCartItem, req.ID, req.UserID, req.ProductID, false, false).First(&item).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrCartItemNotFound
+		}
 		return err
 	}
 
-	key := fmt.Sprintf("user:%d:cart_items", req.UserID)
-	deleted, err := r.redis.Del(ctx, key).Result()
-	if err != nil {
+	if err := r.db.Model(&entity.CartItem{}).Where("id = ? AND user_id = ?", item.ID, req.UserID).Update("purchase_amount", req.PurchaseAmount).Error; err != nil {
 		return err
 	}
-	if deleted == 0 {
-		fmt.Println("Key tidak ditemukan di Redis")
-	}
-	return nil
-}
 
-func (r *cartRepo) UpdatePaidCartItem(req *dto.UpdatePaidCartItemReq) error {
-	if err := r.db.Model(&entity.CartItem{}).Where("user_id = ? AND id = ? AND is_product_deleted = ?", req.UserID, req.ID, false).Update("is_paid", true).Error; err != nil {
+	if err := touchActivity(r.db, req.UserID, req.Email); err != nil {
 		return err
 	}
 
@@ -93,8 +162,12 @@ func (r *cartRepo) UpdatePaidCartItem(req *dto.UpdatePaidCartItemReq) error {
 }
 
 func (r *cartRepo) DeleteCartItem(userId, id uint) error {
-	if err := r.db.Model(&entity.CartItem{}).Where("id = ?", id).Delete(&entity.CartItem{}).Error; err != nil {
-		return err
+	result := r.db.Where("id = ? AND user_id = ? AND order_id IS NULL", id, userId).Delete(&entity.CartItem{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return utils.ErrCartItemNotFound
 	}
 
 	key := fmt.Sprintf("user:%d:cart_items", userId)
@@ -119,10 +192,13 @@ func (r *cartRepo) GetMyCartItems(userId uint) ([]dto.CartItem, error) {
 	}
 
 	var items []dto.CartItem
-	if err := r.db.Where("user_id = ?", userId).Find(&items).Error; err != nil {
+	if err := r.db.Where("user_id = ? AND order_id IS NULL", userId).Find(&items).Error; err != nil {
 
 		return nil, err
 	}
+	for i := range items {
+		items[i].PriceChanged = !items[i].IsPaid && items[i].CurrentPrice != items[i].UnitPrice
+	}
 
 	jsonData, _ := json.Marshal(items)
 
@@ -145,10 +221,21 @@ func (r *cartRepo) UpdateIsDeleteProduct(id uint) error {
 		return err
 	}
 
-	if err := tx.Model(&entity.CartItem{}).Update("is_product_deleted", true).Error; err != nil {
+	if err := tx.Model(&entity.CartItem{}).Where("product_id = ? AND is_product_deleted = ?", id, false).Update("is_product_deleted", true).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
+
+	// product yang dihapus juga dikeluarkan dari semua wishlist
+	if err := tx.Where("product_id = ?", id).Delete(&entity.WishlistItem{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
 	for _, u := range userId {
 		key := fmt.Sprintf("user:%d:cart_items", u)
 		deleted, err := r.redis.Del(ctx, key).Result()
@@ -163,6 +250,26 @@ func (r *cartRepo) UpdateIsDeleteProduct(id uint) error {
 	return nil
 }
 
+func (r *cartRepo) UpdateCurrentPrice(productId uint, price int64) error {
+	var userId []uint
+	if err := r.db.Model(&entity.CartItem{}).Where("product_id = ? AND is_paid = ?", productId, false).Distinct().Pluck("user_id", &userId).Error; err != nil {
+		return err
+	}
+	if len(userId) == 0 {
+		return nil
+	}
+
+	if err := r.db.Model(&entity.CartItem{}).Where("product_id = ? AND is_paid = ?", productId, false).Update("current_price", price).Error; err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(userId))
+	for _, u := range userId {
+		keys = append(keys, fmt.Sprintf("user:%d:cart_items", u))
+	}
+	return r.redis.Del(ctx, keys...).Err()
+}
+
 func (u *cartRepo) WaitForResponse(correlationID string, out interface{}) error {
 	key := fmt.Sprintf("response:%s", correlationID)
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"service_cart/dto"
+	"service_cart/helper/utils"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestCartRepo memakai sqlmock untuk mysql dan redis palsu yang menolak HELLO
+// lalu menjawab perintah lain dengan integer 1, cukup untuk DEL cache cart
+func newTestCartRepo(t *testing.T) (CartRepo, sqlmock.Sqlmock) {
+	t.Helper()
+
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rdb := redis.NewClient(&redis.Options{
+		DisableIdentity: true,
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			client, server := net.Pipe()
+			go serveFakeRedis(server)
+			return client, nil
+		},
+	})
+	t.Cleanup(func() { rdb.Close() })
+
+	return NewCartRepo(db, rdb), mock
+}
+
+func serveFakeRedis(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		// perintah dikirim sebagai array RESP: *<n>, lalu $<len> dan isi per argumen
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		var args int
+		if _, err := fmt.Sscanf(header, "*%d\r\n", &args); err != nil {
+			return
+		}
+		var command string
+		for i := 0; i < args*2; i++ {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if i == 1 {
+				command = strings.ToUpper(strings.TrimSpace(line))
+			}
+		}
+
+		reply := ":1\r\n"
+		if command == "HELLO" {
+			reply = "-ERR unknown command\r\n"
+		}
+		if _, err := conn.Write([]byte(reply)); err != nil {
+			return
+		}
+	}
+}
+
+func TestCreateCartItemMergesOpenLine(t *testing.T) {
+	repo, mock := newTestCartRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `cart_activities`")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `cart_items` WHERE user_id = ? AND product_id = ? AND order_id IS NULL")+".*FOR UPDATE").
+		WithArgs(uint(1), uint(7), false, false, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "product_id", "purchase_amount"}).AddRow(3, 1, 7, 2))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `cart_items` SET `current_price`=?,`purchase_amount`=purchase_amount + ? WHERE id = ?")).
+		WithArgs(int64(1000), 3, uint(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.CreateCartItem(&dto.CreateCartItemReq{UserID: 1, ProductID: 7, PurchaseAmount: 3, UnitPrice: 1000})
+	if err != nil {
+		t.Fatalf("CreateCartItem() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateCartItemInsertsNewLine(t *testing.T) {
+	repo, mock := newTestCartRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `cart_activities`")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `cart_items`") + ".*FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `cart_items`")).
+		WillReturnResult(sqlmock.NewResult(4, 1))
+	mock.ExpectCommit()
+
+	err := repo.CreateCartItem(&dto.CreateCartItemReq{UserID: 1, ProductID: 7, PurchaseAmount: 3, UnitPrice: 1000})
+	if err != nil {
+		t.Fatalf("CreateCartItem() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpdateAmountCartItemOtherUser(t *testing.T) {
+	repo, mock := newTestCartRepo(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `cart_items` WHERE id = ? AND user_id = ? AND product_id = ?")).
+		WithArgs(uint(3), uint(2), uint(7), false, false, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	err := repo.UpdateAmountCartItem(&dto.UpdateAmountCartItemReq{ID: 3, UserID: 2, ProductID: 7, PurchaseAmount: 4})
+	if err != utils.ErrCartItemNotFound {
+		t.Fatalf("UpdateAmountCartItem() error = %v, want %v", err, utils.ErrCartItemNotFound)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeleteCartItemOwnership(t *testing.T) {
+	tests := []struct {
+		name     string
+		affected int64
+		want     error
+	}{
+		{"owner", 1, nil},
+		{"other user or unknown item", 0, utils.ErrCartItemNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := newTestCartRepo(t)
+
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `cart_items` WHERE id = ? AND user_id = ? AND order_id IS NULL")).
+				WithArgs(uint(3), uint(1)).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+			mock.ExpectCommit()
+
+			if err := repo.DeleteCartItem(1, 3); err != tt.want {
+				t.Fatalf("DeleteCartItem() error = %v, want %v", err, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"errors"
+	"service_cart/entity"
+	"service_cart/helper/utils"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CouponRepo interface {
+	CreateCoupon(coupon *entity.Coupon) error
+	GetCouponByCode(code string) (*entity.Coupon, error)
+	GetCouponsByCode(codes []string) ([]entity.Coupon, error)
+	CountUserRedemptions(couponId, userId uint) (int64, error)
+
+	//cart
+	AddCartCoupon(userId, couponId uint) error
+	RemoveCartCoupon(userId, couponId uint) error
+	GetCartCoupons(userId uint) ([]entity.Coupon, error)
+}
+
+type couponRepo struct {
+	db *gorm.DB
+}
+
+func NewCouponRepo(db *gorm.DB) CouponRepo {
+	return &couponRepo{db}
+}
+
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func (r *couponRepo) CreateCoupon(coupon *entity.Coupon) error {
+	coupon.Code = normalizeCode(coupon.Code)
+
+	var count int64
+	if err := r.db.Model(&entity.Coupon{}).Where("code = ?", coupon.Code).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return utils.ErrCouponExists
+	}
+
+	return r.db.Create(coupon).Error
+}
+
+func (r *couponRepo) GetCouponByCode(code string) (*entity.Coupon, error) {
+	var coupon entity.Coupon
+	if err := r.db.Where("code = ?", normalizeCode(code)).First(&coupon).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrCouponNotFound
+		}
+		return nil, err
+	}
+
+	return &coupon, nil
+}
+
+func (r *couponRepo) GetCouponsByCode(codes []string) ([]entity.Coupon, error) {
+	normalized := make([]string, 0, len(codes))
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		code = normalizeCode(code)
+		if !seen[code] {
+			seen[code] = true
+			normalized = append(normalized, code)
+		}
+	}
+
+	var coupons []entity.Coupon
+	if err := r.db.Where("code IN ?", normalized).Find(&coupons).Error; err != nil {
+		return nil, err
+	}
+	if len(coupons) != len(normalized) {
+		return nil, utils.ErrCouponNotFound
+	}
+
+	return coupons, nil
+}
+
+func (r *couponRepo) CountUserRedemptions(couponId, userId uint) (int64, error) {
+	var count int64
+	if err := r.db.Model(&entity.CouponRedemption{}).Where("coupon_id = ? AND user_id = ?", couponId, userId).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *couponRepo) AddCartCoupon(userId, couponId uint) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&entity.CartCoupon{
+		UserID:   userId,
+		CouponID: couponId,
+	}).Error
+}
+
+func (r *couponRepo) RemoveCartCoupon(userId, couponId uint) error {
+	return r.db.Where("user_id = ? AND coupon_id = ?", userId, couponId).Delete(&entity.CartCoupon{}).Error
+}
+
+func (r *couponRepo) GetCartCoupons(userId uint) ([]entity.Coupon, error) {
+	var coupons []entity.Coupon
+	if err := r.db.Joins("JOIN cart_coupons ON cart_coupons.coupon_id = coupons.id").
+		Where("cart_coupons.user_id = ?", userId).
+		Order("cart_coupons.created_at ASC").
+		Find(&coupons).Error; err != nil {
+		return nil, err
+	}
+
+	return coupons, nil
+}
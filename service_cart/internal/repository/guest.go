@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"fmt"
+	"service_cart/helper/utils"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// GuestCartRepo menyimpan cart guest di redis hash product_id -> quantity
+type GuestCartRepo interface {
+	GetAmount(guestId string, productId uint) (int, error)
+	AddItem(guestId string, productId uint, amount int) error
+	SetItem(guestId string, productId uint, amount int) error
+	RemoveItem(guestId string, productId uint) error
+	GetItems(guestId string) (map[uint]int, error)
+	Clear(guestId string) error
+}
+
+type guestCartRepo struct {
+	redis *redis.Client
+}
+
+func NewGuestCartRepo(redis *redis.Client) GuestCartRepo {
+	return &guestCartRepo{redis}
+}
+
+func guestCartKey(guestId string) string {
+	return fmt.Sprintf("guest:%s:cart", guestId)
+}
+
+func (r *guestCartRepo) GetAmount(guestId string, productId uint) (int, error) {
+	amount, err := r.redis.HGet(ctx, guestCartKey(guestId), strconv.Itoa(int(productId))).Int()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return amount, err
+}
+
+// setiap tulis memperpanjang ttl cart guest
+func (r *guestCartRepo) touch(pipe redis.Pipeliner, guestId string) {
+	pipe.Expire(ctx, guestCartKey(guestId), utils.GuestCartTTL)
+}
+
+func (r *guestCartRepo) AddItem(guestId string, productId uint, amount int) error {
+	_, err := r.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HIncrBy(ctx, guestCartKey(guestId), strconv.Itoa(int(productId)), int64(amount))
+		r.touch(pipe, guestId)
+		return nil
+	})
+	return err
+}
+
+func (r *guestCartRepo) SetItem(guestId string, productId uint, amount int) error {
+	exists, err := r.redis.HExists(ctx, guestCartKey(guestId), strconv.Itoa(int(productId))).Result()
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return utils.ErrCartItemNotFound
+	}
+
+	_, err = r.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, guestCartKey(guestId), strconv.Itoa(int(productId)), amount)
+		r.touch(pipe, guestId)
+		return nil
+	})
+	return err
+}
+
+func (r *guestCartRepo) RemoveItem(guestId string, productId uint) error {
+	deleted, err := r.redis.HDel(ctx, guestCartKey(guestId), strconv.Itoa(int(productId))).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return utils.ErrCartItemNotFound
+	}
+	return nil
+}
+
+func (r *guestCartRepo) GetItems(guestId string) (map[uint]int, error) {
+	raw, err := r.redis.HGetAll(ctx, guestCartKey(guestId)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make(map[uint]int, len(raw))
+	for field, value := range raw {
+		productId, err := strconv.Atoi(field)
+		if err != nil {
+			continue
+		}
+		amount, err := strconv.Atoi(value)
+		if err != nil || amount <= 0 {
+			continue
+		}
+		items[uint(productId)] = amount
+	}
+
+	return items, nil
+}
+
+func (r *guestCartRepo) Clear(guestId string) error {
+	return r.redis.Del(ctx, guestCartKey(guestId)).Err()
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"service_cart/entity"
+	"service_cart/helper/utils"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InvoiceRepo interface {
+	IssueInvoice(invoice *entity.Invoice, dir string) (*entity.Invoice, error)
+	GetInvoiceByOrder(userId, orderId uint) (*entity.Invoice, error)
+}
+
+type invoiceRepo struct {
+	db *gorm.DB
+}
+
+func NewInvoiceRepo(db *gorm.DB) InvoiceRepo {
+	return &invoiceRepo{db}
+}
+
+// IssueInvoice mengembalikan invoice yang sudah ada untuk order itu, kalau belum ada
+// nomor berikutnya diambil dari baris sequence yang terkunci sampai commit supaya tidak loncat
+func (r *invoiceRepo) IssueInvoice(invoice *entity.Invoice, dir string) (*entity.Invoice, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var existing entity.Invoice
+		err := tx.Where("order_id = ?", invoice.OrderID).First(&existing).Error
+		if err == nil {
+			*invoice = existing
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		year := invoice.IssuedAt.Year()
+		if err := tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]interface{}{"last_number": gorm.Expr("last_number + 1")}),
+		}).Create(&entity.InvoiceSequence{Year: year, LastNumber: 1}).Error; err != nil {
+			return err
+		}
+
+		var seq entity.InvoiceSequence
+		if err := tx.Where("year = ?", year).First(&seq).Error; err != nil {
+			return err
+		}
+
+		invoice.Number = fmt.Sprintf("INV/%d/%06d", year, seq.LastNumber)
+		invoice.FilePath = filepath.Join(dir, strings.ReplaceAll(invoice.Number, "/", "-")+".pdf")
+		return tx.Create(invoice).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+func (r *invoiceRepo) GetInvoiceByOrder(userId, orderId uint) (*entity.Invoice, error) {
+	var invoice entity.Invoice
+	if err := r.db.Where("order_id = ? AND user_id = ?", orderId, userId).First(&invoice).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrInvoiceNotFound
+		}
+		return nil, err
+	}
+
+	return &invoice, nil
+}
@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"service_cart/dto"
+	"service_cart/entity"
+	"service_cart/helper/utils"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OrderRepo interface {
+	GetCheckoutItems(userId uint) ([]entity.CartItem, error)
+	CreateOrder(order *entity.Order, cartItemIds []uint) (*dto.Order, error)
+	GetMyOrders(userId uint) ([]dto.Order, error)
+	GetOrder(userId, id uint) (*dto.Order, error)
+	UpdateOrderStatus(userId, id uint, from, to string) error
+}
+
+type orderRepo struct {
+	db    *gorm.DB
+	redis *redis.Client
+}
+
+func NewOrderRepo(db *gorm.DB, redis *redis.Client) OrderRepo {
+	return &orderRepo{db, redis}
+}
+
+func toOrderDTO(o *entity.Order) dto.Order {
+	items := make([]dto.OrderItem, 0, len(o.Items))
+	for _, item := range o.Items {
+		items = append(items, dto.OrderItem{
+			ID:          item.ID,
+			CartItemID:  item.CartItemID,
+			ProductID:   item.ProductID,
+			StoreID:     item.StoreID,
+			ProductName: item.ProductName,
+			UnitPrice:   item.UnitPrice,
+			Quantity:    item.Quantity,
+			Subtotal:    item.Subtotal,
+			Category:    item.Category,
+
+			DiscountAmount: item.DiscountAmount,
+			TaxRegion:      item.TaxRegion,
+			TaxName:        item.TaxName,
+			TaxRate:        item.TaxRate,
+			TaxMode:        item.TaxMode,
+			TaxAmount:      item.TaxAmount,
+		})
+	}
+
+	discounts := make([]dto.OrderDiscount, 0, len(o.Discounts))
+	for _, discount := range o.Discounts {
+		discounts = append(discounts, dto.OrderDiscount{
+			CouponID: discount.CouponID,
+			Code:     discount.Code,
+			StoreID:  discount.StoreID,
+			Amount:   discount.Amount,
+		})
+	}
+
+	shipments := make([]dto.Shipment, 0, len(o.Shipments))
+	for i := range o.Shipments {
+		shipments = append(shipments, toShipmentDTO(&o.Shipments[i]))
+	}
+
+	return dto.Order{
+		ID:             o.ID,
+		UserID:         o.UserID,
+		Status:         o.Status,
+		TotalAmount:    o.TotalAmount,
+		DiscountAmount: o.DiscountAmount,
+		Items:          items,
+		Discounts:      discounts,
+		ShippingAmount: o.ShippingAmount,
+		Shipments:      shipments,
+		TaxAmount:      o.TaxAmount,
+		CreatedAt:      o.CreatedAt,
+		UpdatedAt:      o.UpdatedAt,
+	}
+}
+
+func (r *orderRepo) GetCheckoutItems(userId uint) ([]entity.CartItem, error) {
+	var items []entity.CartItem
+	if err := r.db.Where("user_id = ? AND is_paid = ? AND is_product_deleted = ? AND order_id IS NULL", userId, false, false).Order("id ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *orderRepo) CreateOrder(order *entity.Order, cartItemIds []uint) (*dto.Order, error) {
+	tx := r.db.Begin()
+	if err := tx.Create(order).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// cart item yang sudah diambil checkout lain tidak ikut ter-update
+	result := tx.Model(&entity.CartItem{}).Where("id IN ? AND user_id = ? AND order_id IS NULL", cartItemIds, order.UserID).Update("order_id", order.ID)
+	if result.Error != nil {
+		tx.Rollback()
+		return nil, result.Error
+	}
+	if result.RowsAffected != int64(len(cartItemIds)) {
+		tx.Rollback()
+		return nil, utils.ErrCartChanged
+	}
+
+	if err := redeemCoupons(tx, order); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("user:%d:cart_items", order.UserID)
+	if err := r.redis.Del(ctx, key).Err(); err != nil {
+		return nil, err
+	}
+
+	response := toOrderDTO(order)
+	return &response, nil
+}
+
+func (r *orderRepo) GetMyOrders(userId uint) ([]dto.Order, error) {
+	var orders []entity.Order
+	if err := r.db.Preload("Items").Preload("Discounts").Preload("Shipments").Where("user_id = ?", userId).Order("created_at DESC").Find(&orders).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.Order, 0, len(orders))
+	for i := range orders {
+		result = append(result, toOrderDTO(&orders[i]))
+	}
+
+	return result, nil
+}
+
+// redeemCoupons mengunci baris kupon supaya kuota global dan per user
+// tetap benar walau ada checkout bersamaan.
+func redeemCoupons(tx *gorm.DB, order *entity.Order) error {
+	if len(order.Discounts) == 0 {
+		return nil
+	}
+
+	couponIds := make([]uint, 0, len(order.Discounts))
+	for _, discount := range order.Discounts {
+		couponIds = append(couponIds, discount.CouponID)
+	}
+
+	// urutan kunci tetap (id ASC) supaya tidak deadlock
+	var coupons []entity.Coupon
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", couponIds).Order("id ASC").Find(&coupons).Error; err != nil {
+		return err
+	}
+
+	for _, coupon := range coupons {
+		if coupon.UsageLimit > 0 && coupon.UsedCount >= coupon.UsageLimit {
+			return utils.ErrCouponExhausted
+		}
+
+		if coupon.PerUserLimit > 0 {
+			var used int64
+			if err := tx.Model(&entity.CouponRedemption{}).Where("coupon_id = ? AND user_id = ?", coupon.ID, order.UserID).Count(&used).Error; err != nil {
+				return err
+			}
+			if used >= int64(coupon.PerUserLimit) {
+				return utils.ErrCouponUserLimit
+			}
+		}
+
+		if err := tx.Model(&entity.Coupon{}).Where("id = ?", coupon.ID).Update("used_count", gorm.Expr("used_count + 1")).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&entity.CouponRedemption{
+			CouponID: coupon.ID,
+			UserID:   order.UserID,
+			OrderID:  order.ID,
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Where("user_id = ? AND coupon_id IN ?", order.UserID, couponIds).Delete(&entity.CartCoupon{}).Error
+}
+
+// releaseCoupons mengembalikan kuota kupon dari order yang batal
+func releaseCoupons(tx *gorm.DB, orderId uint) error {
+	var redemptions []entity.CouponRedemption
+	if err := tx.Where("order_id = ?", orderId).Find(&redemptions).Error; err != nil {
+		return err
+	}
+
+	for _, redemption := range redemptions {
+		if err := tx.Model(&entity.Coupon{}).Where("id = ? AND used_count > 0", redemption.CouponID).Update("used_count", gorm.Expr("used_count - 1")).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Where("order_id = ?", orderId).Delete(&entity.CouponRedemption{}).Error
+}
+
+func (r *orderRepo) GetOrder(userId, id uint) (*dto.Order, error) {
+	var order entity.Order
+	if err := r.db.Preload("Items").Preload("Discounts").Preload("Shipments").Where("id = ? AND user_id = ?", id, userId).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrOrderNotFound
+		}
+		return nil, err
+	}
+
+	response := toOrderDTO(&order)
+	return &response, nil
+}
+
+func (r *orderRepo) UpdateOrderStatus(userId, id uint, from, to string) error {
+	tx := r.db.Begin()
+	result := tx.Model(&entity.Order{}).Where("id = ? AND user_id = ? AND status = ?", id, userId, from).Update("status", to)
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return utils.ErrInvalidStatus
+	}
+
+	switch to {
+	case entity.OrderPaid:
+		if err := tx.Model(&entity.CartItem{}).Where("order_id = ?", id).Update("is_paid", true).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	case entity.OrderCancelled:
+		// item dikembalikan ke cart
+		if err := tx.Model(&entity.CartItem{}).Where("order_id = ?", id).Update("order_id", nil).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := releaseCoupons(tx, id); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Model(&entity.Shipment{}).Where("order_id = ?", id).Update("status", entity.ShipmentCancelled).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("user:%d:cart_items", userId)
+	return r.redis.Del(ctx, key).Err()
+}
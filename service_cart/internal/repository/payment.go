@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"errors"
+	"service_cart/entity"
+	"service_cart/helper/utils"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentRepo interface {
+	CreatePayment(payment *entity.Payment) (*entity.Payment, error)
+	GetPaymentByCorrelation(corrId string) (*entity.Payment, error)
+	GetPaymentByIntent(intentId string) (*entity.Payment, error)
+	GetPaymentByOrder(userId, orderId uint) (*entity.Payment, error)
+	UpdatePaymentStatus(id uint, from []string, to string, updates map[string]interface{}) (bool, error)
+	IsEventProcessed(eventId string) (bool, error)
+	RecordEvent(eventId string, paymentId uint, eventType string) error
+	GetRefundByReturn(returnId uint) (*entity.PaymentRefund, error)
+	ReserveRefund(refund *entity.PaymentRefund) (*entity.PaymentRefund, error)
+	CompleteRefund(id uint, refundId string) error
+	FailRefund(id uint, reason string) error
+}
+
+type paymentRepo struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepo(db *gorm.DB) PaymentRepo {
+	return &paymentRepo{db}
+}
+
+// CreatePayment idempotent per correlation_id, charge request yang dikirim
+// ulang saga mengembalikan payment yang sudah ada.
+func (r *paymentRepo) CreatePayment(payment *entity.Payment) (*entity.Payment, error) {
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(payment).Error; err != nil {
+		return nil, err
+	}
+
+	return r.GetPaymentByCorrelation(payment.CorrelationID)
+}
+
+func (r *paymentRepo) first(query string, args ...interface{}) (*entity.Payment, error) {
+	var payment entity.Payment
+	if err := r.db.Where(query, args...).Order("id DESC").First(&payment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrPaymentNotFound
+		}
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+func (r *paymentRepo) GetPaymentByCorrelation(corrId string) (*entity.Payment, error) {
+	return r.first("correlation_id = ?", corrId)
+}
+
+func (r *paymentRepo) GetPaymentByIntent(intentId string) (*entity.Payment, error) {
+	return r.first("intent_id = ?", intentId)
+}
+
+func (r *paymentRepo) GetPaymentByOrder(userId, orderId uint) (*entity.Payment, error) {
+	return r.first("order_id = ? AND user_id = ?", orderId, userId)
+}
+
+func (r *paymentRepo) UpdatePaymentStatus(id uint, from []string, to string, updates map[string]interface{}) (bool, error) {
+	values := map[string]interface{}{
+		"status": to,
+	}
+	for k, v := range updates {
+		values[k] = v
+	}
+
+	result := r.db.Model(&entity.Payment{}).Where("id = ? AND status IN ?", id, from).Updates(values)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *paymentRepo) IsEventProcessed(eventId string) (bool, error) {
+	var count int64
+	if err := r.db.Model(&entity.PaymentEvent{}).Where("id = ?", eventId).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r *paymentRepo) RecordEvent(eventId string, paymentId uint, eventType string) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&entity.PaymentEvent{
+		ID:         eventId,
+		PaymentID:  paymentId,
+		Type:       eventType,
+		ReceivedAt: time.Now(),
+	}).Error
+}
+
+func (r *paymentRepo) GetRefundByReturn(returnId uint) (*entity.PaymentRefund, error) {
+	var refund entity.PaymentRefund
+	if err := r.db.Where("return_id = ?", returnId).First(&refund).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrPaymentNotFound
+		}
+		return nil, err
+	}
+
+	return &refund, nil
+}
+
+// lockPayment mengunci payment selama transaksi refund
+func lockPayment(tx *gorm.DB, id uint) (*entity.Payment, error) {
+	var payment entity.Payment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&payment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// ReserveRefund mencatat refund retur sebagai pending dan langsung menambah
+// refunded_amount dengan payment dikunci, sebelum gateway dipanggil. Refund
+// yang sudah ada untuk retur yang sama dikembalikan apa adanya, kecuali yang
+// gagal dipesan ulang.
+func (r *paymentRepo) ReserveRefund(refund *entity.PaymentRefund) (*entity.PaymentRefund, error) {
+	var result entity.PaymentRefund
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		payment, err := lockPayment(tx, refund.PaymentID)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("return_id = ?", refund.ReturnID).First(&result).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		exists := err == nil
+		if exists && result.Status != entity.RefundFailed {
+			return nil
+		}
+
+		if payment.Status != entity.PaymentSucceeded {
+			return utils.ErrPaymentStatus
+		}
+		if payment.RefundedAmount+refund.Amount > payment.Amount {
+			return utils.ErrRefundExceeded
+		}
+
+		if exists {
+			if err := tx.Model(&result).Updates(map[string]interface{}{
+				"status":         entity.RefundPending,
+				"amount":         refund.Amount,
+				"failure_reason": "",
+			}).Error; err != nil {
+				return err
+			}
+		} else {
+			result = *refund
+			result.Status = entity.RefundPending
+			if err := tx.Create(&result).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&entity.Payment{}).Where("id = ?", payment.ID).
+			Update("refunded_amount", payment.RefundedAmount+refund.Amount).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// CompleteRefund menandai refund pending berhasil, payment menjadi refunded
+// setelah seluruh nilainya dikembalikan dan tidak ada refund yang masih pending
+func (r *paymentRepo) CompleteRefund(id uint, refundId string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var refund entity.PaymentRefund
+		if err := tx.Where("id = ?", id).First(&refund).Error; err != nil {
+			return err
+		}
+		payment, err := lockPayment(tx, refund.PaymentID)
+		if err != nil {
+			return err
+		}
+
+		result := tx.Model(&entity.PaymentRefund{}).Where("id = ? AND status = ?", id, entity.RefundPending).
+			Updates(map[string]interface{}{"status": entity.RefundSucceeded, "refund_id": refundId})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		if payment.RefundedAmount != payment.Amount || payment.Status != entity.PaymentSucceeded {
+			return nil
+		}
+		// refund lain yang masih pending bisa gagal dan melepas nilainya
+		var pending int64
+		if err := tx.Model(&entity.PaymentRefund{}).Where("payment_id = ? AND status = ?", payment.ID, entity.RefundPending).Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending > 0 {
+			return nil
+		}
+		return tx.Model(&entity.Payment{}).Where("id = ?", payment.ID).Update("status", entity.PaymentRefunded).Error
+	})
+}
+
+// FailRefund melepas nilai yang sudah dipesan refund pending
+func (r *paymentRepo) FailRefund(id uint, reason string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var refund entity.PaymentRefund
+		if err := tx.Where("id = ?", id).First(&refund).Error; err != nil {
+			return err
+		}
+		payment, err := lockPayment(tx, refund.PaymentID)
+		if err != nil {
+			return err
+		}
+
+		result := tx.Model(&entity.PaymentRefund{}).Where("id = ? AND status = ?", id, entity.RefundPending).
+			Updates(map[string]interface{}{"status": entity.RefundFailed, "failure_reason": reason})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		return tx.Model(&entity.Payment{}).Where("id = ?", payment.ID).
+			Update("refunded_amount", payment.RefundedAmount-refund.Amount).Error
+	})
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"errors"
+	"service_cart/entity"
+	"service_cart/helper/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReturnRepo interface {
+	CreateReturn(ret *entity.ReturnRequest, orderedQty int) (*entity.ReturnRequest, error)
+	GetReturn(id uint) (*entity.ReturnRequest, error)
+	GetMyReturns(userId uint) ([]entity.ReturnRequest, error)
+	GetStoreReturns(storeId uint, status string) ([]entity.ReturnRequest, error)
+	UpdateReturnStatus(id uint, from []string, to string, updates map[string]interface{}) (bool, error)
+}
+
+type returnRepo struct {
+	db *gorm.DB
+}
+
+func NewReturnRepo(db *gorm.DB) ReturnRepo {
+	return &returnRepo{db}
+}
+
+// CreateReturn mengunci baris order supaya dua retur bersamaan tidak melebihi qty yang dibeli.
+// Retur yang ditolak tidak dihitung, jadi pembeli bisa mengajukan ulang.
+func (r *returnRepo) CreateReturn(ret *entity.ReturnRequest, orderedQty int) (*entity.ReturnRequest, error) {
+	tx := r.db.Begin()
+
+	var item entity.OrderItem
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND order_id = ?", ret.OrderItemID, ret.OrderID).First(&item).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrOrderNotFound
+		}
+		return nil, err
+	}
+
+	var returned int64
+	if err := tx.Model(&entity.ReturnRequest{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("order_item_id = ? AND status <> ?", ret.OrderItemID, entity.ReturnRejected).
+		Scan(&returned).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if int(returned)+ret.Quantity > orderedQty {
+		tx.Rollback()
+		return nil, utils.ErrReturnQuantity
+	}
+
+	if err := tx.Create(ret).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *returnRepo) GetReturn(id uint) (*entity.ReturnRequest, error) {
+	var ret entity.ReturnRequest
+	if err := r.db.Preload("Photos").Where("id = ?", id).First(&ret).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrReturnNotFound
+		}
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+func (r *returnRepo) findReturns(query string, args ...interface{}) ([]entity.ReturnRequest, error) {
+	var returns []entity.ReturnRequest
+	if err := r.db.Preload("Photos").Where(query, args...).Order("id DESC").Find(&returns).Error; err != nil {
+		return nil, err
+	}
+
+	return returns, nil
+}
+
+func (r *returnRepo) GetMyReturns(userId uint) ([]entity.ReturnRequest, error) {
+	return r.findReturns("user_id = ?", userId)
+}
+
+func (r *returnRepo) GetStoreReturns(storeId uint, status string) ([]entity.ReturnRequest, error) {
+	if status != "" {
+		return r.findReturns("store_id = ? AND status = ?", storeId, status)
+	}
+	return r.findReturns("store_id = ?", storeId)
+}
+
+func (r *returnRepo) UpdateReturnStatus(id uint, from []string, to string, updates map[string]interface{}) (bool, error) {
+	values := map[string]interface{}{"status": to}
+	for k, v := range updates {
+		values[k] = v
+	}
+
+	result := r.db.Model(&entity.ReturnRequest{}).Where("id = ? AND status IN ?", id, from).Updates(values)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
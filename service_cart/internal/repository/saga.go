@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"errors"
+	"service_cart/entity"
+	"service_cart/helper/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SagaRepo interface {
+	CreateSaga(saga *entity.Saga) error
+	GetSaga(id string) (*entity.Saga, error)
+	GetSagaByOrder(orderId uint) (*entity.Saga, error)
+	AdvanceSaga(id, fromStep, toStep string, deadline time.Time, updates map[string]interface{}) (bool, error)
+	StartCompensation(id, reason string) (bool, error)
+	FinishSaga(id, status string) error
+	GetActiveSagas() ([]entity.Saga, error)
+	GetExpiredSagas(now, stalledBefore time.Time) ([]entity.Saga, error)
+}
+
+type sagaRepo struct {
+	db *gorm.DB
+}
+
+func NewSagaRepo(db *gorm.DB) SagaRepo {
+	return &sagaRepo{db}
+}
+
+func (r *sagaRepo) CreateSaga(saga *entity.Saga) error {
+	return r.db.Create(saga).Error
+}
+
+func (r *sagaRepo) GetSaga(id string) (*entity.Saga, error) {
+	var saga entity.Saga
+	if err := r.db.Where("id = ?", id).First(&saga).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrSagaNotFound
+		}
+		return nil, err
+	}
+
+	return &saga, nil
+}
+
+func (r *sagaRepo) GetSagaByOrder(orderId uint) (*entity.Saga, error) {
+	var saga entity.Saga
+	if err := r.db.Where("order_id = ?", orderId).First(&saga).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrSagaNotFound
+		}
+		return nil, err
+	}
+
+	return &saga, nil
+}
+
+// AdvanceSaga hanya berhasil kalau saga masih running di fromStep,
+// jadi reply ganda atau reply yang kalah dengan timeout diabaikan.
+func (r *sagaRepo) AdvanceSaga(id, fromStep, toStep string, deadline time.Time, updates map[string]interface{}) (bool, error) {
+	values := map[string]interface{}{
+		"step":     toStep,
+		"deadline": deadline,
+	}
+	for k, v := range updates {
+		values[k] = v
+	}
+
+	result := r.db.Model(&entity.Saga{}).Where("id = ? AND step = ? AND status = ?", id, fromStep, entity.SagaRunning).Updates(values)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *sagaRepo) StartCompensation(id, reason string) (bool, error) {
+	result := r.db.Model(&entity.Saga{}).Where("id = ? AND status = ?", id, entity.SagaRunning).Updates(map[string]interface{}{
+		"status":         entity.SagaCompensating,
+		"failure_reason": reason,
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *sagaRepo) FinishSaga(id, status string) error {
+	return r.db.Model(&entity.Saga{}).Where("id = ? AND status IN ?", id, []string{entity.SagaRunning, entity.SagaCompensating}).Update("status", status).Error
+}
+
+func (r *sagaRepo) GetActiveSagas() ([]entity.Saga, error) {
+	var sagas []entity.Saga
+	if err := r.db.Where("status IN ?", []string{entity.SagaRunning, entity.SagaCompensating}).Find(&sagas).Error; err != nil {
+		return nil, err
+	}
+
+	return sagas, nil
+}
+
+// GetExpiredSagas mengambil saga running yang melewati deadline di step mana pun,
+// juga saga compensating yang tidak berubah sejak stalledBefore karena kompensasinya
+// gagal di tengah jalan
+func (r *sagaRepo) GetExpiredSagas(now, stalledBefore time.Time) ([]entity.Saga, error) {
+	var sagas []entity.Saga
+	if err := r.db.
+		Where("status = ? AND step IN ? AND deadline < ?", entity.SagaRunning, []string{entity.SagaStepReserveStock, entity.SagaStepCharge, entity.SagaStepConfirm, entity.SagaStepNotify}, now).
+		Or("status = ? AND updated_at < ?", entity.SagaCompensating, stalledBefore).
+		Find(&sagas).Error; err != nil {
+		return nil, err
+	}
+
+	return sagas, nil
+}
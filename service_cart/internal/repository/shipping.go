@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"errors"
+	"service_cart/dto"
+	"service_cart/entity"
+	"service_cart/helper/shipping"
+	"service_cart/helper/utils"
+
+	"gorm.io/gorm"
+)
+
+type ShippingRepo interface {
+	CreateRate(req *dto.ShippingRateReq) (*dto.ShippingRate, error)
+	GetRates(storeId uint) ([]dto.ShippingRate, error)
+	DeleteRate(storeId, id uint) error
+
+	//shipping.RuleSource
+	GetShippingRules(storeId uint, method string) ([]shipping.Rule, error)
+	GetShippingMethods(storeId uint) ([]string, error)
+
+	//shipment
+	GetShipment(id uint) (*entity.Shipment, error)
+	GetStoreShipment(orderId, storeId uint) (*entity.Shipment, error)
+	GetOrderShipments(userId, orderId uint) ([]dto.Shipment, error)
+	UpdateShipmentStatus(id uint, from, to string, updates map[string]interface{}) (bool, error)
+	IsOrderDelivered(orderId uint) (bool, error)
+}
+
+type shippingRepo struct {
+	db *gorm.DB
+}
+
+func NewShippingRepo(db *gorm.DB) ShippingRepo {
+	return &shippingRepo{db}
+}
+
+func toShippingRateDTO(r *entity.ShippingRate) dto.ShippingRate {
+	return dto.ShippingRate{
+		ID:         r.ID,
+		StoreID:    r.StoreID,
+		Method:     r.Method,
+		Zone:       r.Zone,
+		MinWeight:  r.MinWeight,
+		MaxWeight:  r.MaxWeight,
+		BasePrice:  r.BasePrice,
+		PerKgPrice: r.PerKgPrice,
+	}
+}
+
+func toShipmentDTO(s *entity.Shipment) dto.Shipment {
+	return dto.Shipment{
+		ID:             s.ID,
+		OrderID:        s.OrderID,
+		StoreID:        s.StoreID,
+		Method:         s.Method,
+		Weight:         s.Weight,
+		Cost:           s.Cost,
+		Status:         s.Status,
+		TrackingNumber: s.TrackingNumber,
+		Recipient:      s.Recipient,
+		Phone:          s.Phone,
+		Street:         s.Street,
+		City:           s.City,
+		Province:       s.Province,
+		PostalCode:     s.PostalCode,
+		PackedAt:       s.PackedAt,
+		ShippedAt:      s.ShippedAt,
+		DeliveredAt:    s.DeliveredAt,
+		UpdatedAt:      s.UpdatedAt,
+	}
+}
+
+func (r *shippingRepo) CreateRate(req *dto.ShippingRateReq) (*dto.ShippingRate, error) {
+	rate := entity.ShippingRate{
+		StoreID:    req.StoreID,
+		Method:     req.Method,
+		Zone:       shipping.NormalizeZone(req.Zone),
+		MinWeight:  req.MinWeight,
+		MaxWeight:  req.MaxWeight,
+		BasePrice:  req.BasePrice,
+		PerKgPrice: req.PerKgPrice,
+	}
+	if err := r.db.Create(&rate).Error; err != nil {
+		return nil, err
+	}
+
+	response := toShippingRateDTO(&rate)
+	return &response, nil
+}
+
+func (r *shippingRepo) GetRates(storeId uint) ([]dto.ShippingRate, error) {
+	var rates []entity.ShippingRate
+	if err := r.db.Where("store_id = ?", storeId).Order("method, zone, min_weight").Find(&rates).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.ShippingRate, 0, len(rates))
+	for i := range rates {
+		result = append(result, toShippingRateDTO(&rates[i]))
+	}
+
+	return result, nil
+}
+
+func (r *shippingRepo) DeleteRate(storeId, id uint) error {
+	result := r.db.Where("id = ? AND store_id = ?", id, storeId).Delete(&entity.ShippingRate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return utils.ErrShippingRateNotFound
+	}
+
+	return nil
+}
+
+func (r *shippingRepo) GetShippingRules(storeId uint, method string) ([]shipping.Rule, error) {
+	var rates []entity.ShippingRate
+	if err := r.db.Where("store_id = ? AND method = ?", storeId, method).Order("min_weight").Find(&rates).Error; err != nil {
+		return nil, err
+	}
+
+	rules := make([]shipping.Rule, 0, len(rates))
+	for _, rate := range rates {
+		rules = append(rules, shipping.Rule{
+			Zone:       rate.Zone,
+			MinWeight:  rate.MinWeight,
+			MaxWeight:  rate.MaxWeight,
+			BasePrice:  rate.BasePrice,
+			PerKgPrice: rate.PerKgPrice,
+		})
+	}
+
+	return rules, nil
+}
+
+func (r *shippingRepo) GetShippingMethods(storeId uint) ([]string, error) {
+	var methods []string
+	if err := r.db.Model(&entity.ShippingRate{}).Where("store_id = ?", storeId).Distinct().Order("method").Pluck("method", &methods).Error; err != nil {
+		return nil, err
+	}
+
+	return methods, nil
+}
+
+func (r *shippingRepo) GetShipment(id uint) (*entity.Shipment, error) {
+	var shipment entity.Shipment
+	if err := r.db.Where("id = ?", id).First(&shipment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrShipmentNotFound
+		}
+		return nil, err
+	}
+
+	return &shipment, nil
+}
+
+func (r *shippingRepo) GetStoreShipment(orderId, storeId uint) (*entity.Shipment, error) {
+	var shipment entity.Shipment
+	if err := r.db.Where("order_id = ? AND store_id = ?", orderId, storeId).First(&shipment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrShipmentNotFound
+		}
+		return nil, err
+	}
+
+	return &shipment, nil
+}
+
+func (r *shippingRepo) GetOrderShipments(userId, orderId uint) ([]dto.Shipment, error) {
+	var count int64
+	if err := r.db.Model(&entity.Order{}).Where("id = ? AND user_id = ?", orderId, userId).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, utils.ErrOrderNotFound
+	}
+
+	var shipments []entity.Shipment
+	if err := r.db.Where("order_id = ?", orderId).Order("store_id").Find(&shipments).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.Shipment, 0, len(shipments))
+	for i := range shipments {
+		result = append(result, toShipmentDTO(&shipments[i]))
+	}
+
+	return result, nil
+}
+
+// UpdateShipmentStatus hanya berpindah dari status yang diharapkan, pengiriman
+// baru boleh diproses kalau ordernya sudah dibayar
+func (r *shippingRepo) UpdateShipmentStatus(id uint, from, to string, updates map[string]interface{}) (bool, error) {
+	values := map[string]interface{}{"status": to}
+	for k, v := range updates {
+		values[k] = v
+	}
+
+	paidOrders := r.db.Model(&entity.Order{}).Select("id").Where("status IN ?", []string{entity.OrderPaid, entity.OrderFulfilled})
+	result := r.db.Model(&entity.Shipment{}).
+		Where("id = ? AND status = ? AND order_id IN (?)", id, from, paidOrders).
+		Updates(values)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *shippingRepo) IsOrderDelivered(orderId uint) (bool, error) {
+	var pending int64
+	if err := r.db.Model(&entity.Shipment{}).Where("order_id = ? AND status <> ?", orderId, entity.ShipmentDelivered).Count(&pending).Error; err != nil {
+		return false, err
+	}
+
+	return pending == 0, nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"service_cart/dto"
+	"service_cart/entity"
+	"service_cart/helper/tax"
+	"service_cart/helper/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TaxRepo interface {
+	SaveRate(req *dto.TaxRateReq) (*dto.TaxRate, error)
+	GetRates(region string) ([]dto.TaxRate, error)
+	DeleteRate(id uint) error
+
+	//tax.RuleSource
+	GetTaxRules(region string) ([]tax.Rule, error)
+}
+
+type taxRepo struct {
+	db *gorm.DB
+}
+
+func NewTaxRepo(db *gorm.DB) TaxRepo {
+	return &taxRepo{db}
+}
+
+func toTaxRateDTO(r *entity.TaxRate) dto.TaxRate {
+	return dto.TaxRate{
+		ID:        r.ID,
+		Region:    r.Region,
+		Category:  r.Category,
+		Name:      r.Name,
+		Rate:      r.Rate,
+		Mode:      r.Mode,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+// SaveRate satu tarif per wilayah dan kategori, menyimpan ulang berarti mengganti tarif.
+// Order lama tidak berubah karena tarifnya sudah disalin ke baris order.
+func (r *taxRepo) SaveRate(req *dto.TaxRateReq) (*dto.TaxRate, error) {
+	rate := entity.TaxRate{
+		Region:   tax.Normalize(req.Region),
+		Category: tax.Normalize(req.Category),
+		Name:     req.Name,
+		Rate:     req.Rate,
+		Mode:     req.Mode,
+	}
+	if err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "region"}, {Name: "category"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "rate", "mode", "updated_at"}),
+	}).Create(&rate).Error; err != nil {
+		return nil, err
+	}
+
+	var saved entity.TaxRate
+	if err := r.db.Where("region = ? AND category = ?", rate.Region, rate.Category).First(&saved).Error; err != nil {
+		return nil, err
+	}
+
+	response := toTaxRateDTO(&saved)
+	return &response, nil
+}
+
+func (r *taxRepo) GetRates(region string) ([]dto.TaxRate, error) {
+	query := r.db.Order("region, category")
+	if region != "" {
+		query = query.Where("region = ?", tax.Normalize(region))
+	}
+
+	var rates []entity.TaxRate
+	if err := query.Find(&rates).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.TaxRate, 0, len(rates))
+	for i := range rates {
+		result = append(result, toTaxRateDTO(&rates[i]))
+	}
+	return result, nil
+}
+
+func (r *taxRepo) DeleteRate(id uint) error {
+	result := r.db.Where("id = ?", id).Delete(&entity.TaxRate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return utils.ErrTaxRateNotFound
+	}
+
+	return nil
+}
+
+func (r *taxRepo) GetTaxRules(region string) ([]tax.Rule, error) {
+	var rates []entity.TaxRate
+	if err := r.db.Where("region = ?", region).Find(&rates).Error; err != nil {
+		return nil, err
+	}
+
+	rules := make([]tax.Rule, 0, len(rates))
+	for _, rate := range rates {
+		rules = append(rules, tax.Rule{
+			Region:   rate.Region,
+			Category: rate.Category,
+			Name:     rate.Name,
+			Rate:     rate.Rate,
+			Mode:     rate.Mode,
+		})
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"service_cart/entity"
+	"service_cart/helper/utils"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WishlistRepo interface {
+	CreateWishlist(wishlist *entity.Wishlist) error
+	GetMyWishlists(userId uint) ([]entity.Wishlist, error)
+	GetWishlist(userId, id uint) (*entity.Wishlist, error)
+	DeleteWishlist(userId, id uint) error
+
+	//item
+	AddItem(userId, wishlistId, productId uint) error
+	RemoveItem(userId, wishlistId, productId uint) error
+	MoveCartItemToWishlist(userId, cartItemId, wishlistId uint) error
+}
+
+type wishlistRepo struct {
+	db    *gorm.DB
+	redis *redis.Client
+}
+
+func NewWishlistRepo(db *gorm.DB, redis *redis.Client) WishlistRepo {
+	return &wishlistRepo{db, redis}
+}
+
+func ownedWishlist(db *gorm.DB, userId, id uint) error {
+	var wishlist entity.Wishlist
+	if err := db.Select("id").Where("id = ? AND user_id = ?", id, userId).First(&wishlist).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrWishlistNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *wishlistRepo) CreateWishlist(wishlist *entity.Wishlist) error {
+	var count int64
+	if err := r.db.Model(&entity.Wishlist{}).Where("user_id = ? AND name = ?", wishlist.UserID, wishlist.Name).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return utils.ErrWishlistExists
+	}
+
+	return r.db.Create(wishlist).Error
+}
+
+func (r *wishlistRepo) GetMyWishlists(userId uint) ([]entity.Wishlist, error) {
+	var wishlists []entity.Wishlist
+	if err := r.db.Preload("Items").Where("user_id = ?", userId).Order("id").Find(&wishlists).Error; err != nil {
+		return nil, err
+	}
+
+	return wishlists, nil
+}
+
+func (r *wishlistRepo) GetWishlist(userId, id uint) (*entity.Wishlist, error) {
+	var wishlist entity.Wishlist
+	if err := r.db.Preload("Items").Where("id = ? AND user_id = ?", id, userId).First(&wishlist).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrWishlistNotFound
+		}
+		return nil, err
+	}
+
+	return &wishlist, nil
+}
+
+func (r *wishlistRepo) DeleteWishlist(userId, id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := ownedWishlist(tx, userId, id); err != nil {
+			return err
+		}
+		if err := tx.Where("wishlist_id = ?", id).Delete(&entity.WishlistItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&entity.Wishlist{}).Error
+	})
+}
+
+// AddItem tidak error kalau product sudah ada di wishlist
+func (r *wishlistRepo) AddItem(userId, wishlistId, productId uint) error {
+	if err := ownedWishlist(r.db, userId, wishlistId); err != nil {
+		return err
+	}
+
+	item := entity.WishlistItem{WishlistID: wishlistId, ProductID: productId}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&item).Error
+}
+
+func (r *wishlistRepo) RemoveItem(userId, wishlistId, productId uint) error {
+	if err := ownedWishlist(r.db, userId, wishlistId); err != nil {
+		return err
+	}
+
+	result := r.db.Where("wishlist_id = ? AND product_id = ?", wishlistId, productId).Delete(&entity.WishlistItem{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return utils.ErrWishlistItemNotFound
+	}
+
+	return nil
+}
+
+// MoveCartItemToWishlist menghapus baris cart dan memasukkan product-nya ke wishlist dalam satu transaksi
+func (r *wishlistRepo) MoveCartItemToWishlist(userId, cartItemId, wishlistId uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := ownedWishlist(tx, userId, wishlistId); err != nil {
+			return err
+		}
+
+		var item entity.CartItem
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ? AND "+openCartItem, cartItemId, userId, false, false).
+			First(&item).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.ErrCartItemNotFound
+			}
+			return err
+		}
+		if item.ProductID == nil {
+			return utils.ErrCartItemNotFound
+		}
+
+		if err := tx.Where("id = ?", item.ID).Delete(&entity.CartItem{}).Error; err != nil {
+			return err
+		}
+
+		wishlistItem := entity.WishlistItem{WishlistID: wishlistId, ProductID: *item.ProductID}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&wishlistItem).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	return r.redis.Del(ctx, fmt.Sprintf("user:%d:cart_items", userId)).Err()
+}
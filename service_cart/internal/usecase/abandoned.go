@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"service_cart/dto"
+	"service_cart/helper/utils"
+	"service_cart/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/sony/gobreaker"
+)
+
+type AbandonedCartUsecase interface {
+	NotifyAbandonedCarts() error
+	ExpireStaleCarts() error
+
+	WriteKafkaMessage(topic string, key string, payload interface{}) error
+}
+
+type abandonedCartUsecase struct {
+	abandonedRepo repository.AbandonedCartRepo
+	cartRepo      repository.CartRepo
+	idleAfter     time.Duration
+	expireAfter   time.Duration
+	kafka         map[string]*kafka.Writer
+	writeBreaker  *gobreaker.CircuitBreaker
+}
+
+// jumlah cart yang diproses per putaran scheduler
+const abandonedBatchSize = 100
+
+func NewAbandonedCartUsecase(abandonedRepo repository.AbandonedCartRepo, cartRepo repository.CartRepo, idleAfter, expireAfter time.Duration, kafka map[string]*kafka.Writer) AbandonedCartUsecase {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "AbandonedCartProducerBreaker",
+		MaxRequests: 5,
+		Interval:    30 * time.Second,
+		Timeout:     5 * time.Second,
+		OnStateChange: func(name string, from, to gobreaker.State) {
+			log.Printf("[Circuit Breaker: %s] status berubah dari %s ➜ %s\n", name, from.String(), to.String())
+		},
+	})
+	return &abandonedCartUsecase{abandonedRepo, cartRepo, idleAfter, expireAfter, kafka, cb}
+}
+
+func (u *abandonedCartUsecase) WriteKafkaMessage(topic string, key string, payload interface{}) error {
+	writer, ok := u.kafka[topic]
+	if !ok {
+		return utils.ErrNoTopic
+	}
+
+	value, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	msg := kafka.Message{
+		Key:   []byte(key),
+		Value: value,
+	}
+
+	_, err = u.writeBreaker.Execute(func() (interface{}, error) {
+		return nil, writer.WriteMessages(context.Background(), msg)
+	})
+
+	if err != nil {
+		return fmt.Errorf("kafka write failed or circuit open: %w", err)
+	}
+
+	return nil
+}
+
+func (u *abandonedCartUsecase) NotifyAbandonedCarts() error {
+	activities, err := u.abandonedRepo.GetAbandonedCarts(time.Now().Add(-u.idleAfter), abandonedBatchSize)
+	if err != nil {
+		return err
+	}
+	if len(activities) == 0 {
+		return nil
+	}
+
+	userIds := make([]uint, 0, len(activities))
+	for _, activity := range activities {
+		userIds = append(userIds, activity.UserID)
+	}
+
+	items, err := u.abandonedRepo.GetOpenCartItems(userIds)
+	if err != nil {
+		return err
+	}
+
+	// nama product diambil sekali untuk semua cart, kalau gagal email tetap dikirim tanpa nama
+	productIds := make([]uint, 0, len(items))
+	seen := make(map[uint]bool)
+	for _, item := range items {
+		if item.ProductID != nil && !seen[*item.ProductID] {
+			seen[*item.ProductID] = true
+			productIds = append(productIds, *item.ProductID)
+		}
+	}
+	products, err := fetchProductDetails(u.WriteKafkaMessage, u.cartRepo, productIds)
+	if err != nil {
+		log.Printf("abandoned cart: ambil detail product gagal: %v", err)
+		products = map[uint]dto.ProductDetailKafka{}
+	}
+
+	carts := make(map[uint]*dto.AbandonedCart, len(activities))
+	for _, activity := range activities {
+		carts[activity.UserID] = &dto.AbandonedCart{
+			UserID:         activity.UserID,
+			LastActivityAt: activity.LastActivityAt,
+		}
+	}
+	for _, item := range items {
+		if item.ProductID == nil {
+			continue
+		}
+		line := dto.AbandonedCartItem{
+			ProductID:      *item.ProductID,
+			ProductName:    products[*item.ProductID].Name,
+			PurchaseAmount: item.PurchaseAmount,
+			CurrentPrice:   item.CurrentPrice,
+		}
+		cart := carts[item.UserID]
+		cart.Items = append(cart.Items, line)
+		cart.Total += line.CurrentPrice * int64(line.PurchaseAmount)
+	}
+
+	now := time.Now()
+	for _, activity := range activities {
+		cart := carts[activity.UserID]
+		if len(cart.Items) == 0 || activity.Email == "" {
+			// tetap ditandai, kalau tidak baris ini terpilih lagi setiap putaran dan
+			// menutup batch untuk cart lain yang bisa diingatkan
+			if err := u.abandonedRepo.MarkReminded(activity.UserID, now); err != nil {
+				return err
+			}
+			continue
+		}
+
+		corrId := uuid.NewString()
+		message, _ := json.Marshal(cart)
+		payload := map[string]interface{}{
+			"correlation_id": corrId,
+			"email":          activity.Email,
+			"service":        "cart",
+			"action":         "abandoned",
+			"message":        string(message),
+		}
+		if err := u.WriteKafkaMessage("notification-request", corrId, payload); err != nil {
+			return utils.ErrFailedKafkaWrite
+		}
+
+		if err := u.abandonedRepo.MarkReminded(activity.UserID, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (u *abandonedCartUsecase) ExpireStaleCarts() error {
+	expired, err := u.abandonedRepo.ExpireStaleCarts(time.Now().Add(-u.expireAfter))
+	if err != nil {
+		return err
+	}
+	if expired > 0 {
+		log.Printf("abandoned cart: %d item cart kedaluwarsa dihapus", expired)
+	}
+	return nil
+}
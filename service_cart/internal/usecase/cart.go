@@ -8,6 +8,7 @@ import (
 	"service_cart/dto"
 	"service_cart/helper/utils"
 	"service_cart/internal/repository"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,11 +18,12 @@ import (
 
 type CartUsecase interface {
 	GetMyCartItems(userId uint) ([]dto.CartItem, error)
+	GetMyCart(userId uint) (*dto.Cart, error)
 	CreateCartItem(req *dto.CreateCartItemReq) error
 	UpdateAmountCartItem(req *dto.UpdateAmountCartItemReq) error
-	UpdatePaidCartItem(req *dto.UpdatePaidCartItemReq) error
 	DeleteCartItem(userId, id uint) error
 	UpdateIsDeleteProduct(id uint) error
+	UpdateCurrentPrice(productId uint, price int64) error
 
 	//kafka
 	WriteKafkaMessage(topic string, key string, payload interface{}) error
@@ -77,40 +79,119 @@ func (u *cartUsecase) GetMyCartItems(userId uint) ([]dto.CartItem, error) {
 	return u.cartRepo.GetMyCartItems(userId)
 }
 
-func (u *cartUsecase) CreateCartItem(req *dto.CreateCartItemReq) error {
-	corrId := uuid.NewString()
+// fetchProductDetails mengambil detail banyak product dalam satu request kafka
+func fetchProductDetails(write func(topic string, key string, payload interface{}) error, cartRepo repository.CartRepo, productIds []uint) (map[uint]dto.ProductDetailKafka, error) {
+	products := make(map[uint]dto.ProductDetailKafka, len(productIds))
+	if len(productIds) == 0 {
+		return products, nil
+	}
 
+	corrId := uuid.NewString()
 	payload := map[string]interface{}{
 		"correlation_id": corrId,
-		"product_id":     req.ProductID,
+		"product_ids":    productIds,
 	}
-	if err := u.WriteKafkaMessage("validation-product-request", corrId, payload); err != nil {
-		return utils.ErrFailedKafkaWrite
+	if err := write("products-detail-request", corrId, payload); err != nil {
+		return nil, utils.ErrFailedKafkaWrite
 	}
 
-	var validation dto.ValidationProductKafka
-	if err := u.cartRepo.WaitForResponse(corrId, &validation); err != nil {
-		return err
+	var details []dto.ProductDetailKafka
+	if err := cartRepo.WaitForResponse(corrId, &details); err != nil {
+		return nil, err
 	}
 
-	if !validation.Deleted {
-		return utils.ErrProductDeleted
+	for _, d := range details {
+		products[d.ProductID] = d
 	}
+	return products, nil
+}
 
-	if validation.Stock < req.PurchaseAmount {
-		return utils.ErrStocknotEnough
+func cartProductIds(items []dto.CartItem) []uint {
+	productIds := make([]uint, 0, len(items))
+	seen := make(map[uint]bool)
+	for _, item := range items {
+		if item.ProductID == 0 || seen[item.ProductID] {
+			continue
+		}
+		seen[item.ProductID] = true
+		productIds = append(productIds, item.ProductID)
 	}
-	return u.cartRepo.CreateCartItem(req)
+	return productIds
 }
 
-func (u *cartUsecase) UpdateAmountCartItem(req *dto.UpdateAmountCartItemReq) error {
+// buildCart mengelompokkan item per toko dan menandai product yang dihapus atau stoknya kurang
+func buildCart(items []dto.CartItem, products map[uint]dto.ProductDetailKafka) *dto.Cart {
+	cart := &dto.Cart{
+		Stores:      []dto.CartStore{},
+		Unavailable: []dto.CartLine{},
+	}
+
+	stores := make(map[uint]*dto.CartStore)
+	var storeIds []uint
+	for _, item := range items {
+		line := dto.CartLine{
+			CartItemID:     item.ID,
+			ProductID:      item.ProductID,
+			PurchaseAmount: item.PurchaseAmount,
+			UnitPrice:      item.UnitPrice,
+			CurrentPrice:   item.CurrentPrice,
+		}
+
+		product, ok := products[item.ProductID]
+		if !ok || product.Deleted || item.IsProductDeleted {
+			line.Deleted = true
+			cart.Unavailable = append(cart.Unavailable, line)
+			continue
+		}
+
+		line.ProductName = product.Name
+		line.CurrentPrice = product.Price
+		line.PriceChanged = product.Price != item.UnitPrice
+		line.Stock = product.Stock
+		line.UnderStocked = product.Stock < item.PurchaseAmount
+		line.LineSubtotal = product.Price * int64(item.PurchaseAmount)
+
+		store, ok := stores[product.StoreID]
+		if !ok {
+			store = &dto.CartStore{StoreID: product.StoreID}
+			stores[product.StoreID] = store
+			storeIds = append(storeIds, product.StoreID)
+		}
+		store.Items = append(store.Items, line)
+		store.Subtotal += line.LineSubtotal
+		cart.Total += line.LineSubtotal
+	}
+
+	sort.Slice(storeIds, func(i, j int) bool { return storeIds[i] < storeIds[j] })
+	for _, id := range storeIds {
+		cart.Stores = append(cart.Stores, *stores[id])
+	}
+
+	return cart
+}
+
+func (u *cartUsecase) GetMyCart(userId uint) (*dto.Cart, error) {
+	items, err := u.cartRepo.GetMyCartItems(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := fetchProductDetails(u.WriteKafkaMessage, u.cartRepo, cartProductIds(items))
+	if err != nil {
+		return nil, err
+	}
+
+	return buildCart(items, products), nil
+}
+
+func (u *cartUsecase) CreateCartItem(req *dto.CreateCartItemReq) error {
 	corrId := uuid.NewString()
 
 	payload := map[string]interface{}{
 		"correlation_id": corrId,
 		"product_id":     req.ProductID,
 	}
-	if err := u.WriteKafkaMessage("validation-product-request", corrId, payload); err != nil {
+	if err := u.WriteKafkaMessage("product-validation-request", corrId, payload); err != nil {
 		return utils.ErrFailedKafkaWrite
 	}
 
@@ -119,24 +200,36 @@ func (u *cartUsecase) UpdateAmountCartItem(req *dto.UpdateAmountCartItemReq) err
 		return err
 	}
 
-	if !validation.Deleted {
+	if validation.Deleted {
 		return utils.ErrProductDeleted
 	}
 
-	if validation.Stock < req.PurchaseAmount {
+	// quantity yang sudah ada di cart ikut dihitung karena barisnya digabung
+	total := req.PurchaseAmount
+	existing, err := u.cartRepo.GetOpenCartItemByProduct(req.UserID, req.ProductID)
+	if err != nil && err != utils.ErrCartItemNotFound {
+		return err
+	}
+	if existing != nil {
+		total += existing.PurchaseAmount
+	}
+
+	if validation.Stock < total {
 		return utils.ErrStocknotEnough
 	}
-	return u.cartRepo.UpdateAmountCartItem(req)
+
+	req.UnitPrice = validation.Price
+	return u.cartRepo.CreateCartItem(req)
 }
 
-func (u *cartUsecase) UpdatePaidCartItem(req *dto.UpdatePaidCartItemReq) error {
+func (u *cartUsecase) UpdateAmountCartItem(req *dto.UpdateAmountCartItemReq) error {
 	corrId := uuid.NewString()
 
 	payload := map[string]interface{}{
 		"correlation_id": corrId,
 		"product_id":     req.ProductID,
 	}
-	if err := u.WriteKafkaMessage("validation-product-request", corrId, payload); err != nil {
+	if err := u.WriteKafkaMessage("product-validation-request", corrId, payload); err != nil {
 		return utils.ErrFailedKafkaWrite
 	}
 
@@ -145,27 +238,14 @@ func (u *cartUsecase) UpdatePaidCartItem(req *dto.UpdatePaidCartItemReq) error {
 		return err
 	}
 
-	if !validation.Deleted {
+	if validation.Deleted {
 		return utils.ErrProductDeleted
 	}
 
 	if validation.Stock < req.PurchaseAmount {
 		return utils.ErrStocknotEnough
 	}
-
-	message, _ := json.Marshal(&req)
-	payloadtwo := map[string]interface{}{
-		"correlation_id": corrId,
-		"email":          req.Email,
-		"service":        "cart",
-		"action":         "paid",
-		"message":        message,
-	}
-
-	if err := u.WriteKafkaMessage("notification-request", corrId, payloadtwo); err != nil {
-		return utils.ErrFailedKafkaWrite
-	}
-	return u.cartRepo.UpdatePaidCartItem(req)
+	return u.cartRepo.UpdateAmountCartItem(req)
 }
 
 func (u *cartUsecase) DeleteCartItem(userId, id uint) error {
@@ -175,3 +255,7 @@ func (u *cartUsecase) DeleteCartItem(userId, id uint) error {
 func (u *cartUsecase) UpdateIsDeleteProduct(id uint) error {
 	return u.cartRepo.UpdateIsDeleteProduct(id)
 }
+
+func (u *cartUsecase) UpdateCurrentPrice(productId uint, price int64) error {
+	return u.cartRepo.UpdateCurrentPrice(productId, price)
+}
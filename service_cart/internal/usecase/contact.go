@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"service_cart/dto"
+	"service_cart/helper/utils"
+	"service_cart/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// fetchUserContacts mengambil username dan email user dari service user
+func fetchUserContacts(write func(topic string, key string, payload interface{}) error, cartRepo repository.CartRepo, userIds []uint) (map[uint]dto.UserContactKafka, error) {
+	contacts := make(map[uint]dto.UserContactKafka, len(userIds))
+	if len(userIds) == 0 {
+		return contacts, nil
+	}
+
+	corrId := uuid.NewString()
+	if err := write("user-contact-request", corrId, map[string]interface{}{
+		"correlation_id": corrId,
+		"user_ids":       userIds,
+	}); err != nil {
+		return nil, utils.ErrFailedKafkaWrite
+	}
+
+	var found []dto.UserContactKafka
+	if err := cartRepo.WaitForResponse(corrId, &found); err != nil {
+		return nil, err
+	}
+	for _, contact := range found {
+		contacts[contact.ID] = contact
+	}
+
+	return contacts, nil
+}
+
+// storeAdminEmails mencari admin tiap toko di service store lalu emailnya di service user.
+// Toko yang admin atau emailnya tidak ditemukan tidak ada di hasil.
+func storeAdminEmails(write func(topic string, key string, payload interface{}) error, cartRepo repository.CartRepo, storeIds []uint) (map[uint]string, error) {
+	emails := make(map[uint]string, len(storeIds))
+	if len(storeIds) == 0 {
+		return emails, nil
+	}
+
+	corrId := uuid.NewString()
+	if err := write("stores-detail-request", corrId, map[string]interface{}{
+		"correlation_id": corrId,
+		"store_ids":      storeIds,
+	}); err != nil {
+		return nil, utils.ErrFailedKafkaWrite
+	}
+
+	var stores []dto.StoreDetailKafka
+	if err := cartRepo.WaitForResponse(corrId, &stores); err != nil {
+		return nil, err
+	}
+
+	adminIds := make([]uint, 0, len(stores))
+	for _, store := range stores {
+		adminIds = append(adminIds, store.AdminID)
+	}
+	contacts, err := fetchUserContacts(write, cartRepo, adminIds)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, store := range stores {
+		if contact, ok := contacts[store.AdminID]; ok && contact.Email != "" {
+			emails[store.ID] = contact.Email
+		}
+	}
+
+	return emails, nil
+}
@@ -0,0 +1,331 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"service_cart/dto"
+	"service_cart/entity"
+	"service_cart/helper/utils"
+	"service_cart/internal/repository"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/sony/gobreaker"
+)
+
+type CouponUsecase interface {
+	CreateCoupon(req *dto.CreateCouponReq) (*dto.Coupon, error)
+	ApplyToCart(userId uint, code string) (*dto.Coupon, error)
+	RemoveFromCart(userId uint, code string) error
+	GetCartCoupons(userId uint) ([]dto.Coupon, error)
+
+	//checkout
+	CalculateDiscounts(userId uint, codes []string, items []entity.OrderItem) ([]entity.OrderDiscount, error)
+
+	WriteKafkaMessage(topic string, key string, payload interface{}) error
+}
+
+type couponUsecase struct {
+	couponRepo   repository.CouponRepo
+	cartRepo     repository.CartRepo
+	kafka        map[string]*kafka.Writer
+	writeBreaker *gobreaker.CircuitBreaker
+}
+
+func NewCouponUsecase(couponRepo repository.CouponRepo, cartRepo repository.CartRepo, kafka map[string]*kafka.Writer) CouponUsecase {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "CouponProducerBreaker",
+		MaxRequests: 5,
+		Interval:    30 * time.Second,
+		Timeout:     5 * time.Second,
+		OnStateChange: func(name string, from, to gobreaker.State) {
+			log.Printf("[Circuit Breaker: %s] status berubah dari %s ➜ %s\n", name, from.String(), to.String())
+		},
+	})
+	return &couponUsecase{couponRepo, cartRepo, kafka, cb}
+}
+
+func toCouponDTO(c *entity.Coupon) dto.Coupon {
+	return dto.Coupon{
+		ID:           c.ID,
+		Code:         c.Code,
+		StoreID:      c.StoreID,
+		DiscountType: c.DiscountType,
+		Value:        c.Value,
+		MinSpend:     c.MinSpend,
+		UsageLimit:   c.UsageLimit,
+		PerUserLimit: c.PerUserLimit,
+		UsedCount:    c.UsedCount,
+		StartsAt:     c.StartsAt,
+		EndsAt:       c.EndsAt,
+	}
+}
+
+// admin platform diatur lewat env PLATFORM_ADMIN_IDS, dipisah koma
+func isPlatformAdmin(userId uint) bool {
+	for _, raw := range strings.Split(os.Getenv("PLATFORM_ADMIN_IDS"), ",") {
+		id, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err == nil && uint(id) == userId {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *couponUsecase) WriteKafkaMessage(topic string, key string, payload interface{}) error {
+	writer, ok := u.kafka[topic]
+	if !ok {
+		return utils.ErrNoTopic
+	}
+
+	value, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	msg := kafka.Message{
+		Key:   []byte(key),
+		Value: value,
+	}
+
+	_, err = u.writeBreaker.Execute(func() (interface{}, error) {
+		return nil, writer.WriteMessages(context.Background(), msg)
+	})
+
+	if err != nil {
+		return fmt.Errorf("kafka write failed or circuit open: %w", err)
+	}
+
+	return nil
+}
+
+// checkStoreAdmin menanyakan ke service store apakah user admin toko itu
+func checkStoreAdmin(write func(topic string, key string, payload interface{}) error, cartRepo repository.CartRepo, userId, storeId uint) error {
+	corrID := uuid.NewString()
+	payload := map[string]interface{}{
+		"store_id":       storeId,
+		"user_id":        userId,
+		"correlation_id": corrID,
+	}
+	if err := write("store-validation-request", corrID, payload); err != nil {
+		return utils.ErrFailedKafkaWrite
+	}
+
+	var isValid bool
+	if err := cartRepo.WaitForResponse(corrID, &isValid); err != nil {
+		return err
+	}
+	if !isValid {
+		return utils.ErrNotAdmin
+	}
+	return nil
+}
+
+func (u *couponUsecase) CreateCoupon(req *dto.CreateCouponReq) (*dto.Coupon, error) {
+	if req.StoreID != nil {
+		if err := checkStoreAdmin(u.WriteKafkaMessage, u.cartRepo, req.UserID, *req.StoreID); err != nil {
+			return nil, err
+		}
+	} else if !isPlatformAdmin(req.UserID) {
+		return nil, utils.ErrNotAdmin
+	}
+
+	coupon := entity.Coupon{
+		Code:         req.Code,
+		StoreID:      req.StoreID,
+		CreatedBy:    req.UserID,
+		DiscountType: req.DiscountType,
+		Value:        req.Value,
+		MinSpend:     req.MinSpend,
+		UsageLimit:   req.UsageLimit,
+		PerUserLimit: req.PerUserLimit,
+		StartsAt:     req.StartsAt,
+		EndsAt:       req.EndsAt,
+	}
+	if err := u.couponRepo.CreateCoupon(&coupon); err != nil {
+		return nil, err
+	}
+
+	response := toCouponDTO(&coupon)
+	return &response, nil
+}
+
+// checkUsable mengecek kupon tanpa mengunci, pengecekan final ada di transaksi checkout
+func (u *couponUsecase) checkUsable(userId uint, coupon *entity.Coupon, now time.Time) error {
+	if now.Before(coupon.StartsAt) || now.After(coupon.EndsAt) {
+		return utils.ErrCouponInactive
+	}
+	if coupon.UsageLimit > 0 && coupon.UsedCount >= coupon.UsageLimit {
+		return utils.ErrCouponExhausted
+	}
+	if coupon.PerUserLimit > 0 {
+		used, err := u.couponRepo.CountUserRedemptions(coupon.ID, userId)
+		if err != nil {
+			return err
+		}
+		if used >= int64(coupon.PerUserLimit) {
+			return utils.ErrCouponUserLimit
+		}
+	}
+	return nil
+}
+
+func couponScope(coupon *entity.Coupon) uint {
+	if coupon.StoreID == nil {
+		return 0
+	}
+	return *coupon.StoreID
+}
+
+func (u *couponUsecase) ApplyToCart(userId uint, code string) (*dto.Coupon, error) {
+	coupon, err := u.couponRepo.GetCouponByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.checkUsable(userId, coupon, time.Now()); err != nil {
+		return nil, err
+	}
+
+	applied, err := u.couponRepo.GetCartCoupons(userId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range applied {
+		if applied[i].ID != coupon.ID && couponScope(&applied[i]) == couponScope(coupon) {
+			return nil, utils.ErrCouponScope
+		}
+	}
+
+	if err := u.couponRepo.AddCartCoupon(userId, coupon.ID); err != nil {
+		return nil, err
+	}
+
+	response := toCouponDTO(coupon)
+	return &response, nil
+}
+
+func (u *couponUsecase) RemoveFromCart(userId uint, code string) error {
+	coupon, err := u.couponRepo.GetCouponByCode(code)
+	if err != nil {
+		return err
+	}
+
+	return u.couponRepo.RemoveCartCoupon(userId, coupon.ID)
+}
+
+func (u *couponUsecase) GetCartCoupons(userId uint) ([]dto.Coupon, error) {
+	coupons, err := u.couponRepo.GetCartCoupons(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.Coupon, 0, len(coupons))
+	for i := range coupons {
+		result = append(result, toCouponDTO(&coupons[i]))
+	}
+
+	return result, nil
+}
+
+func discountFor(coupon *entity.Coupon, base int64) int64 {
+	var amount int64
+	switch coupon.DiscountType {
+	case entity.CouponPercentage:
+		amount = base * coupon.Value / 100
+	case entity.CouponFixed:
+		amount = coupon.Value
+	}
+	if amount > base {
+		amount = base
+	}
+	return amount
+}
+
+// CalculateDiscounts menggabungkan kupon di cart dengan kode dari request checkout.
+// Kupon toko dihitung dari subtotal toko itu, kupon platform dari sisa totalnya.
+func (u *couponUsecase) CalculateDiscounts(userId uint, codes []string, items []entity.OrderItem) ([]entity.OrderDiscount, error) {
+	coupons, err := u.couponRepo.GetCartCoupons(userId)
+	if err != nil {
+		return nil, err
+	}
+	if len(codes) > 0 {
+		extra, err := u.couponRepo.GetCouponsByCode(codes)
+		if err != nil {
+			return nil, err
+		}
+		coupons = append(coupons, extra...)
+	}
+	if len(coupons) == 0 {
+		return nil, nil
+	}
+
+	var total int64
+	storeSubtotal := make(map[uint]int64)
+	for _, item := range items {
+		storeSubtotal[item.StoreID] += item.Subtotal
+		total += item.Subtotal
+	}
+
+	now := time.Now()
+	seen := make(map[uint]bool)
+	scopes := make(map[uint]bool)
+	var platform *entity.Coupon
+	var discounts []entity.OrderDiscount
+	for i := range coupons {
+		coupon := &coupons[i]
+		if seen[coupon.ID] {
+			continue
+		}
+		seen[coupon.ID] = true
+
+		if scopes[couponScope(coupon)] {
+			return nil, utils.ErrCouponScope
+		}
+		scopes[couponScope(coupon)] = true
+
+		if err := u.checkUsable(userId, coupon, now); err != nil {
+			return nil, err
+		}
+
+		if coupon.StoreID == nil {
+			platform = coupon
+			continue
+		}
+
+		base, ok := storeSubtotal[*coupon.StoreID]
+		if !ok {
+			return nil, utils.ErrCouponNotApply
+		}
+		if base < coupon.MinSpend {
+			return nil, utils.ErrCouponMinSpend
+		}
+
+		amount := discountFor(coupon, base)
+		total -= amount
+		discounts = append(discounts, entity.OrderDiscount{
+			CouponID: coupon.ID,
+			Code:     coupon.Code,
+			StoreID:  coupon.StoreID,
+			Amount:   amount,
+		})
+	}
+
+	if platform != nil {
+		if total < platform.MinSpend {
+			return nil, utils.ErrCouponMinSpend
+		}
+		discounts = append(discounts, entity.OrderDiscount{
+			CouponID: platform.ID,
+			Code:     platform.Code,
+			Amount:   discountFor(platform, total),
+		})
+	}
+
+	return discounts, nil
+}
@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"service_cart/dto"
+	"service_cart/helper/utils"
+	"service_cart/internal/repository"
+	"sort"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sony/gobreaker"
+)
+
+type GuestCartUsecase interface {
+	IssueToken() (string, error)
+	GetCart(guestId string) (*dto.Cart, error)
+	AddItem(guestId string, productId uint, amount int) error
+	UpdateItem(guestId string, productId uint, amount int) error
+	RemoveItem(guestId string, productId uint) error
+	MergeIntoUser(userId uint, email string, guestToken string) (*dto.GuestMergeResult, error)
+
+	WriteKafkaMessage(topic string, key string, payload interface{}) error
+}
+
+type guestCartUsecase struct {
+	guestRepo    repository.GuestCartRepo
+	cartRepo     repository.CartRepo
+	kafka        map[string]*kafka.Writer
+	writeBreaker *gobreaker.CircuitBreaker
+}
+
+func NewGuestCartUsecase(guestRepo repository.GuestCartRepo, cartRepo repository.CartRepo, kafka map[string]*kafka.Writer) GuestCartUsecase {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "GuestCartProducerBreaker",
+		MaxRequests: 5,
+		Interval:    30 * time.Second,
+		Timeout:     5 * time.Second,
+		OnStateChange: func(name string, from, to gobreaker.State) {
+			log.Printf("[Circuit Breaker: %s] status berubah dari %s ➜ %s\n", name, from.String(), to.String())
+		},
+	})
+	return &guestCartUsecase{guestRepo, cartRepo, kafka, cb}
+}
+
+func (u *guestCartUsecase) WriteKafkaMessage(topic string, key string, payload interface{}) error {
+	writer, ok := u.kafka[topic]
+	if !ok {
+		return utils.ErrNoTopic
+	}
+
+	value, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	msg := kafka.Message{
+		Key:   []byte(key),
+		Value: value,
+	}
+
+	_, err = u.writeBreaker.Execute(func() (interface{}, error) {
+		return nil, writer.WriteMessages(context.Background(), msg)
+	})
+
+	if err != nil {
+		return fmt.Errorf("kafka write failed or circuit open: %w", err)
+	}
+
+	return nil
+}
+
+func (u *guestCartUsecase) IssueToken() (string, error) {
+	token, _, err := utils.GenerateGuestToken()
+	return token, err
+}
+
+func (u *guestCartUsecase) GetCart(guestId string) (*dto.Cart, error) {
+	amounts, err := u.guestRepo.GetItems(guestId)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.CartItem, 0, len(amounts))
+	for productId, amount := range amounts {
+		items = append(items, dto.CartItem{
+			ProductID:      productId,
+			PurchaseAmount: amount,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ProductID < items[j].ProductID })
+
+	products, err := fetchProductDetails(u.WriteKafkaMessage, u.cartRepo, cartProductIds(items))
+	if err != nil {
+		return nil, err
+	}
+
+	// cart guest tidak menyimpan harga, harga selalu harga terkini
+	for i := range items {
+		items[i].UnitPrice = products[items[i].ProductID].Price
+	}
+
+	return buildCart(items, products), nil
+}
+
+func (u *guestCartUsecase) checkStock(productId uint, total int) error {
+	products, err := fetchProductDetails(u.WriteKafkaMessage, u.cartRepo, []uint{productId})
+	if err != nil {
+		return err
+	}
+
+	product, ok := products[productId]
+	if !ok || product.Deleted {
+		return utils.ErrProductDeleted
+	}
+	if product.Stock < total {
+		return utils.ErrStocknotEnough
+	}
+	return nil
+}
+
+func (u *guestCartUsecase) AddItem(guestId string, productId uint, amount int) error {
+	current, err := u.guestRepo.GetAmount(guestId, productId)
+	if err != nil {
+		return err
+	}
+	if err := u.checkStock(productId, current+amount); err != nil {
+		return err
+	}
+
+	return u.guestRepo.AddItem(guestId, productId, amount)
+}
+
+func (u *guestCartUsecase) UpdateItem(guestId string, productId uint, amount int) error {
+	if err := u.checkStock(productId, amount); err != nil {
+		return err
+	}
+
+	return u.guestRepo.SetItem(guestId, productId, amount)
+}
+
+func (u *guestCartUsecase) RemoveItem(guestId string, productId uint) error {
+	return u.guestRepo.RemoveItem(guestId, productId)
+}
+
+// MergeIntoUser memindahkan cart guest ke cart user. Quantity dijumlahkan dengan
+// baris yang sudah ada lalu dipotong ke stok, product yang dihapus dilewati.
+func (u *guestCartUsecase) MergeIntoUser(userId uint, email string, guestToken string) (*dto.GuestMergeResult, error) {
+	guestId, err := utils.ValidateGuestToken(guestToken)
+	if err != nil {
+		return nil, err
+	}
+
+	amounts, err := u.guestRepo.GetItems(guestId)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.GuestMergeResult{
+		Merged:   []uint{},
+		Adjusted: []uint{},
+		Skipped:  []uint{},
+	}
+	if len(amounts) == 0 {
+		return result, nil
+	}
+
+	productIds := make([]uint, 0, len(amounts))
+	for productId := range amounts {
+		productIds = append(productIds, productId)
+	}
+	sort.Slice(productIds, func(i, j int) bool { return productIds[i] < productIds[j] })
+
+	products, err := fetchProductDetails(u.WriteKafkaMessage, u.cartRepo, productIds)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, productId := range productIds {
+		product, ok := products[productId]
+		if !ok || product.Deleted {
+			result.Skipped = append(result.Skipped, productId)
+			continue
+		}
+
+		existing, err := u.cartRepo.GetOpenCartItemByProduct(userId, productId)
+		if err != nil && err != utils.ErrCartItemNotFound {
+			return nil, err
+		}
+
+		current := 0
+		if existing != nil {
+			current = existing.PurchaseAmount
+		}
+
+		total := current + amounts[productId]
+		adjusted := false
+		if total > product.Stock {
+			total = product.Stock
+			adjusted = true
+		}
+		if total <= current {
+			// stok tidak cukup untuk menambah apa pun
+			result.Skipped = append(result.Skipped, productId)
+			continue
+		}
+
+		if existing != nil {
+			err = u.cartRepo.UpdateAmountCartItem(&dto.UpdateAmountCartItemReq{
+				UserID:         userId,
+				Email:          email,
+				ID:             existing.ID,
+				ProductID:      productId,
+				PurchaseAmount: total,
+			})
+		} else {
+			err = u.cartRepo.CreateCartItem(&dto.CreateCartItemReq{
+				UserID:         userId,
+				Email:          email,
+				ProductID:      productId,
+				UnitPrice:      product.Price,
+				PurchaseAmount: total,
+			})
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if adjusted {
+			result.Adjusted = append(result.Adjusted, productId)
+		} else {
+			result.Merged = append(result.Merged, productId)
+		}
+	}
+
+	// cart guest dihapus supaya event login yang terkirim ulang tidak menggandakan quantity
+	if err := u.guestRepo.Clear(guestId); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,250 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"service_cart/dto"
+	"service_cart/entity"
+	"service_cart/helper/invoice"
+	"service_cart/helper/utils"
+	"service_cart/internal/repository"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/sony/gobreaker"
+)
+
+type InvoiceUsecase interface {
+	Generate(order *dto.Order, email string) (*dto.InvoiceFile, error)
+	GetInvoiceFile(userId, orderId uint, email string) (*dto.InvoiceFile, error)
+
+	WriteKafkaMessage(topic string, key string, payload interface{}) error
+}
+
+type invoiceUsecase struct {
+	invoiceRepo  repository.InvoiceRepo
+	orderRepo    repository.OrderRepo
+	cartRepo     repository.CartRepo
+	kafka        map[string]*kafka.Writer
+	writeBreaker *gobreaker.CircuitBreaker
+}
+
+func NewInvoiceUsecase(invoiceRepo repository.InvoiceRepo, orderRepo repository.OrderRepo, cartRepo repository.CartRepo, kafka map[string]*kafka.Writer) InvoiceUsecase {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "InvoiceProducerBreaker",
+		MaxRequests: 5,
+		Interval:    30 * time.Second,
+		Timeout:     5 * time.Second,
+		OnStateChange: func(name string, from, to gobreaker.State) {
+			log.Printf("[Circuit Breaker: %s] status berubah dari %s ➜ %s\n", name, from.String(), to.String())
+		},
+	})
+	return &invoiceUsecase{invoiceRepo, orderRepo, cartRepo, kafka, cb}
+}
+
+// folder penyimpanan pdf, di docker dipasang volume supaya tidak hilang saat redeploy
+func invoiceDir() string {
+	dir := os.Getenv("INVOICE_DIR")
+	if dir == "" {
+		return "storage/invoices"
+	}
+	return dir
+}
+
+func (u *invoiceUsecase) WriteKafkaMessage(topic string, key string, payload interface{}) error {
+	writer, ok := u.kafka[topic]
+	if !ok {
+		return utils.ErrNoTopic
+	}
+
+	value, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	msg := kafka.Message{
+		Key:   []byte(key),
+		Value: value,
+	}
+
+	_, err = u.writeBreaker.Execute(func() (interface{}, error) {
+		return nil, writer.WriteMessages(context.Background(), msg)
+	})
+
+	if err != nil {
+		return fmt.Errorf("kafka write failed or circuit open: %w", err)
+	}
+
+	return nil
+}
+
+// storeNames tidak menggagalkan invoice, kalau service store tidak menjawab nama toko diganti id
+func (u *invoiceUsecase) storeNames(storeIds []uint) map[uint]string {
+	names := make(map[uint]string, len(storeIds))
+
+	corrId := uuid.NewString()
+	payload := map[string]interface{}{
+		"correlation_id": corrId,
+		"store_ids":      storeIds,
+	}
+	if err := u.WriteKafkaMessage("stores-detail-request", corrId, payload); err != nil {
+		log.Printf("invoice: gagal minta detail toko: %v", err)
+		return names
+	}
+
+	var stores []dto.StoreDetailKafka
+	if err := u.cartRepo.WaitForResponse(corrId, &stores); err != nil {
+		log.Printf("invoice: detail toko tidak didapat: %v", err)
+		return names
+	}
+
+	for _, s := range stores {
+		names[s.ID] = s.Name
+	}
+	return names
+}
+
+func (u *invoiceUsecase) buildInvoice(inv *entity.Invoice, order *dto.Order) *dto.Invoice {
+	byStore := make(map[uint]*dto.InvoiceStore)
+	var storeIds []uint
+	var taxes []dto.InvoiceTax
+	for _, item := range order.Items {
+		if item.TaxAmount > 0 {
+			taxes = addInvoiceTax(taxes, item)
+		}
+
+		store, ok := byStore[item.StoreID]
+		if !ok {
+			store = &dto.InvoiceStore{StoreID: item.StoreID}
+			byStore[item.StoreID] = store
+			storeIds = append(storeIds, item.StoreID)
+		}
+		store.Items = append(store.Items, item)
+		store.Subtotal += item.Subtotal
+	}
+	sort.Slice(storeIds, func(i, j int) bool { return storeIds[i] < storeIds[j] })
+
+	names := u.storeNames(storeIds)
+	stores := make([]dto.InvoiceStore, 0, len(storeIds))
+	for _, id := range storeIds {
+		store := byStore[id]
+		store.Name = names[id]
+		if store.Name == "" {
+			store.Name = fmt.Sprintf("Toko #%d", id)
+		}
+		stores = append(stores, *store)
+	}
+
+	return &dto.Invoice{
+		Number:         inv.Number,
+		OrderID:        inv.OrderID,
+		IssuedAt:       inv.IssuedAt,
+		BuyerID:        inv.UserID,
+		BuyerEmail:     inv.BuyerEmail,
+		Stores:         stores,
+		Discounts:      order.Discounts,
+		Subtotal:       inv.Subtotal,
+		DiscountAmount: inv.DiscountAmount,
+		ShippingAmount: inv.ShippingAmount,
+		TaxAmount:      inv.TaxAmount,
+		Taxes:          taxes,
+		TotalAmount:    inv.TotalAmount,
+	}
+}
+
+func addInvoiceTax(taxes []dto.InvoiceTax, item dto.OrderItem) []dto.InvoiceTax {
+	for i := range taxes {
+		if taxes[i].Name == item.TaxName && taxes[i].Rate == item.TaxRate && taxes[i].Mode == item.TaxMode {
+			taxes[i].Amount += item.TaxAmount
+			return taxes
+		}
+	}
+	return append(taxes, dto.InvoiceTax{
+		Name:   item.TaxName,
+		Rate:   item.TaxRate,
+		Mode:   item.TaxMode,
+		Amount: item.TaxAmount,
+	})
+}
+
+// Generate aman dipanggil ulang, nomor invoice tetap dan file pdf ditulis ulang
+func (u *invoiceUsecase) Generate(order *dto.Order, email string) (*dto.InvoiceFile, error) {
+	var subtotal int64
+	for _, item := range order.Items {
+		subtotal += item.Subtotal
+	}
+
+	inv, err := u.invoiceRepo.IssueInvoice(&entity.Invoice{
+		OrderID:        order.ID,
+		UserID:         order.UserID,
+		BuyerEmail:     email,
+		Subtotal:       subtotal,
+		DiscountAmount: order.DiscountAmount,
+		ShippingAmount: order.ShippingAmount,
+		TaxAmount:      order.TaxAmount,
+		TotalAmount:    order.TotalAmount,
+		IssuedAt:       time.Now(),
+	}, invoiceDir())
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := invoice.Render(u.buildInvoice(inv, order))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(inv.FilePath), 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(inv.FilePath, content, 0o644); err != nil {
+		return nil, err
+	}
+
+	return &dto.InvoiceFile{
+		Number:   inv.Number,
+		Filename: filepath.Base(inv.FilePath),
+		Content:  content,
+	}, nil
+}
+
+func (u *invoiceUsecase) GetInvoiceFile(userId, orderId uint, email string) (*dto.InvoiceFile, error) {
+	order, err := u.orderRepo.GetOrder(userId, orderId)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != entity.OrderPaid && order.Status != entity.OrderFulfilled {
+		return nil, utils.ErrInvoiceUnavailable
+	}
+
+	inv, err := u.invoiceRepo.GetInvoiceByOrder(userId, orderId)
+	if err == utils.ErrInvoiceNotFound {
+		return u.Generate(order, email)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(inv.FilePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		// file hilang dari disk, dibuat ulang dari data order dengan nomor yang sama
+		return u.Generate(order, inv.BuyerEmail)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.InvoiceFile{
+		Number:   inv.Number,
+		Filename: filepath.Base(inv.FilePath),
+		Content:  content,
+	}, nil
+}
@@ -0,0 +1,267 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"service_cart/dto"
+	"service_cart/entity"
+	"service_cart/helper/utils"
+	"service_cart/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/sony/gobreaker"
+)
+
+type OrderUsecase interface {
+	Checkout(userId uint, email string, req *dto.CheckoutReq) (*dto.Order, error)
+	GetMyOrders(userId uint) ([]dto.Order, error)
+	GetOrder(userId, id uint) (*dto.Order, error)
+	GetOrderSaga(userId, id uint) (*dto.Saga, error)
+	CancelOrder(userId, id uint) error
+	CompleteOrder(userId, id uint) error
+
+	//kafka
+	WriteKafkaMessage(topic string, key string, payload interface{}) error
+}
+
+type orderUsecase struct {
+	orderRepo    repository.OrderRepo
+	cartRepo     repository.CartRepo
+	coupon       CouponUsecase
+	shipping     ShippingUsecase
+	tax          TaxUsecase
+	saga         CheckoutSaga
+	kafka        map[string]*kafka.Writer
+	writeBreaker *gobreaker.CircuitBreaker
+}
+
+func NewOrderUsecase(orderRepo repository.OrderRepo, cartRepo repository.CartRepo, coupon CouponUsecase, shipping ShippingUsecase, tax TaxUsecase, saga CheckoutSaga, kafka map[string]*kafka.Writer) OrderUsecase {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "OrderProducerBreaker",
+		MaxRequests: 5,
+		Interval:    30 * time.Second,
+		Timeout:     5 * time.Second,
+		OnStateChange: func(name string, from, to gobreaker.State) {
+			log.Printf("[Circuit Breaker: %s] status berubah dari %s ➜ %s\n", name, from.String(), to.String())
+		},
+	})
+	return &orderUsecase{orderRepo, cartRepo, coupon, shipping, tax, saga, kafka, cb}
+}
+
+// status tujuan yang boleh dari tiap status order
+var orderTransitions = map[string][]string{
+	entity.OrderPending: {entity.OrderPaid, entity.OrderCancelled},
+	entity.OrderPaid:    {entity.OrderFulfilled},
+}
+
+func canTransition(from, to string) bool {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *orderUsecase) WriteKafkaMessage(topic string, key string, payload interface{}) error {
+	writer, ok := u.kafka[topic]
+	if !ok {
+		return utils.ErrNoTopic
+	}
+
+	value, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	msg := kafka.Message{
+		Key:   []byte(key),
+		Value: value,
+	}
+
+	_, err = u.writeBreaker.Execute(func() (interface{}, error) {
+		return nil, writer.WriteMessages(context.Background(), msg)
+	})
+
+	if err != nil {
+		return fmt.Errorf("kafka write failed or circuit open: %w", err)
+	}
+
+	return nil
+}
+
+func (u *orderUsecase) Checkout(userId uint, email string, req *dto.CheckoutReq) (*dto.Order, error) {
+	items, err := u.orderRepo.GetCheckoutItems(userId)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, utils.ErrEmptyCart
+	}
+
+	productIds := make([]uint, 0, len(items))
+	seen := make(map[uint]bool)
+	for _, item := range items {
+		if item.ProductID == nil || seen[*item.ProductID] {
+			continue
+		}
+		seen[*item.ProductID] = true
+		productIds = append(productIds, *item.ProductID)
+	}
+
+	corrId := uuid.NewString()
+	payload := map[string]interface{}{
+		"correlation_id": corrId,
+		"product_ids":    productIds,
+	}
+	if err := u.WriteKafkaMessage("products-detail-request", corrId, payload); err != nil {
+		return nil, utils.ErrFailedKafkaWrite
+	}
+
+	var details []dto.ProductDetailKafka
+	if err := u.cartRepo.WaitForResponse(corrId, &details); err != nil {
+		return nil, err
+	}
+
+	products := make(map[uint]dto.ProductDetailKafka, len(details))
+	for _, d := range details {
+		products[d.ProductID] = d
+	}
+
+	order := entity.Order{
+		UserID: userId,
+		Status: entity.OrderPending,
+	}
+	cartItemIds := make([]uint, 0, len(items))
+	quantity := make(map[uint]int)
+	weights := make(map[uint]int)
+	for _, item := range items {
+		if item.ProductID == nil {
+			return nil, utils.ErrProductDeleted
+		}
+		product, ok := products[*item.ProductID]
+		if !ok || product.Deleted {
+			return nil, utils.ErrProductDeleted
+		}
+
+		quantity[product.ProductID] += item.PurchaseAmount
+		weights[product.ProductID] = product.Weight
+		if product.Stock < quantity[product.ProductID] {
+			return nil, utils.ErrStocknotEnough
+		}
+
+		subtotal := product.Price * int64(item.PurchaseAmount)
+		order.Items = append(order.Items, entity.OrderItem{
+			CartItemID:  item.ID,
+			ProductID:   product.ProductID,
+			StoreID:     product.StoreID,
+			ProductName: product.Name,
+			UnitPrice:   product.Price,
+			Quantity:    item.PurchaseAmount,
+			Subtotal:    subtotal,
+			Category:    product.Category,
+		})
+		order.TotalAmount += subtotal
+		cartItemIds = append(cartItemIds, item.ID)
+	}
+
+	discounts, err := u.coupon.CalculateDiscounts(userId, req.CouponCodes, order.Items)
+	if err != nil {
+		return nil, err
+	}
+	for _, discount := range discounts {
+		order.DiscountAmount += discount.Amount
+	}
+	order.Discounts = discounts
+	order.TotalAmount -= order.DiscountAmount
+
+	// pajak dihitung per baris setelah diskon, hanya pajak exclusive yang menambah total
+	taxAmount, exclusiveTax, err := u.tax.ApplyTaxes(order.Items, discounts)
+	if err != nil {
+		return nil, err
+	}
+	order.TaxAmount = taxAmount
+	order.TotalAmount += exclusiveTax
+
+	// ongkir tidak ikut dipotong kupon
+	shipments, err := u.shipping.PlanShipments(userId, email, req.AddressID, req.ShippingMethod, order.Items, weights)
+	if err != nil {
+		return nil, err
+	}
+	for _, shipment := range shipments {
+		order.ShippingAmount += shipment.Cost
+	}
+	order.Shipments = shipments
+	order.TotalAmount += order.ShippingAmount
+
+	response, err := u.orderRepo.CreateOrder(&order, cartItemIds)
+	if err != nil {
+		return nil, err
+	}
+
+	// read model seller ikut terisi lagi dari event berikutnya kalau yang ini gagal
+	if err := publishStoreOrders(u.WriteKafkaMessage, response, "created"); err != nil {
+		log.Printf("order %d: gagal kirim event order toko: %v", response.ID, err)
+	}
+
+	// stok, pembayaran dan konfirmasi dilanjutkan async oleh saga
+	if _, err := u.saga.Start(response, email); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (u *orderUsecase) GetMyOrders(userId uint) ([]dto.Order, error) {
+	return u.orderRepo.GetMyOrders(userId)
+}
+
+func (u *orderUsecase) GetOrder(userId, id uint) (*dto.Order, error) {
+	return u.orderRepo.GetOrder(userId, id)
+}
+
+func (u *orderUsecase) GetOrderSaga(userId, id uint) (*dto.Saga, error) {
+	return u.saga.GetSagaByOrder(userId, id)
+}
+
+func (u *orderUsecase) changeStatus(userId, id uint, to string) (*dto.Order, error) {
+	order, err := u.orderRepo.GetOrder(userId, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canTransition(order.Status, to) {
+		return nil, utils.ErrInvalidStatus
+	}
+
+	if err := u.orderRepo.UpdateOrderStatus(userId, id, order.Status, to); err != nil {
+		return nil, err
+	}
+
+	order.Status = to
+	if err := publishStoreOrders(u.WriteKafkaMessage, order, to); err != nil {
+		log.Printf("order %d: gagal kirim event order toko: %v", order.ID, err)
+	}
+	return order, nil
+}
+
+func (u *orderUsecase) CancelOrder(userId, id uint) error {
+	running, err := u.saga.IsRunning(id)
+	if err != nil {
+		return err
+	}
+	if running {
+		return utils.ErrCheckoutRunning
+	}
+
+	_, err = u.changeStatus(userId, id, entity.OrderCancelled)
+	return err
+}
+
+func (u *orderUsecase) CompleteOrder(userId, id uint) error {
+	_, err := u.changeStatus(userId, id, entity.OrderFulfilled)
+	return err
+}
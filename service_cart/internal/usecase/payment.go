@@ -0,0 +1,319 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"service_cart/dto"
+	"service_cart/entity"
+	"service_cart/helper/payment"
+	"service_cart/helper/utils"
+	"service_cart/internal/repository"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sony/gobreaker"
+)
+
+type PaymentUsecase interface {
+	Pay(userId, orderId uint) (*dto.Payment, error)
+	GetPayment(userId, orderId uint) (*dto.Payment, error)
+	HandleWebhook(payload []byte, signature string) error
+
+	//kafka
+	Charge(req *dto.PaymentChargeKafka) error
+	Refund(req *dto.PaymentRefundKafka) error
+	WriteKafkaMessage(topic string, key string, payload interface{}) error
+}
+
+type paymentUsecase struct {
+	paymentRepo  repository.PaymentRepo
+	gateway      payment.PaymentGateway
+	kafka        map[string]*kafka.Writer
+	writeBreaker *gobreaker.CircuitBreaker
+}
+
+func NewPaymentUsecase(paymentRepo repository.PaymentRepo, gateway payment.PaymentGateway, kafka map[string]*kafka.Writer) PaymentUsecase {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "PaymentProducerBreaker",
+		MaxRequests: 5,
+		Interval:    30 * time.Second,
+		Timeout:     5 * time.Second,
+		OnStateChange: func(name string, from, to gobreaker.State) {
+			log.Printf("[Circuit Breaker: %s] status berubah dari %s ➜ %s\n", name, from.String(), to.String())
+		},
+	})
+	return &paymentUsecase{paymentRepo, gateway, kafka, cb}
+}
+
+func toPaymentDTO(p *entity.Payment) *dto.Payment {
+	return &dto.Payment{
+		ID:            p.ID,
+		OrderID:       p.OrderID,
+		Amount:        p.Amount,
+		Status:        p.Status,
+		IntentID:      p.IntentID,
+		FailureReason: p.FailureReason,
+		UpdatedAt:     p.UpdatedAt,
+	}
+}
+
+func (u *paymentUsecase) WriteKafkaMessage(topic string, key string, payload interface{}) error {
+	writer, ok := u.kafka[topic]
+	if !ok {
+		return utils.ErrNoTopic
+	}
+
+	value, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	msg := kafka.Message{
+		Key:   []byte(key),
+		Value: value,
+	}
+
+	_, err = u.writeBreaker.Execute(func() (interface{}, error) {
+		return nil, writer.WriteMessages(context.Background(), msg)
+	})
+
+	if err != nil {
+		return fmt.Errorf("kafka write failed or circuit open: %w", err)
+	}
+
+	return nil
+}
+
+// reply hanya dikirim untuk payment yang sudah final
+func (u *paymentUsecase) reply(p *entity.Payment) error {
+	reply := dto.SagaReplyKafka{
+		CorrelationID: p.CorrelationID,
+		PaymentID:     p.IntentID,
+	}
+	switch p.Status {
+	case entity.PaymentSucceeded:
+		reply.Success = true
+	case entity.PaymentFailed:
+		reply.Reason = p.FailureReason
+	default:
+		return nil
+	}
+
+	return u.WriteKafkaMessage("payment-charge-response", p.CorrelationID, reply)
+}
+
+func (u *paymentUsecase) Charge(req *dto.PaymentChargeKafka) error {
+	existing, err := u.paymentRepo.GetPaymentByCorrelation(req.CorrelationID)
+	if err == nil {
+		// charge request dikirim ulang saat saga recover
+		return u.reply(existing)
+	}
+	if err != utils.ErrPaymentNotFound {
+		return err
+	}
+
+	intent, err := u.gateway.CreateIntent(context.Background(), req.Amount, req.CorrelationID)
+	if err != nil {
+		failed := &entity.Payment{
+			CorrelationID: req.CorrelationID,
+			OrderID:       req.OrderID,
+			UserID:        req.UserID,
+			Amount:        req.Amount,
+			Status:        entity.PaymentFailed,
+			IntentID:      "failed_" + req.CorrelationID,
+			FailureReason: err.Error(),
+		}
+		if failed, err = u.paymentRepo.CreatePayment(failed); err != nil {
+			return err
+		}
+		return u.reply(failed)
+	}
+
+	_, err = u.paymentRepo.CreatePayment(&entity.Payment{
+		CorrelationID: req.CorrelationID,
+		OrderID:       req.OrderID,
+		UserID:        req.UserID,
+		Amount:        req.Amount,
+		Status:        entity.PaymentPending,
+		IntentID:      intent.ID,
+	})
+	return err
+}
+
+func (u *paymentUsecase) Pay(userId, orderId uint) (*dto.Payment, error) {
+	p, err := u.paymentRepo.GetPaymentByOrder(userId, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := u.paymentRepo.UpdatePaymentStatus(p.ID, []string{entity.PaymentPending}, entity.PaymentProcessing, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, utils.ErrPaymentStatus
+	}
+
+	if err := u.gateway.Confirm(context.Background(), p.IntentID); err != nil {
+		// kembalikan ke pending supaya user bisa mencoba lagi
+		u.paymentRepo.UpdatePaymentStatus(p.ID, []string{entity.PaymentProcessing}, entity.PaymentPending, nil)
+		return nil, err
+	}
+
+	p.Status = entity.PaymentProcessing
+	return toPaymentDTO(p), nil
+}
+
+func (u *paymentUsecase) GetPayment(userId, orderId uint) (*dto.Payment, error) {
+	p, err := u.paymentRepo.GetPaymentByOrder(userId, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	return toPaymentDTO(p), nil
+}
+
+// HandleWebhook memproses callback provider. Event dicatat setelah reply terkirim,
+// jadi kalau kafka gagal provider akan mengirim ulang dan reply dicoba lagi.
+func (u *paymentUsecase) HandleWebhook(payload []byte, signature string) error {
+	event, err := u.gateway.ParseWebhook(payload, signature)
+	if err != nil {
+		return err
+	}
+
+	processed, err := u.paymentRepo.IsEventProcessed(event.ID)
+	if err != nil {
+		return err
+	}
+	if processed {
+		return nil
+	}
+
+	p, err := u.paymentRepo.GetPaymentByIntent(event.IntentID)
+	if err != nil {
+		return err
+	}
+
+	from := []string{entity.PaymentPending, entity.PaymentProcessing}
+	switch event.Type {
+	case payment.EventSucceeded:
+		if _, err := u.paymentRepo.UpdatePaymentStatus(p.ID, from, entity.PaymentSucceeded, nil); err != nil {
+			return err
+		}
+	case payment.EventFailed:
+		if _, err := u.paymentRepo.UpdatePaymentStatus(p.ID, from, entity.PaymentFailed, map[string]interface{}{
+			"failure_reason": event.Reason,
+		}); err != nil {
+			return err
+		}
+	default:
+		return u.paymentRepo.RecordEvent(event.ID, p.ID, event.Type)
+	}
+
+	p, err = u.paymentRepo.GetPaymentByIntent(event.IntentID)
+	if err != nil {
+		return err
+	}
+	if err := u.reply(p); err != nil {
+		return utils.ErrFailedKafkaWrite
+	}
+
+	return u.paymentRepo.RecordEvent(event.ID, p.ID, event.Type)
+}
+
+func (u *paymentUsecase) Refund(req *dto.PaymentRefundKafka) error {
+	if req.ReturnID != 0 {
+		return u.refundReturn(req)
+	}
+
+	p, err := u.paymentRepo.GetPaymentByCorrelation(req.CorrelationID)
+	if err == utils.ErrPaymentNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// hanya payment sukses yang perlu dikembalikan, refund ulang diabaikan
+	if p.Status != entity.PaymentSucceeded {
+		return nil
+	}
+
+	// sebagian mungkin sudah dikembalikan lewat retur
+	refund, err := u.gateway.Refund(context.Background(), p.IntentID, p.Amount-p.RefundedAmount, fmt.Sprintf("payment-%d", p.ID))
+	if err != nil {
+		return err
+	}
+
+	_, err = u.paymentRepo.UpdatePaymentStatus(p.ID, []string{entity.PaymentSucceeded}, entity.PaymentRefunded, map[string]interface{}{
+		"refund_id": refund.ID,
+	})
+	return err
+}
+
+// refundReturn mengembalikan sebagian payment untuk satu retur. Nilainya dipesan
+// dulu sebagai refund pending dengan payment dikunci, baru gateway dipanggil
+// dengan id retur sebagai idempotency key, jadi retur yang diproses bersamaan
+// tidak melewati nilai payment dan pesan yang terkirim ulang tidak merefund dua
+// kali. Hasilnya selalu dibalas ke return-refund-response.
+func (u *paymentUsecase) refundReturn(req *dto.PaymentRefundKafka) error {
+	reply := dto.ReturnRefundReplyKafka{ReturnID: req.ReturnID}
+	key := fmt.Sprintf("return-%d", req.ReturnID)
+
+	existing, err := u.paymentRepo.GetRefundByReturn(req.ReturnID)
+	if err != nil && err != utils.ErrPaymentNotFound {
+		return err
+	}
+	if err == nil && existing.Status == entity.RefundSucceeded {
+		reply.Success = true
+		reply.RefundID = existing.RefundID
+		return u.WriteKafkaMessage("return-refund-response", key, reply)
+	}
+
+	p, err := u.paymentRepo.GetPaymentByOrder(req.UserID, req.OrderID)
+	if err == utils.ErrPaymentNotFound {
+		reply.Reason = err.Error()
+		return u.WriteKafkaMessage("return-refund-response", key, reply)
+	}
+	if err != nil {
+		return err
+	}
+
+	pending, err := u.paymentRepo.ReserveRefund(&entity.PaymentRefund{
+		PaymentID: p.ID,
+		ReturnID:  req.ReturnID,
+		Amount:    req.Amount,
+	})
+	switch err {
+	case nil:
+	case utils.ErrPaymentStatus, utils.ErrRefundExceeded:
+		reply.Reason = err.Error()
+		return u.WriteKafkaMessage("return-refund-response", key, reply)
+	default:
+		return err
+	}
+	if pending.Status == entity.RefundSucceeded {
+		reply.Success = true
+		reply.RefundID = pending.RefundID
+		return u.WriteKafkaMessage("return-refund-response", key, reply)
+	}
+
+	refund, err := u.gateway.Refund(context.Background(), p.IntentID, pending.Amount, key)
+	if err != nil {
+		if errFail := u.paymentRepo.FailRefund(pending.ID, err.Error()); errFail != nil {
+			return errFail
+		}
+		reply.Reason = err.Error()
+		return u.WriteKafkaMessage("return-refund-response", key, reply)
+	}
+
+	if err := u.paymentRepo.CompleteRefund(pending.ID, refund.ID); err != nil {
+		return err
+	}
+
+	reply.Success = true
+	reply.RefundID = refund.ID
+	return u.WriteKafkaMessage("return-refund-response", key, reply)
+}
@@ -0,0 +1,404 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"service_cart/dto"
+	"service_cart/entity"
+	"service_cart/helper/tax"
+	"service_cart/helper/utils"
+	"service_cart/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/sony/gobreaker"
+)
+
+type ReturnUsecase interface {
+	RequestReturn(req *dto.ReturnReq) (*dto.Return, error)
+	GetMyReturns(userId uint) ([]dto.Return, error)
+	GetStoreReturns(userId, storeId uint, status string) ([]dto.Return, error)
+	DecideReturn(req *dto.ReturnDecisionReq) (*dto.Return, error)
+
+	//kafka
+	CompleteRefund(reply *dto.ReturnRefundReplyKafka) error
+	WriteKafkaMessage(topic string, key string, payload interface{}) error
+}
+
+type returnUsecase struct {
+	returnRepo   repository.ReturnRepo
+	orderRepo    repository.OrderRepo
+	cartRepo     repository.CartRepo
+	kafka        map[string]*kafka.Writer
+	writeBreaker *gobreaker.CircuitBreaker
+}
+
+func NewReturnUsecase(returnRepo repository.ReturnRepo, orderRepo repository.OrderRepo, cartRepo repository.CartRepo, kafka map[string]*kafka.Writer) ReturnUsecase {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "ReturnProducerBreaker",
+		MaxRequests: 5,
+		Interval:    30 * time.Second,
+		Timeout:     5 * time.Second,
+		OnStateChange: func(name string, from, to gobreaker.State) {
+			log.Printf("[Circuit Breaker: %s] status berubah dari %s ➜ %s\n", name, from.String(), to.String())
+		},
+	})
+	return &returnUsecase{returnRepo, orderRepo, cartRepo, kafka, cb}
+}
+
+func (u *returnUsecase) WriteKafkaMessage(topic string, key string, payload interface{}) error {
+	writer, ok := u.kafka[topic]
+	if !ok {
+		return utils.ErrNoTopic
+	}
+
+	value, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	msg := kafka.Message{
+		Key:   []byte(key),
+		Value: value,
+	}
+
+	_, err = u.writeBreaker.Execute(func() (interface{}, error) {
+		return nil, writer.WriteMessages(context.Background(), msg)
+	})
+
+	if err != nil {
+		return fmt.Errorf("kafka write failed or circuit open: %w", err)
+	}
+
+	return nil
+}
+
+func toReturnDTO(r *entity.ReturnRequest) *dto.Return {
+	photos := make([]string, 0, len(r.Photos))
+	for _, photo := range r.Photos {
+		photos = append(photos, photo.URL)
+	}
+
+	return &dto.Return{
+		ID:            r.ID,
+		OrderID:       r.OrderID,
+		OrderItemID:   r.OrderItemID,
+		UserID:        r.UserID,
+		StoreID:       r.StoreID,
+		ProductID:     r.ProductID,
+		ProductName:   r.ProductName,
+		Quantity:      r.Quantity,
+		Reason:        r.Reason,
+		Photos:        photos,
+		Status:        r.Status,
+		RefundAmount:  r.RefundAmount,
+		SellerNote:    r.SellerNote,
+		RefundID:      r.RefundID,
+		FailureReason: r.FailureReason,
+		DecidedAt:     r.DecidedAt,
+		RefundedAt:    r.RefundedAt,
+		CreatedAt:     r.CreatedAt,
+		UpdatedAt:     r.UpdatedAt,
+	}
+}
+
+// refundAmount menghitung nilai yang benar-benar dibayar untuk qty yang diretur:
+// subtotal dikurangi bagian diskon baris itu, ditambah pajak exclusive-nya.
+// Ongkir tidak ikut dikembalikan.
+func refundAmount(order *dto.Order, item *dto.OrderItem, quantity int) int64 {
+	discount := item.DiscountAmount
+	if discount == 0 && order.DiscountAmount > 0 {
+		discount = legacyLineDiscount(order, item)
+	}
+
+	paid := item.Subtotal - discount
+	if item.TaxMode == tax.ModeExclusive {
+		paid += item.TaxAmount
+	}
+	if paid < 0 {
+		paid = 0
+	}
+
+	return paid * int64(quantity) / int64(item.Quantity)
+}
+
+// legacyLineDiscount untuk order sebelum diskon disimpan per baris, dibagi
+// sebanding subtotal dengan aturan yang sama seperti allocateDiscounts
+func legacyLineDiscount(order *dto.Order, item *dto.OrderItem) int64 {
+	for _, other := range order.Items {
+		if other.DiscountAmount > 0 {
+			return 0
+		}
+	}
+
+	var discount int64
+	for _, d := range order.Discounts {
+		if d.StoreID != nil && *d.StoreID != item.StoreID {
+			continue
+		}
+
+		var base int64
+		for _, other := range order.Items {
+			if d.StoreID == nil || *d.StoreID == other.StoreID {
+				base += other.Subtotal
+			}
+		}
+		if base > 0 {
+			discount += d.Amount * item.Subtotal / base
+		}
+	}
+	return discount
+}
+
+func (u *returnUsecase) RequestReturn(req *dto.ReturnReq) (*dto.Return, error) {
+	order, err := u.orderRepo.GetOrder(req.UserID, req.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != entity.OrderPaid && order.Status != entity.OrderFulfilled {
+		return nil, utils.ErrReturnNotAllowed
+	}
+
+	var item *dto.OrderItem
+	for i := range order.Items {
+		if order.Items[i].ID == req.OrderItemID {
+			item = &order.Items[i]
+			break
+		}
+	}
+	if item == nil {
+		return nil, utils.ErrOrderNotFound
+	}
+	if req.Quantity > item.Quantity {
+		return nil, utils.ErrReturnQuantity
+	}
+
+	ret := entity.ReturnRequest{
+		OrderID:      order.ID,
+		OrderItemID:  item.ID,
+		UserID:       req.UserID,
+		StoreID:      item.StoreID,
+		ProductID:    item.ProductID,
+		ProductName:  item.ProductName,
+		Quantity:     req.Quantity,
+		Reason:       req.Reason,
+		Status:       entity.ReturnRequested,
+		BuyerEmail:   req.Email,
+		RefundAmount: refundAmount(order, item, req.Quantity),
+	}
+	for _, url := range req.Photos {
+		ret.Photos = append(ret.Photos, entity.ReturnPhoto{URL: url})
+	}
+
+	created, err := u.returnRepo.CreateReturn(&ret, item.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toReturnDTO(created)
+	if err := u.notifySeller(response, "requested"); err != nil {
+		log.Printf("return %d: gagal memberi tahu penjual: %v", response.ID, err)
+	}
+
+	return response, nil
+}
+
+func toReturnDTOs(returns []entity.ReturnRequest) []dto.Return {
+	result := make([]dto.Return, 0, len(returns))
+	for i := range returns {
+		result = append(result, *toReturnDTO(&returns[i]))
+	}
+	return result
+}
+
+func (u *returnUsecase) GetMyReturns(userId uint) ([]dto.Return, error) {
+	returns, err := u.returnRepo.GetMyReturns(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return toReturnDTOs(returns), nil
+}
+
+func (u *returnUsecase) GetStoreReturns(userId, storeId uint, status string) ([]dto.Return, error) {
+	if err := checkStoreAdmin(u.WriteKafkaMessage, u.cartRepo, userId, storeId); err != nil {
+		return nil, err
+	}
+
+	returns, err := u.returnRepo.GetStoreReturns(storeId, status)
+	if err != nil {
+		return nil, err
+	}
+
+	return toReturnDTOs(returns), nil
+}
+
+// DecideReturn dipanggil admin toko. Approve pada retur yang sudah approved
+// mengirim ulang permintaan refund, dipakai kalau refund sebelumnya gagal.
+func (u *returnUsecase) DecideReturn(req *dto.ReturnDecisionReq) (*dto.Return, error) {
+	ret, err := u.returnRepo.GetReturn(req.ReturnID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStoreAdmin(u.WriteKafkaMessage, u.cartRepo, req.UserID, ret.StoreID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !req.Approve {
+		ok, err := u.returnRepo.UpdateReturnStatus(ret.ID, []string{entity.ReturnRequested}, entity.ReturnRejected, map[string]interface{}{
+			"seller_note": req.Note,
+			"decided_at":  now,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, utils.ErrReturnStatus
+		}
+
+		ret.Status = entity.ReturnRejected
+		ret.SellerNote = req.Note
+		ret.DecidedAt = &now
+		response := toReturnDTO(ret)
+		if err := u.notify(ret.BuyerEmail, entity.ReturnRejected, response); err != nil {
+			log.Printf("return %d: gagal memberi tahu pembeli: %v", ret.ID, err)
+		}
+		return response, nil
+	}
+
+	switch ret.Status {
+	case entity.ReturnRequested:
+		ok, err := u.returnRepo.UpdateReturnStatus(ret.ID, []string{entity.ReturnRequested}, entity.ReturnApproved, map[string]interface{}{
+			"seller_note": req.Note,
+			"decided_at":  now,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, utils.ErrReturnStatus
+		}
+
+		ret.Status = entity.ReturnApproved
+		ret.SellerNote = req.Note
+		ret.DecidedAt = &now
+		if err := u.notify(ret.BuyerEmail, entity.ReturnApproved, toReturnDTO(ret)); err != nil {
+			log.Printf("return %d: gagal memberi tahu pembeli: %v", ret.ID, err)
+		}
+	case entity.ReturnApproved:
+	default:
+		return nil, utils.ErrReturnStatus
+	}
+
+	// refund diproses module payment, hasilnya kembali lewat return-refund-response
+	key := fmt.Sprintf("return-%d", ret.ID)
+	if err := u.WriteKafkaMessage("payment-refund-request", key, dto.PaymentRefundKafka{
+		CorrelationID: key,
+		OrderID:       ret.OrderID,
+		Amount:        ret.RefundAmount,
+		ReturnID:      ret.ID,
+		UserID:        ret.UserID,
+	}); err != nil {
+		return nil, utils.ErrFailedKafkaWrite
+	}
+
+	return toReturnDTO(ret), nil
+}
+
+// CompleteRefund dipanggil dari balasan module payment. Restock dikirim lebih dulu
+// karena idempotent per retur di service product, baru status retur ditutup.
+func (u *returnUsecase) CompleteRefund(reply *dto.ReturnRefundReplyKafka) error {
+	ret, err := u.returnRepo.GetReturn(reply.ReturnID)
+	if err == utils.ErrReturnNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if ret.Status != entity.ReturnApproved {
+		return nil
+	}
+
+	if !reply.Success {
+		if _, err := u.returnRepo.UpdateReturnStatus(ret.ID, []string{entity.ReturnApproved}, entity.ReturnApproved, map[string]interface{}{
+			"failure_reason": reply.Reason,
+		}); err != nil {
+			return err
+		}
+
+		ret.FailureReason = reply.Reason
+		if err := u.notifySeller(toReturnDTO(ret), "refund_failed"); err != nil {
+			log.Printf("return %d: gagal memberi tahu penjual: %v", ret.ID, err)
+		}
+		return nil
+	}
+
+	if err := u.WriteKafkaMessage("stock-restock-request", fmt.Sprintf("return-%d", ret.ID), dto.StockRestockKafka{
+		ReturnID:  ret.ID,
+		OrderID:   ret.OrderID,
+		ProductID: ret.ProductID,
+		Quantity:  ret.Quantity,
+	}); err != nil {
+		return utils.ErrFailedKafkaWrite
+	}
+
+	now := time.Now()
+	ok, err := u.returnRepo.UpdateReturnStatus(ret.ID, []string{entity.ReturnApproved}, entity.ReturnRefunded, map[string]interface{}{
+		"refund_id":      reply.RefundID,
+		"refunded_at":    now,
+		"failure_reason": "",
+	})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	ret.Status = entity.ReturnRefunded
+	ret.RefundID = reply.RefundID
+	ret.RefundedAt = &now
+	ret.FailureReason = ""
+	if err := u.notify(ret.BuyerEmail, entity.ReturnRefunded, toReturnDTO(ret)); err != nil {
+		log.Printf("return %d: gagal memberi tahu pembeli: %v", ret.ID, err)
+	}
+	return nil
+}
+
+func (u *returnUsecase) notify(email, action string, ret *dto.Return) error {
+	corrId := uuid.NewString()
+	message, _ := json.Marshal(ret)
+	payload := map[string]interface{}{
+		"correlation_id": corrId,
+		"email":          email,
+		"service":        "return",
+		"action":         action,
+		"message":        string(message),
+	}
+	return u.WriteKafkaMessage("notification-request", corrId, payload)
+}
+
+func (u *returnUsecase) notifySeller(ret *dto.Return, action string) error {
+	email, err := u.storeAdminEmail(ret.StoreID)
+	if err != nil {
+		return err
+	}
+	return u.notify(email, action, ret)
+}
+
+func (u *returnUsecase) storeAdminEmail(storeId uint) (string, error) {
+	emails, err := storeAdminEmails(u.WriteKafkaMessage, u.cartRepo, []uint{storeId})
+	if err != nil {
+		return "", err
+	}
+	email, ok := emails[storeId]
+	if !ok {
+		return "", fmt.Errorf("email admin toko #%d tidak ditemukan", storeId)
+	}
+
+	return email, nil
+}
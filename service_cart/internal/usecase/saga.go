@@ -0,0 +1,499 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"service_cart/dto"
+	"service_cart/entity"
+	"service_cart/helper/utils"
+	"service_cart/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/sony/gobreaker"
+)
+
+type CheckoutSaga interface {
+	Start(order *dto.Order, email string) (*dto.Saga, error)
+	GetSagaByOrder(userId, orderId uint) (*dto.Saga, error)
+	IsRunning(orderId uint) (bool, error)
+
+	//kafka reply
+	HandleStockReserved(reply *dto.SagaReplyKafka) error
+	HandlePaymentCharged(reply *dto.SagaReplyKafka) error
+
+	//scheduler
+	ExpireTimedOut() error
+	Recover() error
+
+	WriteKafkaMessage(topic string, key string, payload interface{}) error
+}
+
+type checkoutSaga struct {
+	sagaRepo     repository.SagaRepo
+	orderRepo    repository.OrderRepo
+	cartRepo     repository.CartRepo
+	invoice      InvoiceUsecase
+	kafka        map[string]*kafka.Writer
+	writeBreaker *gobreaker.CircuitBreaker
+}
+
+func NewCheckoutSaga(sagaRepo repository.SagaRepo, orderRepo repository.OrderRepo, cartRepo repository.CartRepo, invoice InvoiceUsecase, kafka map[string]*kafka.Writer) CheckoutSaga {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "SagaProducerBreaker",
+		MaxRequests: 5,
+		Interval:    30 * time.Second,
+		Timeout:     5 * time.Second,
+		OnStateChange: func(name string, from, to gobreaker.State) {
+			log.Printf("[Circuit Breaker: %s] status berubah dari %s ➜ %s\n", name, from.String(), to.String())
+		},
+	})
+	return &checkoutSaga{sagaRepo, orderRepo, cartRepo, invoice, kafka, cb}
+}
+
+// batas waktu menunggu reply tiap step sebelum saga dikompensasi. confirm dan notify
+// sudah lewat pembayaran, lewat batasnya step tersebut dijalankan ulang, bukan dikompensasi.
+var sagaStepTimeout = map[string]time.Duration{
+	entity.SagaStepReserveStock: 30 * time.Second,
+	entity.SagaStepCharge:       10 * time.Minute,
+	entity.SagaStepConfirm:      time.Minute,
+	entity.SagaStepNotify:       time.Minute,
+}
+
+func deadlineFor(step string) time.Time {
+	return time.Now().Add(sagaStepTimeout[step])
+}
+
+func toSagaDTO(s *entity.Saga) *dto.Saga {
+	return &dto.Saga{
+		ID:            s.ID,
+		OrderID:       s.OrderID,
+		Step:          s.Step,
+		Status:        s.Status,
+		FailureReason: s.FailureReason,
+		UpdatedAt:     s.UpdatedAt,
+	}
+}
+
+func (u *checkoutSaga) WriteKafkaMessage(topic string, key string, payload interface{}) error {
+	writer, ok := u.kafka[topic]
+	if !ok {
+		return utils.ErrNoTopic
+	}
+
+	value, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	msg := kafka.Message{
+		Key:   []byte(key),
+		Value: value,
+	}
+
+	_, err = u.writeBreaker.Execute(func() (interface{}, error) {
+		return nil, writer.WriteMessages(context.Background(), msg)
+	})
+
+	if err != nil {
+		return fmt.Errorf("kafka write failed or circuit open: %w", err)
+	}
+
+	return nil
+}
+
+func (u *checkoutSaga) Start(order *dto.Order, email string) (*dto.Saga, error) {
+	saga := entity.Saga{
+		ID:       uuid.NewString(),
+		OrderID:  order.ID,
+		UserID:   order.UserID,
+		Email:    email,
+		Step:     entity.SagaStepReserveStock,
+		Status:   entity.SagaRunning,
+		Deadline: deadlineFor(entity.SagaStepReserveStock),
+	}
+	if err := u.sagaRepo.CreateSaga(&saga); err != nil {
+		return nil, err
+	}
+
+	// kalau command gagal terkirim, saga tetap tersimpan dan akan timeout lalu dikompensasi
+	if err := u.runStep(&saga); err != nil {
+		log.Printf("saga %s: step %s gagal: %v", saga.ID, saga.Step, err)
+	}
+
+	return toSagaDTO(&saga), nil
+}
+
+func (u *checkoutSaga) GetSagaByOrder(userId, orderId uint) (*dto.Saga, error) {
+	saga, err := u.sagaRepo.GetSagaByOrder(orderId)
+	if err != nil {
+		return nil, err
+	}
+	if saga.UserID != userId {
+		return nil, utils.ErrSagaNotFound
+	}
+
+	return toSagaDTO(saga), nil
+}
+
+func (u *checkoutSaga) IsRunning(orderId uint) (bool, error) {
+	saga, err := u.sagaRepo.GetSagaByOrder(orderId)
+	if err == utils.ErrSagaNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return saga.Status == entity.SagaRunning || saga.Status == entity.SagaCompensating, nil
+}
+
+// runStep mengirim command untuk step saat ini, atau menjalankan step lokal
+func (u *checkoutSaga) runStep(saga *entity.Saga) error {
+	switch saga.Step {
+	case entity.SagaStepReserveStock:
+		order, err := u.orderRepo.GetOrder(saga.UserID, saga.OrderID)
+		if err != nil {
+			return err
+		}
+
+		items := make([]dto.StockItemKafka, 0, len(order.Items))
+		for _, item := range order.Items {
+			items = append(items, dto.StockItemKafka{
+				ProductID: item.ProductID,
+				Quantity:  item.Quantity,
+			})
+		}
+
+		return u.WriteKafkaMessage("stock-reserve-request", saga.ID, dto.StockReserveKafka{
+			CorrelationID: saga.ID,
+			OrderID:       saga.OrderID,
+			Items:         items,
+		})
+
+	case entity.SagaStepCharge:
+		order, err := u.orderRepo.GetOrder(saga.UserID, saga.OrderID)
+		if err != nil {
+			return err
+		}
+
+		return u.WriteKafkaMessage("payment-charge-request", saga.ID, dto.PaymentChargeKafka{
+			CorrelationID: saga.ID,
+			OrderID:       saga.OrderID,
+			UserID:        saga.UserID,
+			Amount:        order.TotalAmount,
+		})
+
+	case entity.SagaStepConfirm:
+		err := u.orderRepo.UpdateOrderStatus(saga.UserID, saga.OrderID, entity.OrderPending, entity.OrderPaid)
+		// status sudah paid berarti confirm sempat jalan sebelum service restart
+		if err != nil && err != utils.ErrInvalidStatus {
+			return err
+		}
+
+		ok, err := u.sagaRepo.AdvanceSaga(saga.ID, entity.SagaStepConfirm, entity.SagaStepNotify, deadlineFor(entity.SagaStepNotify), nil)
+		if err != nil || !ok {
+			return err
+		}
+		saga.Step = entity.SagaStepNotify
+		return u.runStep(saga)
+
+	case entity.SagaStepNotify:
+		if err := u.notify(saga); err != nil {
+			return err
+		}
+
+		ok, err := u.sagaRepo.AdvanceSaga(saga.ID, entity.SagaStepNotify, entity.SagaStepDone, time.Now(), nil)
+		if err != nil || !ok {
+			return err
+		}
+		saga.Step = entity.SagaStepDone
+		saga.Status = entity.SagaCompleted
+		return u.sagaRepo.FinishSaga(saga.ID, entity.SagaCompleted)
+	}
+
+	return nil
+}
+
+func (u *checkoutSaga) notify(saga *entity.Saga) error {
+	order, err := u.orderRepo.GetOrder(saga.UserID, saga.OrderID)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range order.Items {
+		paid := dto.CartItemPaidKafka{
+			CartItemID:     item.CartItemID,
+			UserID:         saga.UserID,
+			ProductID:      item.ProductID,
+			PurchaseAmount: item.Quantity,
+			PaidAt:         order.UpdatedAt,
+		}
+		if err := u.WriteKafkaMessage("cart-item-paid", saga.ID, paid); err != nil {
+			return err
+		}
+	}
+
+	if err := publishStoreOrders(u.WriteKafkaMessage, order, entity.OrderPaid); err != nil {
+		return err
+	}
+
+	// email diambil dari token saat checkout, saga lama bisa saja tidak menyimpannya
+	email := saga.Email
+	if email == "" {
+		contacts, err := fetchUserContacts(u.WriteKafkaMessage, u.cartRepo, []uint{saga.UserID})
+		if err != nil {
+			return err
+		}
+		email = contacts[saga.UserID].Email
+	}
+
+	message, _ := json.Marshal(order)
+	payload := map[string]interface{}{
+		"correlation_id": saga.ID,
+		"email":          email,
+		"service":        "order",
+		"action":         "paid",
+		"message":        string(message),
+	}
+
+	// email tetap dikirim walau invoice gagal dibuat, user masih bisa mengunduhnya nanti
+	file, err := u.invoice.Generate(order, email)
+	if err != nil {
+		log.Printf("saga %s: gagal membuat invoice: %v", saga.ID, err)
+	} else {
+		payload["attachment"] = dto.Attachment{
+			Filename: file.Filename,
+			Content:  file.Content,
+		}
+	}
+
+	if err := u.WriteKafkaMessage("notification-request", saga.ID, payload); err != nil {
+		return err
+	}
+
+	// email pembeli sudah terkirim, gagal memberi tahu penjual tidak mengulang step ini
+	if err := u.notifySellers(saga, order); err != nil {
+		log.Printf("saga %s: gagal kirim notifikasi order baru ke penjual: %v", saga.ID, err)
+	}
+	return nil
+}
+
+// notifySellers mengirim email order baru ke admin setiap toko di order, hanya
+// berisi barang toko itu sendiri
+func (u *checkoutSaga) notifySellers(saga *entity.Saga, order *dto.Order) error {
+	storeIds, stores := splitStoreOrders(order, entity.OrderPaid)
+	emails, err := storeAdminEmails(u.WriteKafkaMessage, u.cartRepo, storeIds)
+	if err != nil {
+		return err
+	}
+
+	for _, storeId := range storeIds {
+		email, ok := emails[storeId]
+		if !ok {
+			log.Printf("saga %s: email admin toko #%d tidak ditemukan", saga.ID, storeId)
+			continue
+		}
+
+		corrId := fmt.Sprintf("%s-store-%d", saga.ID, storeId)
+		message, _ := json.Marshal(stores[storeId])
+		payload := map[string]interface{}{
+			"correlation_id": corrId,
+			"email":          email,
+			"service":        "order",
+			"action":         "new_order",
+			"message":        string(message),
+		}
+		if err := u.WriteKafkaMessage("notification-request", corrId, payload); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (u *checkoutSaga) HandleStockReserved(reply *dto.SagaReplyKafka) error {
+	saga, err := u.sagaRepo.GetSaga(reply.CorrelationID)
+	if err != nil {
+		return err
+	}
+
+	if saga.Status != entity.SagaRunning || saga.Step != entity.SagaStepReserveStock {
+		// reply datang setelah saga dikompensasi, stok yang terlanjur ditahan dilepas lagi
+		if reply.Success && (saga.Status == entity.SagaFailed || saga.Status == entity.SagaCompensating) {
+			return u.releaseStock(saga)
+		}
+		return nil
+	}
+
+	if !reply.Success {
+		return u.compensate(saga, reply.Reason)
+	}
+
+	ok, err := u.sagaRepo.AdvanceSaga(saga.ID, entity.SagaStepReserveStock, entity.SagaStepCharge, deadlineFor(entity.SagaStepCharge), map[string]interface{}{
+		"stock_reserved": true,
+	})
+	if err != nil || !ok {
+		return err
+	}
+
+	saga.Step = entity.SagaStepCharge
+	saga.StockReserved = true
+	return u.runStep(saga)
+}
+
+func (u *checkoutSaga) HandlePaymentCharged(reply *dto.SagaReplyKafka) error {
+	saga, err := u.sagaRepo.GetSaga(reply.CorrelationID)
+	if err != nil {
+		return err
+	}
+
+	if saga.Status != entity.SagaRunning || saga.Step != entity.SagaStepCharge {
+		// pembayaran masuk setelah saga gagal, uang dikembalikan
+		if reply.Success && (saga.Status == entity.SagaFailed || saga.Status == entity.SagaCompensating) {
+			saga.PaymentID = reply.PaymentID
+			return u.refundPayment(saga)
+		}
+		return nil
+	}
+
+	if !reply.Success {
+		return u.compensate(saga, reply.Reason)
+	}
+
+	ok, err := u.sagaRepo.AdvanceSaga(saga.ID, entity.SagaStepCharge, entity.SagaStepConfirm, deadlineFor(entity.SagaStepConfirm), map[string]interface{}{
+		"payment_charged": true,
+		"payment_id":      reply.PaymentID,
+	})
+	if err != nil || !ok {
+		return err
+	}
+
+	saga.Step = entity.SagaStepConfirm
+	saga.PaymentCharged = true
+	saga.PaymentID = reply.PaymentID
+	return u.runStep(saga)
+}
+
+func (u *checkoutSaga) compensate(saga *entity.Saga, reason string) error {
+	ok, err := u.sagaRepo.StartCompensation(saga.ID, reason)
+	if err != nil || !ok {
+		return err
+	}
+
+	saga.Status = entity.SagaCompensating
+	saga.FailureReason = reason
+	return u.runCompensation(saga)
+}
+
+// runCompensation aman dijalankan ulang, semua participant idempotent per correlation_id
+func (u *checkoutSaga) runCompensation(saga *entity.Saga) error {
+	if saga.PaymentCharged {
+		if err := u.refundPayment(saga); err != nil {
+			return err
+		}
+	}
+
+	// reserve bisa saja sudah diproses walau reply-nya belum sampai
+	if err := u.releaseStock(saga); err != nil {
+		return err
+	}
+
+	err := u.orderRepo.UpdateOrderStatus(saga.UserID, saga.OrderID, entity.OrderPending, entity.OrderCancelled)
+	if err != nil && err != utils.ErrInvalidStatus {
+		return err
+	}
+
+	order, err := u.orderRepo.GetOrder(saga.UserID, saga.OrderID)
+	if err != nil {
+		return err
+	}
+	if err := publishStoreOrders(u.WriteKafkaMessage, order, entity.OrderCancelled); err != nil {
+		log.Printf("saga %s: gagal kirim event order toko: %v", saga.ID, err)
+	}
+
+	saga.Status = entity.SagaFailed
+	return u.sagaRepo.FinishSaga(saga.ID, entity.SagaFailed)
+}
+
+func (u *checkoutSaga) releaseStock(saga *entity.Saga) error {
+	return u.WriteKafkaMessage("stock-release-request", saga.ID, dto.StockReleaseKafka{
+		CorrelationID: saga.ID,
+		OrderID:       saga.OrderID,
+	})
+}
+
+func (u *checkoutSaga) refundPayment(saga *entity.Saga) error {
+	order, err := u.orderRepo.GetOrder(saga.UserID, saga.OrderID)
+	if err != nil {
+		return err
+	}
+
+	return u.WriteKafkaMessage("payment-refund-request", saga.ID, dto.PaymentRefundKafka{
+		CorrelationID: saga.ID,
+		OrderID:       saga.OrderID,
+		PaymentID:     saga.PaymentID,
+		Amount:        order.TotalAmount,
+	})
+}
+
+// kompensasi yang gagal di tengah (misalnya kafka tidak bisa ditulis) dicoba
+// lagi setelah saga tidak berubah selama ini
+const compensationRetryAfter = 30 * time.Second
+
+func (u *checkoutSaga) ExpireTimedOut() error {
+	now := time.Now()
+	sagas, err := u.sagaRepo.GetExpiredSagas(now, now.Add(-compensationRetryAfter))
+	if err != nil {
+		return err
+	}
+
+	for i := range sagas {
+		saga := &sagas[i]
+		if saga.Status == entity.SagaCompensating {
+			if err := u.runCompensation(saga); err != nil {
+				log.Printf("saga %s: kompensasi ulang gagal: %v", saga.ID, err)
+			}
+			continue
+		}
+		if saga.Step == entity.SagaStepConfirm || saga.Step == entity.SagaStepNotify {
+			if err := u.runStep(saga); err != nil {
+				log.Printf("saga %s: step %s ulang gagal: %v", saga.ID, saga.Step, err)
+			}
+			continue
+		}
+
+		reason := fmt.Sprintf("timeout pada step %s", saga.Step)
+		if err := u.compensate(saga, reason); err != nil {
+			log.Printf("saga %s: kompensasi gagal: %v", saga.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// Recover melanjutkan saga yang terputus karena service restart
+func (u *checkoutSaga) Recover() error {
+	sagas, err := u.sagaRepo.GetActiveSagas()
+	if err != nil {
+		return err
+	}
+
+	for i := range sagas {
+		saga := &sagas[i]
+		switch saga.Status {
+		case entity.SagaRunning:
+			err = u.runStep(saga)
+		case entity.SagaCompensating:
+			err = u.runCompensation(saga)
+		}
+		if err != nil {
+			log.Printf("saga %s: recover gagal: %v", saga.ID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,396 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"service_cart/dto"
+	"service_cart/entity"
+	"service_cart/helper/shipping"
+	"service_cart/helper/utils"
+	"service_cart/internal/repository"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/sony/gobreaker"
+)
+
+type ShippingUsecase interface {
+	CreateRate(req *dto.ShippingRateReq) (*dto.ShippingRate, error)
+	GetRates(storeId uint) ([]dto.ShippingRate, error)
+	DeleteRate(userId, storeId, id uint) error
+	Quote(userId, addressId uint) ([]dto.ShippingQuote, error)
+
+	//checkout
+	PlanShipments(userId uint, email string, addressId uint, method string, items []entity.OrderItem, weights map[uint]int) ([]entity.Shipment, error)
+
+	//shipment
+	GetOrderShipments(userId, orderId uint) ([]dto.Shipment, error)
+	UpdateShipmentStatus(req *dto.ShipmentStatusReq) (*dto.Shipment, error)
+
+	//kafka
+	SendStoreShipmentResponse(req *dto.StoreShipmentReqKafka) error
+
+	WriteKafkaMessage(topic string, key string, payload interface{}) error
+}
+
+type shippingUsecase struct {
+	shippingRepo repository.ShippingRepo
+	orderRepo    repository.OrderRepo
+	cartRepo     repository.CartRepo
+	calculator   shipping.RateCalculator
+	kafka        map[string]*kafka.Writer
+	writeBreaker *gobreaker.CircuitBreaker
+}
+
+func NewShippingUsecase(shippingRepo repository.ShippingRepo, orderRepo repository.OrderRepo, cartRepo repository.CartRepo, calculator shipping.RateCalculator, kafka map[string]*kafka.Writer) ShippingUsecase {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "ShippingProducerBreaker",
+		MaxRequests: 5,
+		Interval:    30 * time.Second,
+		Timeout:     5 * time.Second,
+		OnStateChange: func(name string, from, to gobreaker.State) {
+			log.Printf("[Circuit Breaker: %s] status berubah dari %s ➜ %s\n", name, from.String(), to.String())
+		},
+	})
+	return &shippingUsecase{shippingRepo, orderRepo, cartRepo, calculator, kafka, cb}
+}
+
+// status asal yang wajib dimiliki shipment sebelum pindah ke status tujuan
+var shipmentTransitions = map[string]string{
+	entity.ShipmentPacked:    entity.ShipmentPending,
+	entity.ShipmentShipped:   entity.ShipmentPacked,
+	entity.ShipmentDelivered: entity.ShipmentShipped,
+}
+
+func (u *shippingUsecase) WriteKafkaMessage(topic string, key string, payload interface{}) error {
+	writer, ok := u.kafka[topic]
+	if !ok {
+		return utils.ErrNoTopic
+	}
+
+	value, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	msg := kafka.Message{
+		Key:   []byte(key),
+		Value: value,
+	}
+
+	_, err = u.writeBreaker.Execute(func() (interface{}, error) {
+		return nil, writer.WriteMessages(context.Background(), msg)
+	})
+
+	if err != nil {
+		return fmt.Errorf("kafka write failed or circuit open: %w", err)
+	}
+
+	return nil
+}
+
+func (u *shippingUsecase) fetchAddress(userId, addressId uint) (*dto.ShippingAddress, error) {
+	corrId := uuid.NewString()
+	payload := map[string]interface{}{
+		"correlation_id": corrId,
+		"user_id":        userId,
+		"address_id":     addressId,
+	}
+	if err := u.WriteKafkaMessage("address-request", corrId, payload); err != nil {
+		return nil, utils.ErrFailedKafkaWrite
+	}
+
+	var address dto.ShippingAddress
+	if err := u.cartRepo.WaitForResponse(corrId, &address); err != nil {
+		return nil, err
+	}
+	if address.ID == 0 {
+		return nil, utils.ErrAddressNotFound
+	}
+
+	return &address, nil
+}
+
+// storeWeights menjumlahkan berat item per toko, urut store id
+func storeWeights(items []entity.OrderItem, weights map[uint]int) ([]uint, map[uint]int) {
+	total := make(map[uint]int)
+	var storeIds []uint
+	for _, item := range items {
+		if _, ok := total[item.StoreID]; !ok {
+			storeIds = append(storeIds, item.StoreID)
+		}
+		total[item.StoreID] += weights[item.ProductID] * item.Quantity
+	}
+	sort.Slice(storeIds, func(i, j int) bool { return storeIds[i] < storeIds[j] })
+
+	return storeIds, total
+}
+
+func (u *shippingUsecase) CreateRate(req *dto.ShippingRateReq) (*dto.ShippingRate, error) {
+	if err := checkStoreAdmin(u.WriteKafkaMessage, u.cartRepo, req.UserID, req.StoreID); err != nil {
+		return nil, err
+	}
+
+	req.Method = strings.ToLower(strings.TrimSpace(req.Method))
+	return u.shippingRepo.CreateRate(req)
+}
+
+func (u *shippingUsecase) GetRates(storeId uint) ([]dto.ShippingRate, error) {
+	return u.shippingRepo.GetRates(storeId)
+}
+
+func (u *shippingUsecase) DeleteRate(userId, storeId, id uint) error {
+	if err := checkStoreAdmin(u.WriteKafkaMessage, u.cartRepo, userId, storeId); err != nil {
+		return err
+	}
+
+	return u.shippingRepo.DeleteRate(storeId, id)
+}
+
+// Quote menampilkan metode yang bisa dipilih tiap toko untuk isi cart saat ini
+func (u *shippingUsecase) Quote(userId, addressId uint) ([]dto.ShippingQuote, error) {
+	cartItems, err := u.cartRepo.GetMyCartItems(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := fetchProductDetails(u.WriteKafkaMessage, u.cartRepo, cartProductIds(cartItems))
+	if err != nil {
+		return nil, err
+	}
+
+	var items []entity.OrderItem
+	weights := make(map[uint]int)
+	for _, item := range cartItems {
+		product, ok := products[item.ProductID]
+		if !ok || product.Deleted {
+			continue
+		}
+		weights[product.ProductID] = product.Weight
+		items = append(items, entity.OrderItem{
+			ProductID: product.ProductID,
+			StoreID:   product.StoreID,
+			Quantity:  item.PurchaseAmount,
+		})
+	}
+	if len(items) == 0 {
+		return nil, utils.ErrEmptyCart
+	}
+
+	address, err := u.fetchAddress(userId, addressId)
+	if err != nil {
+		return nil, err
+	}
+
+	storeIds, total := storeWeights(items, weights)
+	quotes := make([]dto.ShippingQuote, 0, len(storeIds))
+	for _, storeId := range storeIds {
+		methods, err := u.calculator.Methods(storeId)
+		if err != nil {
+			return nil, err
+		}
+
+		quote := dto.ShippingQuote{StoreID: storeId, Weight: total[storeId], Options: []dto.ShippingOption{}}
+		for _, method := range methods {
+			cost, err := u.calculator.Quote(storeId, method, address.Province, total[storeId])
+			if err == shipping.ErrNoRate {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			quote.Options = append(quote.Options, dto.ShippingOption{Method: method, Cost: cost})
+		}
+		quotes = append(quotes, quote)
+	}
+
+	return quotes, nil
+}
+
+// PlanShipments membuat satu shipment per toko dengan metode yang sama untuk semua toko
+func (u *shippingUsecase) PlanShipments(userId uint, email string, addressId uint, method string, items []entity.OrderItem, weights map[uint]int) ([]entity.Shipment, error) {
+	address, err := u.fetchAddress(userId, addressId)
+	if err != nil {
+		return nil, err
+	}
+
+	method = strings.ToLower(strings.TrimSpace(method))
+	storeIds, total := storeWeights(items, weights)
+	shipments := make([]entity.Shipment, 0, len(storeIds))
+	for _, storeId := range storeIds {
+		cost, err := u.calculator.Quote(storeId, method, address.Province, total[storeId])
+		if err == shipping.ErrNoRate {
+			return nil, utils.ErrShippingMethod
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		shipments = append(shipments, entity.Shipment{
+			StoreID:    storeId,
+			UserID:     userId,
+			BuyerEmail: email,
+			Method:     method,
+			Weight:     total[storeId],
+			Cost:       cost,
+			Status:     entity.ShipmentPending,
+			Recipient:  address.Recipient,
+			Phone:      address.Phone,
+			Street:     address.Street,
+			City:       address.City,
+			Province:   address.Province,
+			PostalCode: address.PostalCode,
+		})
+	}
+
+	return shipments, nil
+}
+
+func (u *shippingUsecase) GetOrderShipments(userId, orderId uint) ([]dto.Shipment, error) {
+	return u.shippingRepo.GetOrderShipments(userId, orderId)
+}
+
+func (u *shippingUsecase) notifyBuyer(shipment *entity.Shipment) error {
+	corrId := uuid.NewString()
+	message, _ := json.Marshal(toShipmentDTO(shipment))
+	payload := map[string]interface{}{
+		"correlation_id": corrId,
+		"email":          shipment.BuyerEmail,
+		"service":        "shipment",
+		"action":         shipment.Status,
+		"message":        string(message),
+	}
+	return u.WriteKafkaMessage("notification-request", corrId, payload)
+}
+
+func toShipmentDTO(s *entity.Shipment) dto.Shipment {
+	return dto.Shipment{
+		ID:             s.ID,
+		OrderID:        s.OrderID,
+		StoreID:        s.StoreID,
+		Method:         s.Method,
+		Weight:         s.Weight,
+		Cost:           s.Cost,
+		Status:         s.Status,
+		TrackingNumber: s.TrackingNumber,
+		Recipient:      s.Recipient,
+		Phone:          s.Phone,
+		Street:         s.Street,
+		City:           s.City,
+		Province:       s.Province,
+		PostalCode:     s.PostalCode,
+		PackedAt:       s.PackedAt,
+		ShippedAt:      s.ShippedAt,
+		DeliveredAt:    s.DeliveredAt,
+		UpdatedAt:      s.UpdatedAt,
+	}
+}
+
+// UpdateShipmentStatus dipanggil seller. Pembeli diberi tahu setiap perpindahan status,
+// dan order dianggap selesai setelah semua shipment-nya terkirim.
+func (u *shippingUsecase) UpdateShipmentStatus(req *dto.ShipmentStatusReq) (*dto.Shipment, error) {
+	if _, ok := shipmentTransitions[req.Status]; !ok {
+		return nil, utils.ErrShipmentStatus
+	}
+
+	shipment, err := u.shippingRepo.GetShipment(req.ShipmentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStoreAdmin(u.WriteKafkaMessage, u.cartRepo, req.UserID, shipment.StoreID); err != nil {
+		return nil, err
+	}
+
+	return u.changeShipmentStatus(shipment, req.Status, req.TrackingNumber)
+}
+
+// SendStoreShipmentResponse menjalankan perubahan status dari halaman order seller
+// di service store dan selalu membalas, termasuk saat perubahan ditolak
+func (u *shippingUsecase) SendStoreShipmentResponse(req *dto.StoreShipmentReqKafka) error {
+	var reply dto.StoreShipmentReplyKafka
+	shipment, err := u.shippingRepo.GetStoreShipment(req.OrderID, req.StoreID)
+	if err == nil {
+		reply.Shipment, err = u.changeShipmentStatus(shipment, req.Status, req.TrackingNumber)
+	}
+	if err != nil {
+		reply.Reason = err.Error()
+	}
+	reply.Success = err == nil
+
+	return u.WriteKafkaMessage("store-shipment-response", req.CorrelationID, map[string]interface{}{
+		"correlation_id": req.CorrelationID,
+		"data":           reply,
+	})
+}
+
+func (u *shippingUsecase) changeShipmentStatus(shipment *entity.Shipment, status, trackingNumber string) (*dto.Shipment, error) {
+	from, ok := shipmentTransitions[status]
+	if !ok {
+		return nil, utils.ErrShipmentStatus
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{}
+	switch status {
+	case entity.ShipmentPacked:
+		updates["packed_at"] = now
+	case entity.ShipmentShipped:
+		tracking := strings.TrimSpace(trackingNumber)
+		if tracking == "" {
+			tracking = fmt.Sprintf("TRK-%d-%s", shipment.ID, strings.ToUpper(uuid.NewString()[:8]))
+		}
+		updates["shipped_at"] = now
+		updates["tracking_number"] = tracking
+	case entity.ShipmentDelivered:
+		updates["delivered_at"] = now
+	}
+
+	ok, err := u.shippingRepo.UpdateShipmentStatus(shipment.ID, from, status, updates)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, utils.ErrShipmentStatus
+	}
+
+	shipment, err = u.shippingRepo.GetShipment(shipment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// status sudah tersimpan, gagal kirim notifikasi tidak membatalkannya
+	if err := u.notifyBuyer(shipment); err != nil {
+		log.Printf("shipment %d: gagal kirim notifikasi: %v", shipment.ID, err)
+	}
+
+	event := "shipment"
+	if shipment.Status == entity.ShipmentDelivered {
+		delivered, err := u.shippingRepo.IsOrderDelivered(shipment.OrderID)
+		if err != nil {
+			return nil, err
+		}
+		if delivered {
+			err := u.orderRepo.UpdateOrderStatus(shipment.UserID, shipment.OrderID, entity.OrderPaid, entity.OrderFulfilled)
+			if err != nil && err != utils.ErrInvalidStatus {
+				return nil, err
+			}
+			event = entity.OrderFulfilled
+		}
+	}
+
+	order, err := u.orderRepo.GetOrder(shipment.UserID, shipment.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := publishStoreOrders(u.WriteKafkaMessage, order, event); err != nil {
+		log.Printf("shipment %d: gagal kirim event order toko: %v", shipment.ID, err)
+	}
+
+	response := toShipmentDTO(shipment)
+	return &response, nil
+}
@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"fmt"
+	"service_cart/dto"
+	"time"
+)
+
+// splitStoreOrders memecah order menjadi potongan per toko, urut kemunculan toko di item
+func splitStoreOrders(order *dto.Order, event string) ([]uint, map[uint]*dto.StoreOrderEventKafka) {
+	events := make(map[uint]*dto.StoreOrderEventKafka)
+	var storeIds []uint
+	now := time.Now()
+	for _, item := range order.Items {
+		evt, ok := events[item.StoreID]
+		if !ok {
+			evt = &dto.StoreOrderEventKafka{
+				Event:      event,
+				OrderID:    order.ID,
+				StoreID:    item.StoreID,
+				BuyerID:    order.UserID,
+				Status:     order.Status,
+				OrderedAt:  order.CreatedAt,
+				OccurredAt: now,
+			}
+			events[item.StoreID] = evt
+			storeIds = append(storeIds, item.StoreID)
+		}
+
+		evt.Items = append(evt.Items, dto.StoreOrderItemKafka{
+			OrderItemID:    item.ID,
+			ProductID:      item.ProductID,
+			ProductName:    item.ProductName,
+			UnitPrice:      item.UnitPrice,
+			Quantity:       item.Quantity,
+			Subtotal:       item.Subtotal,
+			DiscountAmount: item.DiscountAmount,
+			TaxAmount:      item.TaxAmount,
+		})
+		evt.Subtotal += item.Subtotal
+		evt.DiscountAmount += item.DiscountAmount
+		evt.TaxAmount += item.TaxAmount
+	}
+
+	for i := range order.Shipments {
+		if evt, ok := events[order.Shipments[i].StoreID]; ok {
+			evt.Shipment = &order.Shipments[i]
+		}
+	}
+
+	return storeIds, events
+}
+
+// publishStoreOrders mengirim snapshot order per toko ke read model order seller
+// di service store. Semua event satu order memakai key yang sama.
+func publishStoreOrders(write func(topic string, key string, payload interface{}) error, order *dto.Order, event string) error {
+	storeIds, events := splitStoreOrders(order, event)
+	key := fmt.Sprintf("order-%d", order.ID)
+	for _, storeId := range storeIds {
+		if err := write("store-order-event", key, events[storeId]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
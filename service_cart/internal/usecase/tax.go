@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"service_cart/dto"
+	"service_cart/entity"
+	"service_cart/helper/tax"
+	"service_cart/helper/utils"
+	"service_cart/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/sony/gobreaker"
+)
+
+// wilayah default toko di service store
+const defaultTaxRegion = "id"
+
+type TaxUsecase interface {
+	SaveRate(req *dto.TaxRateReq) (*dto.TaxRate, error)
+	GetRates(region string) ([]dto.TaxRate, error)
+	DeleteRate(userId, id uint) error
+
+	//checkout
+	ApplyTaxes(items []entity.OrderItem, discounts []entity.OrderDiscount) (total int64, exclusive int64, err error)
+
+	WriteKafkaMessage(topic string, key string, payload interface{}) error
+}
+
+type taxUsecase struct {
+	taxRepo      repository.TaxRepo
+	cartRepo     repository.CartRepo
+	resolver     tax.Resolver
+	kafka        map[string]*kafka.Writer
+	writeBreaker *gobreaker.CircuitBreaker
+}
+
+func NewTaxUsecase(taxRepo repository.TaxRepo, cartRepo repository.CartRepo, resolver tax.Resolver, kafka map[string]*kafka.Writer) TaxUsecase {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "TaxProducerBreaker",
+		MaxRequests: 5,
+		Interval:    30 * time.Second,
+		Timeout:     5 * time.Second,
+		OnStateChange: func(name string, from, to gobreaker.State) {
+			log.Printf("[Circuit Breaker: %s] status berubah dari %s ➜ %s\n", name, from.String(), to.String())
+		},
+	})
+	return &taxUsecase{taxRepo, cartRepo, resolver, kafka, cb}
+}
+
+func (u *taxUsecase) WriteKafkaMessage(topic string, key string, payload interface{}) error {
+	writer, ok := u.kafka[topic]
+	if !ok {
+		return utils.ErrNoTopic
+	}
+
+	value, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	msg := kafka.Message{
+		Key:   []byte(key),
+		Value: value,
+	}
+
+	_, err = u.writeBreaker.Execute(func() (interface{}, error) {
+		return nil, writer.WriteMessages(context.Background(), msg)
+	})
+
+	if err != nil {
+		return fmt.Errorf("kafka write failed or circuit open: %w", err)
+	}
+
+	return nil
+}
+
+func (u *taxUsecase) SaveRate(req *dto.TaxRateReq) (*dto.TaxRate, error) {
+	if !isPlatformAdmin(req.UserID) {
+		return nil, utils.ErrNotAdmin
+	}
+
+	return u.taxRepo.SaveRate(req)
+}
+
+func (u *taxUsecase) GetRates(region string) ([]dto.TaxRate, error) {
+	return u.taxRepo.GetRates(region)
+}
+
+func (u *taxUsecase) DeleteRate(userId, id uint) error {
+	if !isPlatformAdmin(userId) {
+		return utils.ErrNotAdmin
+	}
+
+	return u.taxRepo.DeleteRate(id)
+}
+
+// allocateDiscounts membagi tiap diskon ke baris yang kena, sebanding dengan subtotal.
+// Kupon toko hanya ke barang toko itu, kupon platform ke semua barang. Sisa
+// pembulatan masuk ke baris terakhir supaya jumlahnya sama persis dengan diskon.
+func allocateDiscounts(items []entity.OrderItem, discounts []entity.OrderDiscount) {
+	for i := range items {
+		items[i].DiscountAmount = 0
+	}
+
+	for _, discount := range discounts {
+		var base int64
+		last := -1
+		for i := range items {
+			if discount.StoreID == nil || *discount.StoreID == items[i].StoreID {
+				base += items[i].Subtotal
+				last = i
+			}
+		}
+		if base == 0 {
+			continue
+		}
+
+		var given int64
+		for i := range items {
+			if discount.StoreID != nil && *discount.StoreID != items[i].StoreID {
+				continue
+			}
+
+			share := discount.Amount * items[i].Subtotal / base
+			if i == last {
+				share = discount.Amount - given
+			}
+			items[i].DiscountAmount += share
+			given += share
+		}
+	}
+}
+
+func (u *taxUsecase) storeRegions(storeIds []uint) (map[uint]string, error) {
+	corrId := uuid.NewString()
+	if err := u.WriteKafkaMessage("stores-detail-request", corrId, map[string]interface{}{
+		"correlation_id": corrId,
+		"store_ids":      storeIds,
+	}); err != nil {
+		return nil, utils.ErrFailedKafkaWrite
+	}
+
+	var stores []dto.StoreDetailKafka
+	if err := u.cartRepo.WaitForResponse(corrId, &stores); err != nil {
+		return nil, err
+	}
+
+	regions := make(map[uint]string, len(stores))
+	for _, store := range stores {
+		regions[store.ID] = store.Region
+	}
+	return regions, nil
+}
+
+// ApplyTaxes mengisi diskon dan pajak tiap baris order. Dasar pajak adalah subtotal
+// setelah diskon, ongkir tidak dikenai pajak. exclusive adalah pajak yang harus
+// ditambahkan ke total order, pajak inclusive sudah ada di dalam harga.
+func (u *taxUsecase) ApplyTaxes(items []entity.OrderItem, discounts []entity.OrderDiscount) (int64, int64, error) {
+	allocateDiscounts(items, discounts)
+
+	var storeIds []uint
+	seen := make(map[uint]bool)
+	for _, item := range items {
+		if !seen[item.StoreID] {
+			seen[item.StoreID] = true
+			storeIds = append(storeIds, item.StoreID)
+		}
+	}
+
+	regions, err := u.storeRegions(storeIds)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var total, exclusive int64
+	rules := make(map[string]*tax.Rule)
+	for i := range items {
+		item := &items[i]
+
+		region := regions[item.StoreID]
+		if region == "" {
+			region = defaultTaxRegion
+		}
+
+		key := region + "|" + item.Category
+		rule, ok := rules[key]
+		if !ok {
+			rule, err = u.resolver.Resolve(region, item.Category)
+			if err != nil {
+				return 0, 0, err
+			}
+			rules[key] = rule
+		}
+
+		item.TaxRegion = region
+		if rule == nil {
+			continue
+		}
+
+		line := rule.Apply(item.Subtotal - item.DiscountAmount)
+		item.TaxName = line.Name
+		item.TaxRate = line.Rate
+		item.TaxMode = line.Mode
+		item.TaxAmount = line.Amount
+
+		total += line.Amount
+		if line.Mode == tax.ModeExclusive {
+			exclusive += line.Amount
+		}
+	}
+
+	return total, exclusive, nil
+}
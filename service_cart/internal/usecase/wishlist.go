@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"service_cart/dto"
+	"service_cart/entity"
+	"service_cart/helper/utils"
+	"service_cart/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/sony/gobreaker"
+)
+
+type WishlistUsecase interface {
+	CreateWishlist(userId uint, name string) (*dto.Wishlist, error)
+	GetMyWishlists(userId uint) ([]dto.Wishlist, error)
+	DeleteWishlist(userId, id uint) error
+
+	//item
+	AddItem(userId, wishlistId, productId uint) error
+	RemoveItem(userId, wishlistId, productId uint) error
+	MoveToCart(req *dto.CreateCartItemReq, wishlistId uint) error
+	MoveFromCart(userId, cartItemId, wishlistId uint) error
+
+	WriteKafkaMessage(topic string, key string, payload interface{}) error
+}
+
+type wishlistUsecase struct {
+	wishlistRepo repository.WishlistRepo
+	cartRepo     repository.CartRepo
+	cartUsecase  CartUsecase
+	kafka        map[string]*kafka.Writer
+	writeBreaker *gobreaker.CircuitBreaker
+}
+
+func NewWishlistUsecase(wishlistRepo repository.WishlistRepo, cartRepo repository.CartRepo, cartUsecase CartUsecase, kafka map[string]*kafka.Writer) WishlistUsecase {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "WishlistProducerBreaker",
+		MaxRequests: 5,
+		Interval:    30 * time.Second,
+		Timeout:     5 * time.Second,
+		OnStateChange: func(name string, from, to gobreaker.State) {
+			log.Printf("[Circuit Breaker: %s] status berubah dari %s ➜ %s\n", name, from.String(), to.String())
+		},
+	})
+	return &wishlistUsecase{wishlistRepo, cartRepo, cartUsecase, kafka, cb}
+}
+
+func toWishlistDTO(w *entity.Wishlist) dto.Wishlist {
+	items := make([]dto.WishlistItem, 0, len(w.Items))
+	for _, item := range w.Items {
+		items = append(items, dto.WishlistItem{
+			ProductID: item.ProductID,
+			CreatedAt: item.CreatedAt,
+		})
+	}
+
+	return dto.Wishlist{
+		ID:        w.ID,
+		Name:      w.Name,
+		Items:     items,
+		CreatedAt: w.CreatedAt,
+	}
+}
+
+func (u *wishlistUsecase) WriteKafkaMessage(topic string, key string, payload interface{}) error {
+	writer, ok := u.kafka[topic]
+	if !ok {
+		return utils.ErrNoTopic
+	}
+
+	value, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	msg := kafka.Message{
+		Key:   []byte(key),
+		Value: value,
+	}
+
+	_, err = u.writeBreaker.Execute(func() (interface{}, error) {
+		return nil, writer.WriteMessages(context.Background(), msg)
+	})
+
+	if err != nil {
+		return fmt.Errorf("kafka write failed or circuit open: %w", err)
+	}
+
+	return nil
+}
+
+func (u *wishlistUsecase) CreateWishlist(userId uint, name string) (*dto.Wishlist, error) {
+	wishlist := entity.Wishlist{
+		UserID: userId,
+		Name:   strings.TrimSpace(name),
+	}
+	if err := u.wishlistRepo.CreateWishlist(&wishlist); err != nil {
+		return nil, err
+	}
+
+	response := toWishlistDTO(&wishlist)
+	return &response, nil
+}
+
+func (u *wishlistUsecase) GetMyWishlists(userId uint) ([]dto.Wishlist, error) {
+	wishlists, err := u.wishlistRepo.GetMyWishlists(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.Wishlist, 0, len(wishlists))
+	for i := range wishlists {
+		result = append(result, toWishlistDTO(&wishlists[i]))
+	}
+
+	return result, nil
+}
+
+func (u *wishlistUsecase) DeleteWishlist(userId, id uint) error {
+	return u.wishlistRepo.DeleteWishlist(userId, id)
+}
+
+// AddItem hanya mengecek product masih ada, stok baru dicek saat dipindah ke cart
+func (u *wishlistUsecase) AddItem(userId, wishlistId, productId uint) error {
+	corrId := uuid.NewString()
+
+	payload := map[string]interface{}{
+		"correlation_id": corrId,
+		"product_id":     productId,
+	}
+	if err := u.WriteKafkaMessage("product-validation-request", corrId, payload); err != nil {
+		return utils.ErrFailedKafkaWrite
+	}
+
+	var validation dto.ValidationProductKafka
+	if err := u.cartRepo.WaitForResponse(corrId, &validation); err != nil {
+		return err
+	}
+
+	if validation.Deleted {
+		return utils.ErrProductDeleted
+	}
+
+	return u.wishlistRepo.AddItem(userId, wishlistId, productId)
+}
+
+func (u *wishlistUsecase) RemoveItem(userId, wishlistId, productId uint) error {
+	return u.wishlistRepo.RemoveItem(userId, wishlistId, productId)
+}
+
+// MoveToCart lewat CreateCartItem supaya validasi stok dan penggabungan baris cart sama
+func (u *wishlistUsecase) MoveToCart(req *dto.CreateCartItemReq, wishlistId uint) error {
+	wishlist, err := u.wishlistRepo.GetWishlist(req.UserID, wishlistId)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, item := range wishlist.Items {
+		if item.ProductID == req.ProductID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return utils.ErrWishlistItemNotFound
+	}
+
+	if err := u.cartUsecase.CreateCartItem(req); err != nil {
+		return err
+	}
+
+	return u.wishlistRepo.RemoveItem(req.UserID, wishlistId, req.ProductID)
+}
+
+func (u *wishlistUsecase) MoveFromCart(userId, cartItemId, wishlistId uint) error {
+	return u.wishlistRepo.MoveCartItemToWishlist(userId, cartItemId, wishlistId)
+}
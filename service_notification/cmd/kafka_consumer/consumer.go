@@ -3,20 +3,275 @@ package kafkaconsumer
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"service_notification/dto"
+	"service_notification/helper/channel"
+	"service_notification/helper/render"
 	"service_notification/helper/utils"
+	"service_notification/internal/usecase"
 	"time"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/segmentio/kafka-go"
 	"github.com/sony/gobreaker"
 )
 
-func ProductResponseConsumer(breaker *gobreaker.CircuitBreaker) {
+// jarak minimal antar reminder cart terbengkalai untuk user yang sama
+func reminderInterval() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv("CART_REMINDER_INTERVAL"))
+	if err != nil {
+		return 72 * time.Hour
+	}
+	return interval
+}
+
+// resolveLocale mendahulukan bahasa di payload, lalu pilihan user yang tersimpan
+func resolveLocale(rdb *redis.Client, req *dto.NotificationRequest) string {
+	if req.Locale != "" {
+		return req.Locale
+	}
+	locale, err := utils.GetLocale(rdb, req.Email)
+	if err != nil {
+		fmt.Println("get locale failed:", err)
+	}
+	if locale == "" {
+		return render.DefaultLocale
+	}
+	return locale
+}
+
+// recipient penerima untuk kunci dedup, email kalau ada, selain itu nomor hp
+func recipient(req *dto.NotificationRequest) string {
+	if req.Email != "" {
+		return req.Email
+	}
+	return req.Phone
+}
+
+// undelivered membuang channel yang sudah berhasil mengirim notifikasi ini.
+// Kalau store dedup tidak bisa dibaca, channel tetap dikirim.
+func undelivered(delivery usecase.DeliveryUsecase, key *dto.DeliveryKey, channels []channel.Notifier) []channel.Notifier {
+	if key.CorrelationID == "" {
+		return channels
+	}
+
+	result := make([]channel.Notifier, 0, len(channels))
+	for _, ch := range channels {
+		sent, err := delivery.Delivered(context.Background(), key, ch.Name())
+		if err != nil {
+			fmt.Println("cek dedup gagal:", err)
+		}
+		if !sent {
+			result = append(result, ch)
+		}
+	}
+	return result
+}
+
+// record mencatat status kirim, gagal mencatat hanya masuk log
+func record(delivery usecase.DeliveryUsecase, key *dto.DeliveryKey, name, status string, attempts int, err error) {
+	if key.CorrelationID == "" {
+		return
+	}
+	if errRecord := delivery.Record(context.Background(), key, name, status, attempts, err); errRecord != nil {
+		fmt.Printf("catat status %s %s gagal: %v\n", key.CorrelationID, name, errRecord)
+	}
+}
+
+// sendChannels mengirim ke setiap channel dan mengembalikan channel yang gagal.
+// Satu channel gagal tidak menghentikan channel lain.
+func sendChannels(delivery usecase.DeliveryUsecase, key *dto.DeliveryKey, attempts int, channels []channel.Notifier, notification *channel.Message, lastErr map[string]error) ([]channel.Notifier, error) {
+	var failed []channel.Notifier
+	var errs []error
+	for _, ch := range channels {
+		err := ch.Send(context.Background(), notification)
+		if err != nil && err != channel.ErrNoRecipient {
+			failed = append(failed, ch)
+			errs = append(errs, fmt.Errorf("%s: %w", ch.Name(), err))
+			lastErr[ch.Name()] = err
+			record(delivery, key, ch.Name(), dto.DeliveryFailed, attempts, err)
+			continue
+		}
+		if err == nil {
+			record(delivery, key, ch.Name(), dto.DeliverySent, attempts, nil)
+		}
+	}
+	return failed, errors.Join(errs...)
+}
+
+// deadLetter memindah pesan ke dlq. Offset baru di-commit setelah pesan masuk
+// dlq, jadi penulisan diulang terus dengan jeda sampai berhasil.
+func deadLetter(dlq *kafka.Writer, retry utils.RetryConfig, msg kafka.Message, reason string, err error, attempts int, channels []channel.Notifier) {
+	names := make([]string, 0, len(channels))
+	for _, ch := range channels {
+		names = append(names, ch.Name())
+	}
+
+	dead := utils.DeadLetter(msg, reason, err, attempts, names)
+	for try := 1; ; try++ {
+		errWrite := dlq.WriteMessages(context.Background(), dead)
+		if errWrite == nil {
+			break
+		}
+		delay := retry.Backoff(try)
+		fmt.Printf("notifikasi offset %d gagal tulis dlq (percobaan %d), dicoba lagi dalam %s: %v\n", msg.Offset, try, delay, errWrite)
+		time.Sleep(delay)
+	}
+	fmt.Printf("notifikasi offset %d dipindah ke dlq (%s, %d percobaan): %v\n", msg.Offset, reason, attempts, err)
+}
+
+func ProductResponseConsumer(rdb *redis.Client, renderer *render.Renderer, router *channel.Router, delivery usecase.DeliveryUsecase, breaker *gobreaker.CircuitBreaker, dlq *kafka.Writer) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   utils.NotificationTopic,
+		GroupID: "notification-service",
+	})
+	retry := utils.RetryConfigFromEnv()
+
+	// process selesai kalau notifikasi terkirim, sengaja dilewati, atau sudah
+	// masuk dlq. Offset di-commit setelahnya, jadi restart di tengah percobaan
+	// ulang membuat pesan dibaca lagi, bukan hilang.
+	process := func(msg kafka.Message) {
+		var payload dto.NotificationRequest
+		if err := json.Unmarshal(msg.Value, &payload); err != nil {
+			fmt.Println("error unmarshaling:", err)
+			deadLetter(dlq, retry, msg, utils.ReasonDecode, err, 0, nil)
+			return
+		}
+
+		// jenis yang tidak terdaftar diabaikan
+		message, ok := render.NewMessage(payload.Service, payload.Action)
+		if !ok {
+			return
+		}
+		if err := payload.DecodeMessage(message); err != nil {
+			fmt.Println("error decoding message:", err)
+			deadLetter(dlq, retry, msg, utils.ReasonDecode, err, 0, nil)
+			return
+		}
+
+		// pesan hasil replay hanya dikirim ke channel yang dulu gagal
+		channels := channel.Only(router.Route(payload.Service, payload.Action), utils.HeaderValue(msg.Headers, utils.HeaderChannels))
+		if len(channels) == 0 {
+			return
+		}
+
+		// kafka at-least-once, channel yang sudah terkirim untuk notifikasi yang
+		// sama tidak dikirimi lagi
+		key := &dto.DeliveryKey{
+			CorrelationID: payload.CorrelationID,
+			Service:       payload.Service,
+			Action:        payload.Action,
+			Recipient:     recipient(&payload),
+		}
+		channels = undelivered(delivery, key, channels)
+		if len(channels) == 0 {
+			fmt.Printf("notifikasi %s %s.%s sudah terkirim, dilewati\n", payload.CorrelationID, payload.Service, payload.Action)
+			return
+		}
+
+		mail, err := renderer.Render(resolveLocale(rdb, &payload), payload.Service, payload.Action, render.Data{
+			ActionID:      payload.CorrelationID,
+			Now:           time.Now(),
+			Message:       message,
+			HasAttachment: payload.Attachment != nil,
+		})
+		if err != nil {
+			deadLetter(dlq, retry, msg, utils.ReasonRender, err, 0, nil)
+			return
+		}
+
+		notification := &channel.Message{
+			ID:        payload.CorrelationID,
+			Service:   payload.Service,
+			Action:    payload.Action,
+			Email:     payload.Email,
+			Phone:     payload.Phone,
+			Subject:   mail.Subject,
+			HTML:      mail.HTML,
+			Text:      mail.Text,
+			Data:      message,
+			CreatedAt: time.Now(),
+		}
+		// invoice pdf dari service cart, email tetap dikirim kalau tidak ada
+		if payload.Attachment != nil {
+			notification.Attachments = append(notification.Attachments, *payload.Attachment)
+		}
+
+		// reminder cart dibatasi per user, jatahnya dibuka lagi kalau akhirnya gagal
+		reminder := payload.Service == "cart" && payload.Action == "abandoned"
+		acquired := false
+		pending := channels
+		lastErr := make(map[string]error)
+
+		// percobaan ulang hanya ke channel yang masih gagal, jadi channel yang
+		// sudah berhasil tidak menerima notifikasi dobel
+		attempts := 0
+		for {
+			attempts++
+			_, err = breaker.Execute(func() (interface{}, error) {
+				if reminder && !acquired {
+					allowed, err := utils.AllowReminder(rdb, "cart", payload.Email, reminderInterval())
+					if err != nil {
+						return nil, err
+					}
+					if !allowed {
+						pending = nil
+						return nil, nil
+					}
+					acquired = true
+				}
+
+				failed, err := sendChannels(delivery, key, attempts, pending, notification, lastErr)
+				pending = failed
+				return nil, err
+			})
+			if err == nil || attempts >= retry.MaxAttempts {
+				break
+			}
+
+			delay := retry.Backoff(attempts)
+			fmt.Printf("notifikasi %s gagal (percobaan %d), dicoba lagi dalam %s: %v\n", payload.CorrelationID, attempts, delay, err)
+			time.Sleep(delay)
+		}
+
+		if err != nil {
+			if acquired {
+				utils.ReleaseReminder(rdb, "cart", payload.Email)
+			}
+			for _, ch := range pending {
+				cause := lastErr[ch.Name()]
+				if cause == nil {
+					cause = err
+				}
+				record(delivery, key, ch.Name(), dto.DeliveryDeadLetter, attempts, cause)
+			}
+			deadLetter(dlq, retry, msg, utils.ReasonDelivery, err, attempts, pending)
+		}
+	}
+
+	go func() {
+		for {
+			msg, err := r.FetchMessage(context.Background())
+			if err != nil {
+				fmt.Println("error reading message:", err)
+				continue
+			}
+
+			process(msg)
+			if err := r.CommitMessages(context.Background(), msg); err != nil {
+				fmt.Println("error commit message:", err)
+			}
+		}
+	}()
+}
+
+func UserLocaleConsumer(rdb *redis.Client, breaker *gobreaker.CircuitBreaker) {
 	r := kafka.NewReader(kafka.ReaderConfig{
 		Brokers: []string{os.Getenv("KAFKA_BROKER")},
-		Topic:   "notification-request",
+		Topic:   "user-locale-updated",
 		GroupID: "notification-service",
 	})
 
@@ -28,136 +283,22 @@ func ProductResponseConsumer(breaker *gobreaker.CircuitBreaker) {
 				continue
 			}
 
-			var payload map[string]interface{}
+			var payload dto.UserLocale
 			if err := json.Unmarshal(msg.Value, &payload); err != nil {
 				fmt.Println("error unmarshaling:", err)
 				continue
 			}
-
-			corrID := payload["correlation_id"].(string)
-			service := payload["service"].(string)
-			action := payload["action"].(string)
-			email := payload["email"].(string)
-			message := payload["message"]
+			if payload.Email == "" || payload.Locale == "" {
+				continue
+			}
 
 			_, errBreaker := breaker.Execute(func() (interface{}, error) {
-				if service == "user" {
-					if action == "register" {
-						html := fmt.Sprintf("<h1>ActionId:%s <br> Hello %s selamat datang di shop!</h1>", corrID, message.(string))
-						send := dto.SendEmail{
-							ToEmail:  email,
-							Header:   service,
-							ActionId: corrID,
-							Desc:     html,
-						}
-						return nil, utils.SendEmail(&send)
-					}
-				} else if service == "store" {
-					if action == "create" {
-						var store dto.Store
-						err := json.Unmarshal([]byte(message.(string)), &store)
-						if err != nil {
-							fmt.Println(err)
-						}
-						html := fmt.Sprintf("<h1>ActionId:%s <br>anda berhasil membuat store <br> id:%d <br> name:%s <br> admin created:%d <br>created by :%s</h1>", corrID, store.ID, store.Name, store.AdminID, store.CreatedAt.Format(time.RFC1123))
-						send := dto.SendEmail{
-							ToEmail:  email,
-							Header:   service,
-							ActionId: corrID,
-							Desc:     html,
-						}
-						return nil, utils.SendEmail(&send)
-
-					} else if action == "update" {
-						var store dto.Store
-						err := json.Unmarshal([]byte(message.(string)), &store)
-						if err != nil {
-							fmt.Println(err)
-						}
-						html := fmt.Sprintf("<h1>ActionId:%s <br>anda berhasil mengupdate store <br> id:%d <br> name:%s </h1>", corrID, store.ID, store.Name)
-						send := dto.SendEmail{
-							ToEmail:  email,
-							Header:   service,
-							ActionId: corrID,
-							Desc:     html,
-						}
-						return nil, utils.SendEmail(&send)
-					} else if action == "delete" {
-						html := fmt.Sprintf("<h1>ActionId:%s <br>anda berhasil menghapus store dengan id %v </h1>", corrID, message.(uint))
-						send := dto.SendEmail{
-							ToEmail:  email,
-							Header:   service,
-							ActionId: corrID,
-							Desc:     html,
-						}
-						return nil, utils.SendEmail(&send)
-					}
-				} else if service == "product" {
-					if action == "create" {
-						var product dto.Product
-						err := json.Unmarshal([]byte(message.(string)), &product)
-						if err != nil {
-							fmt.Println(err)
-						}
-						html := fmt.Sprintf("<h1>ActionId:%s <br>anda berhasil menambahkan product <br> id:%d <br> name:%s <br> stock:%d <br>created by :%s</h1>", corrID, product.ID, product.Name, product.Stock, product.CreatedAt.Format(time.RFC1123))
-						send := dto.SendEmail{
-							ToEmail:  email,
-							Header:   service,
-							ActionId: corrID,
-							Desc:     html,
-						}
-						return nil, utils.SendEmail(&send)
-
-					} else if action == "update" {
-						var product dto.Product
-						err := json.Unmarshal([]byte(message.(string)), &product)
-						if err != nil {
-							fmt.Println(err)
-						}
-						html := fmt.Sprintf("<h1>ActionId:%s <br>anda berhasil mengupdate product <br> id:%d <br> name:%s <br> stock:%d</h1>", corrID, product.ID, product.Name, product.Stock)
-						send := dto.SendEmail{
-							ToEmail:  email,
-							Header:   service,
-							ActionId: corrID,
-							Desc:     html,
-						}
-						return nil, utils.SendEmail(&send)
-					} else if action == "delete" {
-						html := fmt.Sprintf("<h1>ActionId:%s <br>anda berhasil menghapus product dengan id %v </h1>", corrID, message.(uint))
-						send := dto.SendEmail{
-							ToEmail:  email,
-							Header:   service,
-							ActionId: corrID,
-							Desc:     html,
-						}
-						return nil, utils.SendEmail(&send)
-					}
-				} else if service == "cart" {
-					if action == "paid" {
-						var paid dto.UpdatePaidCartItemReq
-						err := json.Unmarshal([]byte(message.(string)), &paid)
-						if err != nil {
-							fmt.Println(err)
-						}
-						html := fmt.Sprintf("<h1>ActionId:%s <br>anda berhasil membeli product <br> cart id:%d <br> product_id:%d <br> total:%d <br>buy date :%s</h1>", corrID, paid.ID, paid.ProductID, paid.PurchaseAmount, time.Now().Format(time.RFC1123))
-						send := dto.SendEmail{
-							ToEmail:  email,
-							Header:   service,
-							ActionId: corrID,
-							Desc:     html,
-						}
-						return nil, utils.SendEmail(&send)
-					}
-				}
-
-				return nil, nil
+				return nil, utils.SaveLocale(rdb, payload.Email, payload.Locale)
 			})
-
 			if errBreaker != nil {
-				fmt.Printf("write response failed or breaker open:%v", errBreaker)
+				fmt.Println("save locale failed or breaker open:", errBreaker)
 				continue
 			}
-
 		}
 	}()
 }
@@ -3,10 +3,19 @@ package main
 import (
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	kafkaconsumer "service_notification/cmd/kafka_consumer"
+	"service_notification/cmd/route"
+	"service_notification/helper/channel"
+	"service_notification/helper/render"
+	"service_notification/helper/utils"
+	"service_notification/internal/handler"
+	"service_notification/internal/repository"
+	"service_notification/internal/usecase"
 	"time"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/segmentio/kafka-go"
 	"github.com/sony/gobreaker"
 )
@@ -36,8 +45,64 @@ func main() {
 			log.Printf("[Circuit Breaker: %s] status berubah dari %s ➜ %s\n", name, from.String(), to.String())
 		},
 	})
-	go kafkaconsumer.ProductResponseConsumer(cb)
-	fmt.Println("Service notification berjalan")
+	rdb := redis.NewClient(&redis.Options{
+		Addr: os.Getenv("REDIS_HOST"),
+	})
+
+	// template rusak atau belum lengkap ketahuan saat start, bukan saat email dikirim
+	renderer, err := render.New()
+	if err != nil {
+		log.Fatalf("template email: %v", err)
+	}
+
+	inboxRepo := repository.NewInboxRepo(rdb)
+	inboxUC := usecase.NewInboxUsecase(inboxRepo)
+	inboxHandler := handler.NewInboxHandler(inboxUC)
+
+	// catatan kirim dipakai untuk dedup dan dilihat tim support
+	dedupTTL, err := time.ParseDuration(os.Getenv("NOTIFY_DEDUP_TTL"))
+	if err != nil {
+		dedupTTL = 7 * 24 * time.Hour
+	}
+	deliveryRepo := repository.NewDeliveryRepo(rdb, dedupTTL)
+	deliveryUC := usecase.NewDeliveryUsecase(deliveryRepo)
+	deliveryHandler := handler.NewDeliveryHandler(deliveryUC)
+
+	email, err := channel.NewSMTP(channel.SMTPConfigFromEnv())
+	if err != nil {
+		log.Fatalf("smtp: %v", err)
+	}
+
+	// webhook hanya aktif kalau url diisi, sms memakai stub log sampai ada vendor
+	channels := []channel.Notifier{
+		email,
+		channel.NewSMS(channel.LogSMSProvider{}),
+		channel.NewInApp(inboxUC),
+	}
+	if url := os.Getenv("WEBHOOK_URL"); url != "" {
+		channels = append(channels, channel.NewWebhook(url, os.Getenv("WEBHOOK_SECRET")))
+	}
+	router, err := channel.NewRouter(os.Getenv("NOTIFY_ROUTES"), channels...)
+	if err != nil {
+		log.Fatalf("routing notifikasi: %v", err)
+	}
+
+	// pesan yang tetap gagal setelah semua percobaan, dikirim ulang lewat cmd/replay
+	dlq := kafka.NewWriter(kafka.WriterConfig{
+		Brokers:  brokers,
+		Topic:    utils.DeadLetterTopic,
+		Balancer: &kafka.Hash{},
+	})
+
+	go kafkaconsumer.ProductResponseConsumer(rdb, renderer, router, deliveryUC, cb, dlq)
+	go kafkaconsumer.UserLocaleConsumer(rdb, cb)
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "3004"
+	}
 
-	select {}
+	r := route.SetupRoute(inboxHandler, deliveryHandler)
+	fmt.Printf("service notification berjalan pada port:%s", port)
+	http.ListenAndServe(":"+port, r)
 }
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"service_notification/helper/utils"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Replay mengirim ulang pesan di notification-request.dlq ke notification-request
+// setelah penyebab gagalnya diperbaiki. Posisi baca disimpan di consumer group,
+// jadi pesan yang sudah dikirim ulang tidak diulang di run berikutnya.
+//
+//	./replay                  kirim ulang semua pesan dlq
+//	./replay -limit 10        kirim ulang paling banyak 10 pesan
+//	./replay -dry-run         tampilkan isi dlq tanpa mengirim
+func main() {
+	limit := flag.Int("limit", 0, "jumlah maksimal pesan yang dikirim ulang, 0 berarti semua")
+	dryRun := flag.Bool("dry-run", false, "hanya tampilkan pesan, tidak dikirim dan posisi tidak disimpan")
+	idle := flag.Duration("idle", 10*time.Second, "berhenti kalau tidak ada pesan baru selama durasi ini")
+	flag.Parse()
+
+	brokers := []string{os.Getenv("KAFKA_BROKER")}
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: brokers,
+		Topic:   utils.DeadLetterTopic,
+		GroupID: "notification-dlq-replay",
+	})
+	defer reader.Close()
+
+	writer := kafka.NewWriter(kafka.WriterConfig{
+		Brokers:  brokers,
+		Topic:    utils.NotificationTopic,
+		Balancer: &kafka.Hash{},
+	})
+	defer writer.Close()
+
+	// pesan yang masuk dlq lagi selama replay berjalan tidak ikut diproses,
+	// supaya payload yang memang rusak tidak berputar terus
+	startedAt := time.Now()
+	replayed := 0
+	for *limit == 0 || replayed < *limit {
+		ctx, cancel := context.WithTimeout(context.Background(), *idle)
+		msg, err := reader.FetchMessage(ctx)
+		cancel()
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				break
+			}
+			log.Fatalf("baca dlq: %v", err)
+		}
+		if msg.Time.After(startedAt) {
+			continue
+		}
+
+		fmt.Printf("partition %d offset %d: %s, %s percobaan, channel %q: %s\n",
+			msg.Partition, msg.Offset,
+			utils.HeaderValue(msg.Headers, utils.HeaderDLQReason),
+			utils.HeaderValue(msg.Headers, utils.HeaderDLQAttempts),
+			utils.HeaderValue(msg.Headers, utils.HeaderDLQChannels),
+			utils.HeaderValue(msg.Headers, utils.HeaderDLQError),
+		)
+		if *dryRun {
+			replayed++
+			continue
+		}
+
+		replay := kafka.Message{Key: msg.Key, Value: msg.Value}
+		if channels := utils.HeaderValue(msg.Headers, utils.HeaderDLQChannels); channels != "" {
+			replay.Headers = []kafka.Header{{Key: utils.HeaderChannels, Value: []byte(channels)}}
+		}
+		if err := writer.WriteMessages(context.Background(), replay); err != nil {
+			log.Fatalf("kirim ulang offset %d: %v", msg.Offset, err)
+		}
+		if err := reader.CommitMessages(context.Background(), msg); err != nil {
+			log.Fatalf("commit offset %d: %v", msg.Offset, err)
+		}
+		replayed++
+	}
+
+	if *dryRun {
+		log.Printf("%d pesan dlq ditampilkan", replayed)
+		return
+	}
+	log.Printf("%d pesan dlq dikirim ulang", replayed)
+}
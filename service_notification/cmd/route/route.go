@@ -0,0 +1,34 @@
+package route
+
+import (
+	"net/http"
+	"service_notification/helper/middleware"
+	"service_notification/internal/handler"
+
+	"github.com/gorilla/mux"
+)
+
+func SetupRoute(inbox *handler.InboxHandler, delivery *handler.DeliveryHandler) *mux.Router {
+	r := mux.NewRouter()
+
+	// stream didaftarkan lebih dulu supaya tidak tertangkap prefix /inbox
+	streamM := r.PathPrefix("/inbox/stream").Subrouter()
+	streamM.Use(middleware.StreamAuthMiddleware)
+
+	streamM.HandleFunc("", inbox.Stream).Methods(http.MethodGet)
+
+	inboxM := r.PathPrefix("/inbox").Subrouter()
+	inboxM.Use(middleware.AuthMiddleware)
+
+	inboxM.HandleFunc("", inbox.GetInbox).Methods(http.MethodGet)
+	inboxM.HandleFunc("/unread-count", inbox.UnreadCount).Methods(http.MethodGet)
+	inboxM.HandleFunc("/read-all", inbox.MarkAllRead).Methods(http.MethodPut)
+	inboxM.HandleFunc("/{id}/read", inbox.MarkRead).Methods(http.MethodPut)
+
+	supportM := r.PathPrefix("/support").Subrouter()
+	supportM.Use(middleware.SupportMiddleware)
+
+	supportM.HandleFunc("/delivery/{correlationId}", delivery.GetStatus).Methods(http.MethodGet)
+
+	return r
+}
@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+// status pengiriman satu channel
+const (
+	DeliverySent       = "sent"
+	DeliveryFailed     = "failed"
+	DeliveryDeadLetter = "dead_letter"
+)
+
+// DeliveryKey identitas satu notifikasi. Satu correlation id bisa dipakai
+// beberapa pesan dengan action berbeda, jadi service dan action ikut jadi kunci.
+type DeliveryKey struct {
+	CorrelationID string
+	Service       string
+	Action        string
+	Recipient     string
+}
+
+type DeliveryAttempt struct {
+	Service   string    `json:"service"`
+	Action    string    `json:"action"`
+	Recipient string    `json:"recipient"`
+	Channel   string    `json:"channel"`
+	Status    string    `json:"status"`
+	Attempts  int       `json:"attempts"`
+	Error     string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type DeliveryStatus struct {
+	CorrelationID string            `json:"correlation_id"`
+	Deliveries    []DeliveryAttempt `json:"deliveries"`
+}
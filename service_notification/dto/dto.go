@@ -1,12 +1,51 @@
 package dto
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
 
-type SendEmail struct {
-	ToEmail  string
-	Header   string
-	ActionId string
-	Desc     string
+// NotificationRequest isi topic notification-request. Message berupa string json
+// untuk sebagian besar jenis, atau nilai langsung seperti id atau nama.
+type NotificationRequest struct {
+	CorrelationID string          `json:"correlation_id"`
+	Email         string          `json:"email"`
+	Phone         string          `json:"phone"`
+	Locale        string          `json:"locale"`
+	Service       string          `json:"service"`
+	Action        string          `json:"action"`
+	Message       json.RawMessage `json:"message"`
+	Attachment    *Attachment     `json:"attachment"`
+}
+
+// DecodeMessage mengisi out dari message, string json dibuka dulu sebelum didecode
+func (n *NotificationRequest) DecodeMessage(out interface{}) error {
+	if len(n.Message) == 0 {
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(n.Message, &text); err == nil {
+		if target, ok := out.(*string); ok {
+			*target = text
+			return nil
+		}
+		return json.Unmarshal([]byte(text), out)
+	}
+	return json.Unmarshal(n.Message, out)
+}
+
+type UserLocale struct {
+	Email  string `json:"email"`
+	Locale string `json:"locale"`
+}
+
+// content dikirim base64 di json, otomatis didecode ke []byte
+type Attachment struct {
+	Filename string `json:"filename"`
+	Content  []byte `json:"content"`
 }
 
 type Store struct {
@@ -24,8 +63,96 @@ type Product struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
-type UpdatePaidCartItemReq struct {
-	ID             uint `json:"cart_id"`
-	ProductID      uint `json:"product_id"`
-	PurchaseAmount int  `json:"purchase_amount"`
+type OrderItem struct {
+	ProductID   uint   `json:"product_id"`
+	StoreID     uint   `json:"store_id"`
+	ProductName string `json:"product_name"`
+	UnitPrice   int64  `json:"unit_price"`
+	Quantity    int    `json:"quantity"`
+	Subtotal    int64  `json:"subtotal"`
+}
+
+type OrderDiscount struct {
+	Code   string `json:"code"`
+	Amount int64  `json:"amount"`
+}
+
+type Order struct {
+	ID             uint            `json:"id"`
+	UserID         uint            `json:"user_id"`
+	Status         string          `json:"status"`
+	TotalAmount    int64           `json:"total_amount"`
+	DiscountAmount int64           `json:"discount_amount"`
+	Items          []OrderItem     `json:"items"`
+	Discounts      []OrderDiscount `json:"discounts"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// StoreOrder potongan order untuk satu toko, dikirim ke admin toko
+type StoreOrder struct {
+	OrderID        uint        `json:"order_id"`
+	StoreID        uint        `json:"store_id"`
+	Items          []OrderItem `json:"items"`
+	Subtotal       int64       `json:"subtotal"`
+	DiscountAmount int64       `json:"discount_amount"`
+	TaxAmount      int64       `json:"tax_amount"`
+	Shipment       *Shipment   `json:"shipment"`
+}
+
+type Shipment struct {
+	ID             uint   `json:"id"`
+	OrderID        uint   `json:"order_id"`
+	StoreID        uint   `json:"store_id"`
+	Method         string `json:"method"`
+	Status         string `json:"status"`
+	TrackingNumber string `json:"tracking_number"`
+	Recipient      string `json:"recipient"`
+	Street         string `json:"street"`
+	City           string `json:"city"`
+	Province       string `json:"province"`
+	PostalCode     string `json:"postal_code"`
+}
+
+func (s Shipment) Address() string {
+	return fmt.Sprintf("%s, %s, %s, %s %s", s.Recipient, s.Street, s.City, s.Province, s.PostalCode)
+}
+
+type Return struct {
+	ID            uint     `json:"id"`
+	OrderID       uint     `json:"order_id"`
+	StoreID       uint     `json:"store_id"`
+	ProductName   string   `json:"product_name"`
+	Quantity      int      `json:"quantity"`
+	Reason        string   `json:"reason"`
+	Photos        []string `json:"photos"`
+	RefundAmount  int64    `json:"refund_amount"`
+	SellerNote    string   `json:"seller_note"`
+	RefundID      string   `json:"refund_id"`
+	FailureReason string   `json:"failure_reason"`
+}
+
+type AbandonedCartItem struct {
+	ProductID      uint   `json:"product_id"`
+	ProductName    string `json:"product_name"`
+	PurchaseAmount int    `json:"purchase_amount"`
+	CurrentPrice   int64  `json:"current_price"`
+}
+
+// Name nama product, product yang sudah dihapus hanya punya id
+func (i AbandonedCartItem) Name() string {
+	if strings.TrimSpace(i.ProductName) == "" {
+		return fmt.Sprintf("product #%d", i.ProductID)
+	}
+	return i.ProductName
+}
+
+func (i AbandonedCartItem) Total() int64 {
+	return i.CurrentPrice * int64(i.PurchaseAmount)
+}
+
+type AbandonedCart struct {
+	UserID         uint                `json:"user_id"`
+	Items          []AbandonedCartItem `json:"items"`
+	Total          int64               `json:"total"`
+	LastActivityAt time.Time           `json:"last_activity_at"`
 }
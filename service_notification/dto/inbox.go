@@ -0,0 +1,39 @@
+package dto
+
+import "time"
+
+// notifikasi in-app milik satu user
+type InboxItem struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	Subject   string      `json:"subject"`
+	Text      string      `json:"text"`
+	Data      interface{} `json:"data,omitempty"`
+	Read      bool        `json:"read"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+type InboxList struct {
+	Items  []InboxItem `json:"items"`
+	Total  int64       `json:"total"`
+	Unread int64       `json:"unread"`
+}
+
+type InboxFilter struct {
+	Email  string
+	Limit  int64
+	Offset int64
+}
+
+// jenis event yang didorong ke client lewat stream
+const (
+	InboxEventNotification = "notification"
+	InboxEventUnread       = "unread"
+)
+
+// InboxEvent dikirim lewat redis pub/sub ke semua koneksi stream milik user
+type InboxEvent struct {
+	Type   string     `json:"type"`
+	Item   *InboxItem `json:"item,omitempty"`
+	Unread int64      `json:"unread"`
+}
@@ -0,0 +1,38 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"service_notification/dto"
+	"time"
+)
+
+// channel yang tidak punya alamat tujuan untuk pesan ini, misalnya sms tanpa nomor
+var ErrNoRecipient = errors.New("penerima tidak ada untuk channel ini")
+
+// Message satu notifikasi yang sudah dirender, sama untuk semua channel
+type Message struct {
+	ID          string
+	Service     string
+	Action      string
+	Email       string
+	Phone       string
+	Subject     string
+	HTML        string
+	Text        string
+	Data        interface{}
+	Attachments []dto.Attachment
+	CreatedAt   time.Time
+}
+
+// Event nama jenis notifikasi yang dipakai aturan routing, contoh "order.paid"
+func (m *Message) Event() string {
+	return m.Service + "." + m.Action
+}
+
+// Notifier satu channel pengiriman. Channel baru cukup mengimplementasikan ini
+// lalu didaftarkan ke Router.
+type Notifier interface {
+	Name() string
+	Send(ctx context.Context, msg *Message) error
+}
@@ -0,0 +1,41 @@
+package channel
+
+import (
+	"context"
+	"service_notification/dto"
+)
+
+// InboxStore tempat notifikasi in-app disimpan dan diteruskan ke client yang
+// sedang terhubung, diimplementasikan usecase inbox
+type InboxStore interface {
+	Save(ctx context.Context, email string, item *dto.InboxItem) error
+}
+
+// InApp menyimpan notifikasi ke inbox user, dibaca lewat api inbox
+type InApp struct {
+	store InboxStore
+}
+
+func NewInApp(store InboxStore) *InApp {
+	return &InApp{store}
+}
+
+func (a *InApp) Name() string {
+	return "inapp"
+}
+
+func (a *InApp) Send(ctx context.Context, msg *Message) error {
+	if msg.Email == "" {
+		return ErrNoRecipient
+	}
+
+	// satu correlation id bisa dipakai beberapa jenis notifikasi
+	return a.store.Save(ctx, msg.Email, &dto.InboxItem{
+		ID:        msg.ID + ":" + msg.Event(),
+		Event:     msg.Event(),
+		Subject:   msg.Subject,
+		Text:      msg.Text,
+		Data:      msg.Data,
+		CreatedAt: msg.CreatedAt,
+	})
+}
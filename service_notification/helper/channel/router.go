@@ -0,0 +1,97 @@
+package channel
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// aturan default kalau NOTIFY_ROUTES kosong
+const DefaultRoutes = "*=email,inapp"
+
+// Router memilih channel per jenis notifikasi. Aturan dicocokkan dari yang paling
+// spesifik: "order.paid", lalu "order.*", lalu "*".
+type Router struct {
+	rules    map[string][]string
+	channels map[string]Notifier
+}
+
+// NewRouter membaca aturan berformat "order.paid=email,webhook;return.*=email,inapp;*=email".
+// Channel di aturan yang tidak aktif dilewati dengan peringatan di log.
+func NewRouter(spec string, channels ...Notifier) (*Router, error) {
+	r := &Router{
+		rules:    make(map[string][]string),
+		channels: make(map[string]Notifier),
+	}
+	for _, ch := range channels {
+		r.channels[ch.Name()] = ch
+	}
+
+	if strings.TrimSpace(spec) == "" {
+		spec = DefaultRoutes
+	}
+	for _, rule := range strings.Split(spec, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		event, names, ok := strings.Cut(rule, "=")
+		event = strings.TrimSpace(event)
+		if !ok || event == "" {
+			return nil, fmt.Errorf("aturan routing tidak valid: %q", rule)
+		}
+
+		selected := []string{}
+		for _, name := range strings.Split(names, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if _, ok := r.channels[name]; !ok {
+				log.Printf("routing %s: channel %s tidak aktif, dilewati", event, name)
+				continue
+			}
+			selected = append(selected, name)
+		}
+		r.rules[event] = selected
+	}
+
+	return r, nil
+}
+
+func (r *Router) Route(service, action string) []Notifier {
+	names, ok := r.rules[service+"."+action]
+	if !ok {
+		names, ok = r.rules[service+".*"]
+	}
+	if !ok {
+		names = r.rules["*"]
+	}
+
+	result := make([]Notifier, 0, len(names))
+	for _, name := range names {
+		result = append(result, r.channels[name])
+	}
+	return result
+}
+
+// Only menyaring channel hasil Route ke daftar nama "email,webhook".
+// Daftar kosong berarti semua channel tetap dipakai.
+func Only(channels []Notifier, names string) []Notifier {
+	if strings.TrimSpace(names) == "" {
+		return channels
+	}
+
+	allowed := make(map[string]bool)
+	for _, name := range strings.Split(names, ",") {
+		allowed[strings.TrimSpace(name)] = true
+	}
+
+	result := make([]Notifier, 0, len(channels))
+	for _, ch := range channels {
+		if allowed[ch.Name()] {
+			result = append(result, ch)
+		}
+	}
+	return result
+}
@@ -0,0 +1,39 @@
+package channel
+
+import (
+	"context"
+	"log"
+)
+
+// SMSProvider gateway sms, implementasi vendor cukup memenuhi interface ini
+type SMSProvider interface {
+	SendSMS(ctx context.Context, to, body string) error
+}
+
+// LogSMSProvider stub yang hanya mencatat ke log, dipakai sampai ada vendor sms
+type LogSMSProvider struct{}
+
+func (LogSMSProvider) SendSMS(ctx context.Context, to, body string) error {
+	log.Printf("[sms stub] ke %s: %s", to, body)
+	return nil
+}
+
+// SMS mengirim subject notifikasi saja supaya muat di satu pesan
+type SMS struct {
+	provider SMSProvider
+}
+
+func NewSMS(provider SMSProvider) *SMS {
+	return &SMS{provider}
+}
+
+func (s *SMS) Name() string {
+	return "sms"
+}
+
+func (s *SMS) Send(ctx context.Context, msg *Message) error {
+	if msg.Phone == "" {
+		return ErrNoRecipient
+	}
+	return s.provider.SendSMS(ctx, msg.Phone, "Shop: "+msg.Subject)
+}
@@ -0,0 +1,177 @@
+package channel
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/gomail.v2"
+)
+
+const (
+	// STARTTLS dipakai kalau server menawarkannya, biasanya port 587
+	SMTPStartTLS = "starttls"
+	// koneksi langsung TLS, biasanya port 465
+	SMTPImplicitTLS = "tls"
+	// tanpa enkripsi sama sekali, hanya untuk relay lokal seperti mailhog
+	SMTPNone = "none"
+)
+
+type SMTPConfig struct {
+	Host       string
+	Port       int
+	Username   string
+	Password   string
+	From       string
+	TLSMode    string
+	SkipVerify bool
+}
+
+// SMTPConfigFromEnv membaca SMTP_*, default sama dengan pengaturan gmail sebelumnya
+func SMTPConfigFromEnv() SMTPConfig {
+	cfg := SMTPConfig{
+		Host:     envOr("SMTP_HOST", "smtp.gmail.com"),
+		Port:     587,
+		Username: envOr("SMTP_USERNAME", os.Getenv("EMAIL_SENDER")),
+		Password: envOr("SMTP_PASSWORD", os.Getenv("APP_PASSWORD")),
+		From:     envOr("SMTP_FROM", os.Getenv("EMAIL_SENDER")),
+		TLSMode:  strings.ToLower(strings.TrimSpace(envOr("SMTP_TLS", SMTPStartTLS))),
+	}
+	// relay tanpa TLS biasanya tanpa login, kredensial gmail dari EMAIL_SENDER dan
+	// APP_PASSWORD tidak ikut dipakai kecuali SMTP_USERNAME diisi sendiri
+	if cfg.TLSMode == SMTPNone {
+		cfg.Username = os.Getenv("SMTP_USERNAME")
+		cfg.Password = os.Getenv("SMTP_PASSWORD")
+	}
+	if port, err := strconv.Atoi(os.Getenv("SMTP_PORT")); err == nil && port > 0 {
+		cfg.Port = port
+	}
+	cfg.SkipVerify, _ = strconv.ParseBool(os.Getenv("SMTP_TLS_SKIP_VERIFY"))
+	return cfg
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+type SMTP struct {
+	cfg    SMTPConfig
+	dialer *gomail.Dialer
+}
+
+// NewSMTP menolak SMTP_TLS yang tidak dikenal supaya salah ketik ketahuan saat start,
+// bukan diam-diam jatuh ke STARTTLS
+func NewSMTP(cfg SMTPConfig) (*SMTP, error) {
+	switch cfg.TLSMode {
+	case SMTPStartTLS, SMTPImplicitTLS, SMTPNone:
+	default:
+		return nil, fmt.Errorf("SMTP_TLS tidak valid: %q, pilih %s, %s atau %s", cfg.TLSMode, SMTPStartTLS, SMTPImplicitTLS, SMTPNone)
+	}
+
+	dialer := gomail.NewDialer(cfg.Host, cfg.Port, cfg.Username, cfg.Password)
+	dialer.SSL = cfg.TLSMode == SMTPImplicitTLS
+	dialer.TLSConfig = &tls.Config{ServerName: cfg.Host, InsecureSkipVerify: cfg.SkipVerify}
+	return &SMTP{cfg, dialer}, nil
+}
+
+func (s *SMTP) Name() string {
+	return "email"
+}
+
+func (s *SMTP) Send(ctx context.Context, msg *Message) error {
+	if msg.Email == "" {
+		return ErrNoRecipient
+	}
+
+	mailer := gomail.NewMessage()
+	mailer.SetHeader("From", s.cfg.From)
+	mailer.SetHeader("To", msg.Email)
+	mailer.SetHeader("Subject", "Shop: "+msg.Subject)
+	mailer.SetBody("text/plain", msg.Text)
+	mailer.AddAlternative("text/html", msg.HTML)
+	for _, attachment := range msg.Attachments {
+		content := attachment.Content
+		mailer.Attach(attachment.Filename, gomail.SetCopyFunc(func(w io.Writer) error {
+			_, err := w.Write(content)
+			return err
+		}))
+	}
+
+	if s.cfg.TLSMode == SMTPNone {
+		return gomail.Send(gomail.SendFunc(s.sendPlain), mailer)
+	}
+	return s.dialer.DialAndSend(mailer)
+}
+
+// sendPlain mengirim tanpa STARTTLS, dialer gomail selalu mencoba STARTTLS kalau
+// server menawarkannya. Login hanya dilakukan kalau Username diisi.
+func (s *SMTP) sendPlain(from string, to []string, msg io.WriterTo) error {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port)), 10*time.Second)
+	if err != nil {
+		return err
+	}
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if s.cfg.Username != "" {
+		if ok, _ := client.Extension("AUTH"); ok {
+			if err := client.Auth(plaintextAuth{s.cfg.Username, s.cfg.Password}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err := client.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := msg.WriteTo(w); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// plaintextAuth sama dengan smtp.PlainAuth tapi tidak menolak koneksi tanpa TLS,
+// mode none memang dipilih untuk relay yang tidak mendukung TLS
+type plaintextAuth struct {
+	username string
+	password string
+}
+
+func (a plaintextAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	return "PLAIN", []byte("\x00" + a.username + "\x00" + a.password), nil
+}
+
+func (a plaintextAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if more {
+		return nil, errors.New("smtp: server meminta data auth tambahan")
+	}
+	return nil, nil
+}
@@ -0,0 +1,167 @@
+package channel
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeSMTP menawarkan STARTTLS dan AUTH, mencatat perintah yang diterima
+// tanpa pernah benar-benar memulai TLS
+type fakeSMTP struct {
+	mu       sync.Mutex
+	commands []string
+	done     chan struct{}
+}
+
+func startFakeSMTP(t *testing.T, host string) (*fakeSMTP, int) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		t.Skipf("listen %s: %v", host, err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	server := &fakeSMTP{done: make(chan struct{})}
+	go server.serve(ln)
+	return server, ln.Addr().(*net.TCPAddr).Port
+}
+
+func (f *fakeSMTP) serve(ln net.Listener) {
+	defer close(f.done)
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	fmt.Fprint(conn, "220 fake\r\n")
+	data := false
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		if data {
+			if line == ".\r\n" {
+				data = false
+				fmt.Fprint(conn, "250 ok\r\n")
+			}
+			continue
+		}
+
+		command := strings.ToUpper(strings.Fields(line)[0])
+		f.mu.Lock()
+		f.commands = append(f.commands, strings.TrimSpace(line))
+		f.mu.Unlock()
+
+		switch command {
+		case "EHLO":
+			fmt.Fprint(conn, "250-fake\r\n250-STARTTLS\r\n250 AUTH PLAIN LOGIN\r\n")
+		case "AUTH":
+			fmt.Fprint(conn, "235 ok\r\n")
+		case "DATA":
+			data = true
+			fmt.Fprint(conn, "354 lanjut\r\n")
+		case "QUIT":
+			fmt.Fprint(conn, "221 bye\r\n")
+			return
+		default:
+			fmt.Fprint(conn, "250 ok\r\n")
+		}
+	}
+}
+
+func (f *fakeSMTP) sent(t *testing.T) []string {
+	t.Helper()
+	<-f.done
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.commands
+}
+
+func hasCommand(commands []string, prefix string) bool {
+	for _, command := range commands {
+		if strings.HasPrefix(strings.ToUpper(command), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func testMessage() *Message {
+	return &Message{Email: "user@mail.com", Subject: "tes", Text: "isi", HTML: "<p>isi</p>"}
+}
+
+func TestNewSMTPRejectsUnknownTLSMode(t *testing.T) {
+	for _, mode := range []string{"", "startls", "ssl"} {
+		if _, err := NewSMTP(SMTPConfig{TLSMode: mode}); err == nil {
+			t.Fatalf("NewSMTP(%q) error = nil, want error", mode)
+		}
+	}
+}
+
+// relay seperti mailhog di compose bukan localhost, login tidak boleh dicoba
+// hanya karena EMAIL_SENDER terisi
+func TestSMTPNoneSkipsSenderCredentials(t *testing.T) {
+	server, port := startFakeSMTP(t, "127.0.0.1")
+
+	t.Setenv("SMTP_TLS", SMTPNone)
+	t.Setenv("SMTP_HOST", "127.0.0.1")
+	t.Setenv("SMTP_PORT", fmt.Sprint(port))
+	t.Setenv("SMTP_USERNAME", "")
+	t.Setenv("SMTP_PASSWORD", "")
+	t.Setenv("EMAIL_SENDER", "shop@mail.com")
+	t.Setenv("APP_PASSWORD", "rahasia")
+
+	s, err := NewSMTP(SMTPConfigFromEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send(context.Background(), testMessage()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	commands := server.sent(t)
+	if hasCommand(commands, "STARTTLS") || hasCommand(commands, "AUTH") {
+		t.Fatalf("commands = %v, want no STARTTLS or AUTH", commands)
+	}
+	if !hasCommand(commands, "MAIL FROM:<SHOP@MAIL.COM>") {
+		t.Fatalf("commands = %v, want MAIL FROM shop@mail.com", commands)
+	}
+}
+
+// smtp.PlainAuth menolak host selain localhost tanpa TLS, 127.0.0.2 dipakai
+// supaya kasus itu ikut teruji
+func TestSMTPNoneAuthWithoutTLS(t *testing.T) {
+	server, port := startFakeSMTP(t, "127.0.0.2")
+
+	s, err := NewSMTP(SMTPConfig{
+		Host:     "127.0.0.2",
+		Port:     port,
+		Username: "relay",
+		Password: "rahasia",
+		From:     "shop@mail.com",
+		TLSMode:  SMTPNone,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send(context.Background(), testMessage()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	commands := server.sent(t)
+	if hasCommand(commands, "STARTTLS") {
+		t.Fatalf("commands = %v, want no STARTTLS", commands)
+	}
+	if !hasCommand(commands, "AUTH PLAIN") {
+		t.Fatalf("commands = %v, want AUTH PLAIN", commands)
+	}
+}
@@ -0,0 +1,86 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Webhook mengirim notifikasi sebagai json ke satu url. Penerima memverifikasi
+// header X-Shop-Signature = "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)).
+type Webhook struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+type webhookPayload struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	Email     string      `json:"email"`
+	Subject   string      `json:"subject"`
+	Text      string      `json:"text"`
+	Data      interface{} `json:"data"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+func NewWebhook(url, secret string) *Webhook {
+	return &Webhook{url, []byte(secret), &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (w *Webhook) Name() string {
+	return "webhook"
+}
+
+// Sign dipakai juga oleh penerima untuk memeriksa signature
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (w *Webhook) Send(ctx context.Context, msg *Message) error {
+	body, err := json.Marshal(webhookPayload{
+		ID:        msg.ID,
+		Event:     msg.Event(),
+		Email:     msg.Email,
+		Subject:   msg.Subject,
+		Text:      msg.Text,
+		Data:      msg.Data,
+		CreatedAt: msg.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Shop-Event", msg.Event())
+	req.Header.Set("X-Shop-Delivery", msg.ID)
+	req.Header.Set("X-Shop-Timestamp", timestamp)
+	req.Header.Set("X-Shop-Signature", Sign(w.secret, timestamp, body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook membalas status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"service_notification/helper/utils"
+	"strings"
+)
+
+// StreamAuthMiddleware sama dengan AuthMiddleware, tapi token juga boleh dikirim
+// lewat query ?token= karena EventSource di browser tidak bisa memasang header.
+func StreamAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenString := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if tokenString == "" {
+			tokenString = r.URL.Query().Get("token")
+		}
+		if tokenString == "" {
+			utils.WriteError(w, http.StatusUnauthorized, "tak ada token")
+			return
+		}
+
+		claims, err := utils.ValidateJWT(tokenString)
+		if err != nil {
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), UserContextKey, claims)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"service_notification/helper/utils"
+)
+
+const SupportTokenHeader = "X-Support-Token"
+
+// SupportMiddleware untuk endpoint tim support, dicek dengan token bersama di
+// SUPPORT_TOKEN. Kalau env kosong endpoint dianggap tidak aktif.
+func SupportMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expected := os.Getenv("SUPPORT_TOKEN")
+		if expected == "" {
+			utils.WriteError(w, http.StatusForbidden, "endpoint support tidak aktif")
+			return
+		}
+
+		tokenString := r.Header.Get(SupportTokenHeader)
+		if tokenString == "" {
+			utils.WriteError(w, http.StatusUnauthorized, "tak ada token support")
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(tokenString), []byte(expected)) != 1 {
+			utils.WriteError(w, http.StatusForbidden, "token support tidak valid")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
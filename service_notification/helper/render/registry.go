@@ -0,0 +1,56 @@
+package render
+
+import "service_notification/dto"
+
+type kind struct {
+	newMessage func() interface{}
+}
+
+var kinds = map[string]kind{}
+
+func kindName(service, action string) string {
+	return service + "." + action
+}
+
+// Register mendaftarkan jenis notifikasi. newMessage membuat tujuan decode field
+// message, templatenya didefinisikan dengan nama "<service>.<action>" dan
+// "<service>.<action>.subject" di file templates/<bahasa>/.
+func Register(service, action string, newMessage func() interface{}) {
+	kinds[kindName(service, action)] = kind{newMessage}
+}
+
+// NewMessage membuat tujuan decode message, false kalau jenisnya tidak terdaftar
+func NewMessage(service, action string) (interface{}, bool) {
+	k, ok := kinds[kindName(service, action)]
+	if !ok {
+		return nil, false
+	}
+	return k.newMessage(), true
+}
+
+func init() {
+	Register("user", "register", func() interface{} { return new(string) })
+
+	Register("store", "create", func() interface{} { return new(dto.Store) })
+	Register("store", "update", func() interface{} { return new(dto.Store) })
+	Register("store", "delete", func() interface{} { return new(uint) })
+
+	Register("product", "create", func() interface{} { return new(dto.Product) })
+	Register("product", "update", func() interface{} { return new(dto.Product) })
+	Register("product", "delete", func() interface{} { return new(uint) })
+
+	Register("order", "paid", func() interface{} { return new(dto.Order) })
+	Register("order", "new_order", func() interface{} { return new(dto.StoreOrder) })
+
+	Register("shipment", "packed", func() interface{} { return new(dto.Shipment) })
+	Register("shipment", "shipped", func() interface{} { return new(dto.Shipment) })
+	Register("shipment", "delivered", func() interface{} { return new(dto.Shipment) })
+
+	Register("return", "requested", func() interface{} { return new(dto.Return) })
+	Register("return", "approved", func() interface{} { return new(dto.Return) })
+	Register("return", "rejected", func() interface{} { return new(dto.Return) })
+	Register("return", "refunded", func() interface{} { return new(dto.Return) })
+	Register("return", "refund_failed", func() interface{} { return new(dto.Return) })
+
+	Register("cart", "abandoned", func() interface{} { return new(dto.AbandonedCart) })
+}
@@ -0,0 +1,163 @@
+package render
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"reflect"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates
+var files embed.FS
+
+// bahasa yang dipakai kalau user belum memilih atau bahasanya tidak ada template
+const DefaultLocale = "id"
+
+var Locales = []string{"id", "en"}
+
+// Data isi yang bisa dipakai template, Message sudah didecode ke dto milik jenis notifikasi
+type Data struct {
+	ActionID      string
+	Now           time.Time
+	Message       interface{}
+	HasAttachment bool
+}
+
+type Mail struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+type layoutData struct {
+	Subject string
+	Body    interface{}
+	Data    Data
+}
+
+type Renderer struct {
+	html map[string]*htmltemplate.Template
+	text map[string]*texttemplate.Template
+}
+
+// New mem-parse semua template dan memastikan setiap jenis yang terdaftar punya
+// subject, versi html dan versi teks di semua bahasa
+func New() (*Renderer, error) {
+	r := &Renderer{
+		html: make(map[string]*htmltemplate.Template),
+		text: make(map[string]*texttemplate.Template),
+	}
+
+	for _, locale := range Locales {
+		html, err := htmltemplate.New(locale).Funcs(htmltemplate.FuncMap(funcs(locale))).ParseFS(files, "templates/"+locale+"/*.html.tmpl")
+		if err != nil {
+			return nil, err
+		}
+		text, err := texttemplate.New(locale).Funcs(texttemplate.FuncMap(funcs(locale))).ParseFS(files, "templates/"+locale+"/*.txt.tmpl")
+		if err != nil {
+			return nil, err
+		}
+
+		if html.Lookup("layout") == nil || text.Lookup("layout") == nil {
+			return nil, fmt.Errorf("template %s: layout tidak ada", locale)
+		}
+		for name := range kinds {
+			if html.Lookup(name) == nil || text.Lookup(name) == nil || text.Lookup(name+".subject") == nil {
+				return nil, fmt.Errorf("template %s: %s belum lengkap", locale, name)
+			}
+		}
+
+		r.html[locale] = html
+		r.text[locale] = text
+	}
+
+	return r, nil
+}
+
+// Render membuat subject, html dan teks polos. Nilai dari user di-escape
+// otomatis oleh html/template.
+func (r *Renderer) Render(locale, service, action string, data Data) (*Mail, error) {
+	if _, ok := r.html[locale]; !ok {
+		locale = DefaultLocale
+	}
+	name := kindName(service, action)
+	if _, ok := kinds[name]; !ok {
+		return nil, fmt.Errorf("notifikasi %s belum terdaftar", name)
+	}
+	if data.Message != nil {
+		data.Message = reflect.Indirect(reflect.ValueOf(data.Message)).Interface()
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := r.text[locale].ExecuteTemplate(&subject, name+".subject", data); err != nil {
+		return nil, err
+	}
+	if err := r.text[locale].ExecuteTemplate(&text, name, data); err != nil {
+		return nil, err
+	}
+	if err := r.html[locale].ExecuteTemplate(&html, name, data); err != nil {
+		return nil, err
+	}
+
+	mail := &Mail{Subject: strings.TrimSpace(subject.String())}
+
+	var body bytes.Buffer
+	if err := r.text[locale].ExecuteTemplate(&body, "layout", layoutData{mail.Subject, strings.TrimSpace(text.String()), data}); err != nil {
+		return nil, err
+	}
+	mail.Text = body.String()
+
+	body.Reset()
+	// konten html sudah di-escape saat dieksekusi di atas
+	if err := r.html[locale].ExecuteTemplate(&body, "layout", layoutData{mail.Subject, htmltemplate.HTML(html.String()), data}); err != nil {
+		return nil, err
+	}
+	mail.HTML = body.String()
+
+	return mail, nil
+}
+
+func funcs(locale string) map[string]interface{} {
+	return map[string]interface{}{
+		"money": func(amount int64) string {
+			return formatMoney(locale, amount)
+		},
+		"datetime": func(t time.Time) string {
+			if locale == "en" {
+				return t.Format("Jan 2, 2006 15:04 MST")
+			}
+			return t.Format("02/01/2006 15:04 MST")
+		},
+	}
+}
+
+// formatMoney memakai pemisah ribuan sesuai bahasa, rupiah tanpa desimal
+func formatMoney(locale string, amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := fmt.Sprintf("%d", amount)
+	sep := "."
+	if locale == "en" {
+		sep = ","
+	}
+	var grouped strings.Builder
+	for i, c := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteString(sep)
+		}
+		grouped.WriteRune(c)
+	}
+
+	if locale == "en" {
+		return sign + "IDR " + grouped.String()
+	}
+	return sign + "Rp" + grouped.String()
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	NotificationTopic = "notification-request"
+	DeadLetterTopic   = "notification-request.dlq"
+)
+
+// alasan pesan masuk dlq. decode dan render tidak dicoba ulang karena
+// hasilnya akan sama sampai payload atau template diperbaiki.
+const (
+	ReasonDecode   = "decode"
+	ReasonRender   = "render"
+	ReasonDelivery = "delivery"
+)
+
+// metadata kegagalan disimpan di header, value tetap payload asli supaya bisa
+// dikirim ulang apa adanya
+const (
+	HeaderDLQReason    = "x-dlq-reason"
+	HeaderDLQError     = "x-dlq-error"
+	HeaderDLQAttempts  = "x-dlq-attempts"
+	HeaderDLQChannels  = "x-dlq-channels"
+	HeaderDLQTopic     = "x-dlq-source-topic"
+	HeaderDLQPartition = "x-dlq-source-partition"
+	HeaderDLQOffset    = "x-dlq-source-offset"
+	HeaderDLQFailedAt  = "x-dlq-failed-at"
+
+	// HeaderChannels membatasi channel tujuan, dipasang saat replay supaya
+	// channel yang dulu sudah berhasil tidak dikirimi lagi
+	HeaderChannels = "x-notify-channels"
+)
+
+// DeadLetter membungkus pesan yang gagal diproses beserta alasan kegagalannya
+func DeadLetter(msg kafka.Message, reason string, err error, attempts int, channels []string) kafka.Message {
+	headers := []kafka.Header{
+		{Key: HeaderDLQReason, Value: []byte(reason)},
+		{Key: HeaderDLQError, Value: []byte(err.Error())},
+		{Key: HeaderDLQAttempts, Value: []byte(strconv.Itoa(attempts))},
+		{Key: HeaderDLQTopic, Value: []byte(msg.Topic)},
+		{Key: HeaderDLQPartition, Value: []byte(strconv.Itoa(msg.Partition))},
+		{Key: HeaderDLQOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		{Key: HeaderDLQFailedAt, Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+	}
+	if len(channels) > 0 {
+		headers = append(headers, kafka.Header{Key: HeaderDLQChannels, Value: []byte(strings.Join(channels, ","))})
+	}
+
+	return kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}
+}
+
+// HeaderValue mengembalikan string kosong kalau header tidak ada
+func HeaderValue(headers []kafka.Header, key string) string {
+	for _, header := range headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
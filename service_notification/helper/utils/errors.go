@@ -0,0 +1,8 @@
+package utils
+
+import "errors"
+
+var (
+	ErrInboxNotFound    = errors.New("notifikasi tidak ditemukan")
+	ErrDeliveryNotFound = errors.New("status pengiriman tidak ditemukan")
+)
@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func reminderKey(kind, email string) string {
+	return fmt.Sprintf("reminder:%s:%s", kind, email)
+}
+
+// AllowReminder membatasi satu reminder per user dalam window, atomic lewat SETNX
+func AllowReminder(rdb *redis.Client, kind, email string, window time.Duration) (bool, error) {
+	return rdb.SetNX(context.Background(), reminderKey(kind, email), time.Now().Unix(), window).Result()
+}
+
+// ReleaseReminder membuka lagi jatah reminder kalau email gagal terkirim
+func ReleaseReminder(rdb *redis.Client, kind, email string) error {
+	return rdb.Del(context.Background(), reminderKey(kind, email)).Err()
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func localeKey(email string) string {
+	return fmt.Sprintf("locale:%s", email)
+}
+
+// SaveLocale menyimpan bahasa email pilihan user, dikirim service user saat berubah
+func SaveLocale(rdb *redis.Client, email, locale string) error {
+	return rdb.Set(context.Background(), localeKey(email), locale, 0).Err()
+}
+
+// GetLocale mengembalikan string kosong kalau user belum pernah memilih bahasa
+func GetLocale(rdb *redis.Client, email string) (string, error) {
+	locale, err := rdb.Get(context.Background(), localeKey(email)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return locale, err
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"time"
+)
+
+// RetryConfig batas percobaan kirim notifikasi sebelum pesan dipindah ke dlq
+type RetryConfig struct {
+	MaxAttempts int
+	Base        time.Duration
+	Max         time.Duration
+}
+
+// RetryConfigFromEnv membaca NOTIFY_MAX_ATTEMPTS, NOTIFY_RETRY_BASE dan
+// NOTIFY_RETRY_MAX. Default 5 percobaan dengan jeda 1s, 2s, 4s, 8s.
+func RetryConfigFromEnv() RetryConfig {
+	config := RetryConfig{MaxAttempts: 5, Base: time.Second, Max: 30 * time.Second}
+	if attempts, err := strconv.Atoi(os.Getenv("NOTIFY_MAX_ATTEMPTS")); err == nil && attempts > 0 {
+		config.MaxAttempts = attempts
+	}
+	if base, err := time.ParseDuration(os.Getenv("NOTIFY_RETRY_BASE")); err == nil && base > 0 {
+		config.Base = base
+	}
+	if max, err := time.ParseDuration(os.Getenv("NOTIFY_RETRY_MAX")); err == nil && max > 0 {
+		config.Max = max
+	}
+	return config
+}
+
+// Backoff jeda sebelum percobaan berikutnya setelah attempt gagal (mulai dari 1).
+// Jeda naik dua kali lipat sampai Max, separuhnya diacak supaya consumer yang
+// gagal bersamaan tidak mencoba ulang di waktu yang sama.
+func (c RetryConfig) Backoff(attempt int) time.Duration {
+	delay := c.Max
+	if attempt < 32 {
+		if d := c.Base << (attempt - 1); d > 0 && d < c.Max {
+			delay = d
+		}
+	}
+
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+	"service_notification/helper/utils"
+	"service_notification/internal/usecase"
+
+	"github.com/gorilla/mux"
+)
+
+type DeliveryHandler struct {
+	deliveryUsecase usecase.DeliveryUsecase
+}
+
+func NewDeliveryHandler(deliveryUsecase usecase.DeliveryUsecase) *DeliveryHandler {
+	return &DeliveryHandler{deliveryUsecase}
+}
+
+func (h *DeliveryHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	correlationId := mux.Vars(r)["correlationId"]
+
+	response, err := h.deliveryUsecase.GetStatus(r.Context(), correlationId)
+	if err != nil {
+		switch err {
+		case utils.ErrDeliveryNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"service_notification/dto"
+	"service_notification/helper/middleware"
+	"service_notification/helper/utils"
+	"service_notification/internal/usecase"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultInboxLimit = 20
+	maxInboxLimit     = 100
+	// komentar kosong berkala supaya proxy tidak memutus stream yang sepi
+	streamHeartbeat = 25 * time.Second
+)
+
+type InboxHandler struct {
+	inboxUsecase usecase.InboxUsecase
+}
+
+func NewInboxHandler(inboxUsecase usecase.InboxUsecase) *InboxHandler {
+	return &InboxHandler{inboxUsecase}
+}
+
+func parseQueryInt(r *http.Request, name string, fallback int64) (int64, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return fallback, true
+	}
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || value < 0 {
+		return 0, false
+	}
+	return value, true
+}
+
+func (h *InboxHandler) GetInbox(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	limit, ok := parseQueryInt(r, "limit", defaultInboxLimit)
+	if !ok || limit == 0 || limit > maxInboxLimit {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Sprintf("limit harus 1 sampai %d", maxInboxLimit))
+		return
+	}
+	offset, ok := parseQueryInt(r, "offset", 0)
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, "offset tidak valid")
+		return
+	}
+
+	filter := dto.InboxFilter{Email: claims.Email, Limit: limit, Offset: offset}
+	response, err := h.inboxUsecase.GetInbox(r.Context(), &filter)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *InboxHandler) UnreadCount(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	unread, err := h.inboxUsecase.UnreadCount(r.Context(), claims.Email)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]int64{"unread": unread})
+}
+
+func (h *InboxHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if err := h.inboxUsecase.MarkRead(r.Context(), claims.Email, id); err != nil {
+		switch err {
+		case utils.ErrInboxNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]string{"message": "notifikasi ditandai dibaca"})
+}
+
+func (h *InboxHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	if err := h.inboxUsecase.MarkAllRead(r.Context(), claims.Email); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]string{"message": "semua notifikasi ditandai dibaca"})
+}
+
+// Stream mengirim perubahan inbox sebagai server-sent events. Event "unread"
+// berisi jumlah belum dibaca, event "notification" berisi item baru.
+func (h *InboxHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		utils.WriteError(w, http.StatusInternalServerError, "streaming tidak didukung")
+		return
+	}
+
+	events, err := h.inboxUsecase.Subscribe(r.Context(), claims.Email)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeEvent(w, &event); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event *dto.InboxEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if event.Item != nil {
+		if _, err := fmt.Fprintf(w, "id: %s\n", event.Item.ID); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+	return err
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"service_notification/dto"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DeliveryRepo mencatat hasil kirim per channel di hash delivery:<correlation_id>,
+// field "service.action|penerima|channel". Hash kedaluwarsa setelah ttl sejak
+// catatan terakhir, setelah itu pesan yang sama dianggap baru lagi.
+type DeliveryRepo interface {
+	Get(ctx context.Context, key *dto.DeliveryKey, channel string) (*dto.DeliveryAttempt, error)
+	Save(ctx context.Context, key *dto.DeliveryKey, attempt *dto.DeliveryAttempt) error
+	GetAll(ctx context.Context, correlationId string) ([]dto.DeliveryAttempt, error)
+}
+
+type deliveryRepo struct {
+	redis *redis.Client
+	ttl   time.Duration
+}
+
+func NewDeliveryRepo(redis *redis.Client, ttl time.Duration) DeliveryRepo {
+	return &deliveryRepo{redis, ttl}
+}
+
+func deliveryKey(correlationId string) string {
+	return fmt.Sprintf("delivery:%s", correlationId)
+}
+
+func deliveryField(key *dto.DeliveryKey, channel string) string {
+	return fmt.Sprintf("%s.%s|%s|%s", key.Service, key.Action, key.Recipient, channel)
+}
+
+func (r *deliveryRepo) Get(ctx context.Context, key *dto.DeliveryKey, channel string) (*dto.DeliveryAttempt, error) {
+	value, err := r.redis.HGet(ctx, deliveryKey(key.CorrelationID), deliveryField(key, channel)).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var attempt dto.DeliveryAttempt
+	if err := json.Unmarshal([]byte(value), &attempt); err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+func (r *deliveryRepo) Save(ctx context.Context, key *dto.DeliveryKey, attempt *dto.DeliveryAttempt) error {
+	value, err := json.Marshal(attempt)
+	if err != nil {
+		return err
+	}
+
+	redisKey := deliveryKey(key.CorrelationID)
+	_, err = r.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, redisKey, deliveryField(key, attempt.Channel), value)
+		pipe.Expire(ctx, redisKey, r.ttl)
+		return nil
+	})
+	return err
+}
+
+func (r *deliveryRepo) GetAll(ctx context.Context, correlationId string) ([]dto.DeliveryAttempt, error) {
+	values, err := r.redis.HGetAll(ctx, deliveryKey(correlationId)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := make([]dto.DeliveryAttempt, 0, len(values))
+	for _, value := range values {
+		var attempt dto.DeliveryAttempt
+		if err := json.Unmarshal([]byte(value), &attempt); err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	// hash redis tidak berurutan, diurutkan supaya hasil stabil dibaca
+	sort.Slice(attempts, func(i, j int) bool {
+		if !attempts[i].UpdatedAt.Equal(attempts[j].UpdatedAt) {
+			return attempts[i].UpdatedAt.Before(attempts[j].UpdatedAt)
+		}
+		return attempts[i].Channel < attempts[j].Channel
+	})
+	return attempts, nil
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"service_notification/dto"
+	"service_notification/helper/utils"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// batas notifikasi in-app yang disimpan per user, yang paling lama dibuang
+const inboxLimit = 100
+
+// InboxRepo menyimpan inbox per email di redis:
+// hash id -> item, sorted set id urut waktu, dan set id yang belum dibaca.
+// Perubahan inbox diumumkan lewat pub/sub supaya semua koneksi stream ikut menerima.
+type InboxRepo interface {
+	Save(ctx context.Context, email string, item *dto.InboxItem) (bool, int64, error)
+	GetItems(ctx context.Context, filter *dto.InboxFilter) (*dto.InboxList, error)
+	MarkRead(ctx context.Context, email, id string) (int64, error)
+	MarkAllRead(ctx context.Context, email string) error
+	UnreadCount(ctx context.Context, email string) (int64, error)
+	Publish(ctx context.Context, email string, event *dto.InboxEvent) error
+	Subscribe(ctx context.Context, email string) *redis.PubSub
+}
+
+type inboxRepo struct {
+	redis *redis.Client
+}
+
+func NewInboxRepo(redis *redis.Client) InboxRepo {
+	return &inboxRepo{redis}
+}
+
+func inboxItemsKey(email string) string {
+	return fmt.Sprintf("inbox:%s:items", email)
+}
+
+func inboxOrderKey(email string) string {
+	return fmt.Sprintf("inbox:%s:order", email)
+}
+
+func inboxUnreadKey(email string) string {
+	return fmt.Sprintf("inbox:%s:unread", email)
+}
+
+func inboxChannel(email string) string {
+	return fmt.Sprintf("inbox:%s:events", email)
+}
+
+// saveInboxScript menyimpan item sekali saja per id, jadi pesan kafka yang terkirim
+// ulang tidak membuat item yang sudah dibaca kembali belum dibaca. Mengembalikan
+// -1 kalau id sudah ada, selain itu jumlah belum dibaca setelah disimpan.
+var saveInboxScript = redis.NewScript(`
+if redis.call('HSETNX', KEYS[1], ARGV[1], ARGV[2]) == 0 then
+	return -1
+end
+redis.call('ZADD', KEYS[2], ARGV[3], ARGV[1])
+redis.call('SADD', KEYS[3], ARGV[1])
+
+local extra = redis.call('ZCARD', KEYS[2]) - tonumber(ARGV[4])
+if extra > 0 then
+	local old = redis.call('ZRANGE', KEYS[2], 0, extra - 1)
+	redis.call('ZREMRANGEBYRANK', KEYS[2], 0, extra - 1)
+	for _, id in ipairs(old) do
+		redis.call('HDEL', KEYS[1], id)
+		redis.call('SREM', KEYS[3], id)
+	end
+end
+return redis.call('SCARD', KEYS[3])
+`)
+
+func (r *inboxRepo) Save(ctx context.Context, email string, item *dto.InboxItem) (bool, int64, error) {
+	value, err := json.Marshal(item)
+	if err != nil {
+		return false, 0, err
+	}
+
+	keys := []string{inboxItemsKey(email), inboxOrderKey(email), inboxUnreadKey(email)}
+	unread, err := saveInboxScript.Run(ctx, r.redis, keys, item.ID, value, item.CreatedAt.UnixMilli(), inboxLimit).Int64()
+	if err != nil {
+		return false, 0, err
+	}
+	if unread < 0 {
+		return false, 0, nil
+	}
+	return true, unread, nil
+}
+
+func (r *inboxRepo) GetItems(ctx context.Context, filter *dto.InboxFilter) (*dto.InboxList, error) {
+	email := filter.Email
+	pipe := r.redis.Pipeline()
+	idsCmd := pipe.ZRevRange(ctx, inboxOrderKey(email), filter.Offset, filter.Offset+filter.Limit-1)
+	totalCmd := pipe.ZCard(ctx, inboxOrderKey(email))
+	unreadCmd := pipe.SMembers(ctx, inboxUnreadKey(email))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	response := &dto.InboxList{
+		Items:  []dto.InboxItem{},
+		Total:  totalCmd.Val(),
+		Unread: int64(len(unreadCmd.Val())),
+	}
+	ids := idsCmd.Val()
+	if len(ids) == 0 {
+		return response, nil
+	}
+
+	unread := make(map[string]bool, len(unreadCmd.Val()))
+	for _, id := range unreadCmd.Val() {
+		unread[id] = true
+	}
+
+	values, err := r.redis.HMGet(ctx, inboxItemsKey(email), ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var item dto.InboxItem
+		if err := json.Unmarshal([]byte(raw), &item); err != nil {
+			return nil, err
+		}
+		item.Read = !unread[item.ID]
+		response.Items = append(response.Items, item)
+	}
+
+	return response, nil
+}
+
+// MarkRead mengembalikan jumlah belum dibaca setelah item ditandai dibaca
+func (r *inboxRepo) MarkRead(ctx context.Context, email, id string) (int64, error) {
+	exists, err := r.redis.HExists(ctx, inboxItemsKey(email), id).Result()
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, utils.ErrInboxNotFound
+	}
+
+	pipe := r.redis.TxPipeline()
+	pipe.SRem(ctx, inboxUnreadKey(email), id)
+	countCmd := pipe.SCard(ctx, inboxUnreadKey(email))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return countCmd.Val(), nil
+}
+
+func (r *inboxRepo) MarkAllRead(ctx context.Context, email string) error {
+	return r.redis.Del(ctx, inboxUnreadKey(email)).Err()
+}
+
+func (r *inboxRepo) UnreadCount(ctx context.Context, email string) (int64, error) {
+	return r.redis.SCard(ctx, inboxUnreadKey(email)).Result()
+}
+
+func (r *inboxRepo) Publish(ctx context.Context, email string, event *dto.InboxEvent) error {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return r.redis.Publish(ctx, inboxChannel(email), value).Err()
+}
+
+func (r *inboxRepo) Subscribe(ctx context.Context, email string) *redis.PubSub {
+	return r.redis.Subscribe(ctx, inboxChannel(email))
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"service_notification/dto"
+	"service_notification/helper/utils"
+	"service_notification/internal/repository"
+	"time"
+)
+
+type DeliveryUsecase interface {
+	Delivered(ctx context.Context, key *dto.DeliveryKey, channel string) (bool, error)
+	Record(ctx context.Context, key *dto.DeliveryKey, channel, status string, attempts int, err error) error
+	GetStatus(ctx context.Context, correlationId string) (*dto.DeliveryStatus, error)
+}
+
+type deliveryUsecase struct {
+	deliveryRepo repository.DeliveryRepo
+}
+
+func NewDeliveryUsecase(deliveryRepo repository.DeliveryRepo) DeliveryUsecase {
+	return &deliveryUsecase{deliveryRepo}
+}
+
+// Delivered true kalau notifikasi ini sudah pernah terkirim lewat channel tersebut
+func (u *deliveryUsecase) Delivered(ctx context.Context, key *dto.DeliveryKey, channel string) (bool, error) {
+	attempt, err := u.deliveryRepo.Get(ctx, key, channel)
+	if err != nil {
+		return false, err
+	}
+	return attempt != nil && attempt.Status == dto.DeliverySent, nil
+}
+
+func (u *deliveryUsecase) Record(ctx context.Context, key *dto.DeliveryKey, channel, status string, attempts int, err error) error {
+	attempt := &dto.DeliveryAttempt{
+		Service:   key.Service,
+		Action:    key.Action,
+		Recipient: key.Recipient,
+		Channel:   channel,
+		Status:    status,
+		Attempts:  attempts,
+		UpdatedAt: time.Now(),
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+
+	return u.deliveryRepo.Save(ctx, key, attempt)
+}
+
+func (u *deliveryUsecase) GetStatus(ctx context.Context, correlationId string) (*dto.DeliveryStatus, error) {
+	attempts, err := u.deliveryRepo.GetAll(ctx, correlationId)
+	if err != nil {
+		return nil, err
+	}
+	if len(attempts) == 0 {
+		return nil, utils.ErrDeliveryNotFound
+	}
+
+	return &dto.DeliveryStatus{CorrelationID: correlationId, Deliveries: attempts}, nil
+}
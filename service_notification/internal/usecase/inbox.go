@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"service_notification/dto"
+	"service_notification/internal/repository"
+)
+
+type InboxUsecase interface {
+	Save(ctx context.Context, email string, item *dto.InboxItem) error
+	GetInbox(ctx context.Context, filter *dto.InboxFilter) (*dto.InboxList, error)
+	MarkRead(ctx context.Context, email, id string) error
+	MarkAllRead(ctx context.Context, email string) error
+	UnreadCount(ctx context.Context, email string) (int64, error)
+	Subscribe(ctx context.Context, email string) (<-chan dto.InboxEvent, error)
+}
+
+type inboxUsecase struct {
+	inboxRepo repository.InboxRepo
+}
+
+func NewInboxUsecase(inboxRepo repository.InboxRepo) InboxUsecase {
+	return &inboxUsecase{inboxRepo}
+}
+
+// publish hanya dicatat kalau gagal, data inbox sudah tersimpan dan client
+// tetap melihatnya saat memuat ulang list
+func (u *inboxUsecase) publish(ctx context.Context, email string, event dto.InboxEvent) {
+	if err := u.inboxRepo.Publish(ctx, email, &event); err != nil {
+		log.Printf("publish inbox %s gagal: %s", email, err)
+	}
+}
+
+// Save dipakai channel in-app. Item dengan id yang sudah ada diabaikan.
+func (u *inboxUsecase) Save(ctx context.Context, email string, item *dto.InboxItem) error {
+	created, unread, err := u.inboxRepo.Save(ctx, email, item)
+	if err != nil {
+		return err
+	}
+	if !created {
+		return nil
+	}
+
+	u.publish(ctx, email, dto.InboxEvent{Type: dto.InboxEventNotification, Item: item, Unread: unread})
+	return nil
+}
+
+func (u *inboxUsecase) GetInbox(ctx context.Context, filter *dto.InboxFilter) (*dto.InboxList, error) {
+	return u.inboxRepo.GetItems(ctx, filter)
+}
+
+func (u *inboxUsecase) MarkRead(ctx context.Context, email, id string) error {
+	unread, err := u.inboxRepo.MarkRead(ctx, email, id)
+	if err != nil {
+		return err
+	}
+
+	u.publish(ctx, email, dto.InboxEvent{Type: dto.InboxEventUnread, Unread: unread})
+	return nil
+}
+
+func (u *inboxUsecase) MarkAllRead(ctx context.Context, email string) error {
+	if err := u.inboxRepo.MarkAllRead(ctx, email); err != nil {
+		return err
+	}
+
+	u.publish(ctx, email, dto.InboxEvent{Type: dto.InboxEventUnread, Unread: 0})
+	return nil
+}
+
+func (u *inboxUsecase) UnreadCount(ctx context.Context, email string) (int64, error) {
+	return u.inboxRepo.UnreadCount(ctx, email)
+}
+
+// Subscribe mengalirkan perubahan inbox user sampai ctx selesai. Event pertama
+// selalu jumlah belum dibaca saat ini, diambil setelah subscribe aktif supaya
+// tidak ada notifikasi yang terlewat di antaranya.
+func (u *inboxUsecase) Subscribe(ctx context.Context, email string) (<-chan dto.InboxEvent, error) {
+	pubsub := u.inboxRepo.Subscribe(ctx, email)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	unread, err := u.inboxRepo.UnreadCount(ctx, email)
+	if err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	events := make(chan dto.InboxEvent, 16)
+	events <- dto.InboxEvent{Type: dto.InboxEventUnread, Unread: unread}
+
+	go func() {
+		defer close(events)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				var event dto.InboxEvent
+				if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+					log.Printf("event inbox %s tidak valid: %s", email, err)
+					continue
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
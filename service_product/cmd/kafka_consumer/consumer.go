@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"service_product/dto"
 	"service_product/internal/usecase"
 
 	"time"
@@ -55,7 +56,7 @@ func ProductRequestConsumer(usecase usecase.ProductUsecase, breaker *gobreaker.C
 func ValidationStoreConsumer(redis *redis.Client, breaker *gobreaker.CircuitBreaker) {
 	r := kafka.NewReader(kafka.ReaderConfig{
 		Brokers: []string{os.Getenv("KAFKA_BROKER")},
-		Topic:   "validation-store-response",
+		Topic:   "store-validation-response",
 		GroupID: "product-service",
 	})
 
@@ -64,6 +65,7 @@ func ValidationStoreConsumer(redis *redis.Client, breaker *gobreaker.CircuitBrea
 			msg, err := r.ReadMessage(context.Background())
 			if err != nil {
 				fmt.Printf("error read message : %v", err)
+				continue
 			}
 
 			var payload map[string]interface{}
@@ -72,8 +74,9 @@ func ValidationStoreConsumer(redis *redis.Client, breaker *gobreaker.CircuitBrea
 				continue
 			}
 
-			corrID := payload["correlation_id"].(string)
-			isValid := payload["is_valid"].(bool)
+			// disimpan sebagai json supaya bisa dibaca WaitForResponse
+			corrID, _ := payload["correlation_id"].(string)
+			isValid, _ := json.Marshal(payload["is_valid"])
 
 			key := fmt.Sprintf("response:%s", corrID)
 			_, errBreaker := breaker.Execute(func() (interface{}, error) {
@@ -91,7 +94,7 @@ func ValidationStoreConsumer(redis *redis.Client, breaker *gobreaker.CircuitBrea
 
 func ValidationProductConsumer(usecase usecase.ProductUsecase, breaker *gobreaker.CircuitBreaker) {
 	r := kafka.NewReader(kafka.ReaderConfig{
-		Brokers: []string{"localhost:9002"},
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
 		Topic:   "product-validation-request",
 		GroupID: "product-service",
 	})
@@ -110,8 +113,10 @@ func ValidationProductConsumer(usecase usecase.ProductUsecase, breaker *gobreake
 				continue
 			}
 
-			corrID := payload["correlation_id"].(string)
-			productId := payload["product_id"].(uint)
+			// angka dari json selalu float64
+			corrID, _ := payload["correlation_id"].(string)
+			productIdRaw, _ := payload["product_id"].(float64)
+			productId := uint(productIdRaw)
 
 			if _, errBreaker := breaker.Execute(func() (interface{}, error) {
 				if err := usecase.SendValidationCartResponse(productId, corrID); err != nil {
@@ -126,3 +131,158 @@ func ValidationProductConsumer(usecase usecase.ProductUsecase, breaker *gobreake
 		}
 	}()
 }
+
+func CartItemPaidConsumer(usecase usecase.ProductUsecase, breaker *gobreaker.CircuitBreaker) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   "cart-item-paid",
+		GroupID: "product-service",
+	})
+
+	go func() {
+		for {
+			msg, err := r.ReadMessage(context.Background())
+			if err != nil {
+				fmt.Println("error reading message:", err)
+				continue
+			}
+
+			var payload dto.CartItemPaidKafka
+			if err := json.Unmarshal(msg.Value, &payload); err != nil {
+				fmt.Println("error unmarshaling:", err)
+				continue
+			}
+
+			if _, errBreaker := breaker.Execute(func() (interface{}, error) {
+				return nil, usecase.RecordPurchase(&payload)
+			}); errBreaker != nil {
+				fmt.Printf("record purchase failed or breaker open:%v", errBreaker)
+				continue
+			}
+		}
+	}()
+}
+
+func ProductDetailRequestConsumer(usecase usecase.ProductUsecase, breaker *gobreaker.CircuitBreaker) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   "products-detail-request",
+		GroupID: "product-service",
+	})
+
+	go func() {
+		for {
+			msg, err := r.ReadMessage(context.Background())
+			if err != nil {
+				fmt.Println("error reading message:", err)
+				continue
+			}
+
+			var payload dto.ProductDetailRequestKafka
+			if err := json.Unmarshal(msg.Value, &payload); err != nil {
+				fmt.Println("error unmarshaling:", err)
+				continue
+			}
+
+			if _, errBreaker := breaker.Execute(func() (interface{}, error) {
+				return nil, usecase.SendProductDetailsResponse(payload.ProductIDs, payload.CorrelationID)
+			}); errBreaker != nil {
+				fmt.Printf("write response failed or breaker open:%v", errBreaker)
+				continue
+			}
+		}
+	}()
+}
+
+func StockReserveConsumer(usecase usecase.ProductUsecase, breaker *gobreaker.CircuitBreaker) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   "stock-reserve-request",
+		GroupID: "product-service",
+	})
+
+	go func() {
+		for {
+			msg, err := r.ReadMessage(context.Background())
+			if err != nil {
+				fmt.Println("error reading message:", err)
+				continue
+			}
+
+			var payload dto.StockReserveKafka
+			if err := json.Unmarshal(msg.Value, &payload); err != nil {
+				fmt.Println("error unmarshaling:", err)
+				continue
+			}
+
+			if _, errBreaker := breaker.Execute(func() (interface{}, error) {
+				return nil, usecase.ReserveStock(&payload)
+			}); errBreaker != nil {
+				fmt.Printf("reserve stock failed or breaker open:%v", errBreaker)
+				continue
+			}
+		}
+	}()
+}
+
+func StockReleaseConsumer(usecase usecase.ProductUsecase, breaker *gobreaker.CircuitBreaker) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   "stock-release-request",
+		GroupID: "product-service",
+	})
+
+	go func() {
+		for {
+			msg, err := r.ReadMessage(context.Background())
+			if err != nil {
+				fmt.Println("error reading message:", err)
+				continue
+			}
+
+			var payload dto.StockReleaseKafka
+			if err := json.Unmarshal(msg.Value, &payload); err != nil {
+				fmt.Println("error unmarshaling:", err)
+				continue
+			}
+
+			if _, errBreaker := breaker.Execute(func() (interface{}, error) {
+				return nil, usecase.ReleaseStock(&payload)
+			}); errBreaker != nil {
+				fmt.Printf("release stock failed or breaker open:%v", errBreaker)
+				continue
+			}
+		}
+	}()
+}
+
+func StockRestockConsumer(usecase usecase.ProductUsecase, breaker *gobreaker.CircuitBreaker) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   "stock-restock-request",
+		GroupID: "product-service",
+	})
+
+	go func() {
+		for {
+			msg, err := r.ReadMessage(context.Background())
+			if err != nil {
+				fmt.Println("error reading message:", err)
+				continue
+			}
+
+			var payload dto.StockRestockKafka
+			if err := json.Unmarshal(msg.Value, &payload); err != nil {
+				fmt.Println("error unmarshaling:", err)
+				continue
+			}
+
+			if _, errBreaker := breaker.Execute(func() (interface{}, error) {
+				return nil, usecase.RestockProduct(&payload)
+			}); errBreaker != nil {
+				fmt.Printf("restock failed or breaker open:%v", errBreaker)
+				continue
+			}
+		}
+	}()
+}
@@ -8,6 +8,7 @@ import (
 	"service_product/cmd/database"
 	kafkaconsumer "service_product/cmd/kafka_consumer"
 	"service_product/cmd/route"
+	"service_product/cmd/scheduler"
 	"service_product/internal/handler"
 	"service_product/internal/repository"
 	"service_product/internal/usecase"
@@ -32,14 +33,34 @@ func main() {
 			Topic:    "products-response",
 			Balancer: &kafka.LeastBytes{},
 		}),
-		"validation-store-request": kafka.NewWriter(kafka.WriterConfig{
+		"store-validation-request": kafka.NewWriter(kafka.WriterConfig{
 			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
-			Topic:    "validation-store-request",
+			Topic:    "store-validation-request",
 			Balancer: &kafka.LeastBytes{},
 		}),
-		"validation-product-response": kafka.NewWriter(kafka.WriterConfig{
+		"product-validation-response": kafka.NewWriter(kafka.WriterConfig{
 			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
-			Topic:    "validation-product-response",
+			Topic:    "product-validation-response",
+			Balancer: &kafka.LeastBytes{},
+		}),
+		"products-detail-response": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "products-detail-response",
+			Balancer: &kafka.LeastBytes{},
+		}),
+		"stock-reserve-response": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "stock-reserve-response",
+			Balancer: &kafka.LeastBytes{},
+		}),
+		"product-price-changed": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "product-price-changed",
+			Balancer: &kafka.LeastBytes{},
+		}),
+		"notification-request": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "notification-request",
 			Balancer: &kafka.LeastBytes{},
 		}),
 	}
@@ -66,6 +87,13 @@ func main() {
 	go kafkaconsumer.ProductRequestConsumer(productUC, cb)
 	go kafkaconsumer.ValidationStoreConsumer(rdb, cb)
 	go kafkaconsumer.ProductRequestConsumer(productUC, cb)
+	go kafkaconsumer.ValidationProductConsumer(productUC, cb)
+	go kafkaconsumer.CartItemPaidConsumer(productUC, cb)
+	go kafkaconsumer.ProductDetailRequestConsumer(productUC, cb)
+	go kafkaconsumer.StockReserveConsumer(productUC, cb)
+	go kafkaconsumer.StockReleaseConsumer(productUC, cb)
+	go kafkaconsumer.StockRestockConsumer(productUC, cb)
+	go scheduler.PriceScheduler(productUC, 30*time.Second)
 
 	fmt.Printf("service product berjalan pada port:%s", port)
 	http.ListenAndServe(":"+port, r)
@@ -13,7 +13,7 @@ func main() {
 		log.Fatal(err)
 	}
 
-	if err := db.AutoMigrate(&entity.Product{}); err != nil {
+	if err := db.AutoMigrate(&entity.Product{}, &entity.PriceHistory{}, &entity.ScheduledPrice{}, &entity.Review{}, &entity.VerifiedPurchase{}, &entity.PurchaseLog{}, &entity.CoPurchase{}, &entity.StockReservation{}, &entity.StockRestock{}); err != nil {
 		log.Fatal(err)
 	}
 
@@ -18,6 +18,12 @@ func SetupRoute(product *handler.StoreHandler) *mux.Router {
 	useM.HandleFunc("/delete/{storeId}/{productId}", product.DeleteProduct).Methods(http.MethodDelete)
 	useM.HandleFunc("/getall", product.GetAllProduct).Methods(http.MethodGet)
 	useM.HandleFunc("/get/{productId}", product.GetThisProduct).Methods(http.MethodGet)
+	useM.HandleFunc("/cache-stats", product.GetCacheStats).Methods(http.MethodGet)
+	useM.HandleFunc("/price/{storeId}/{productId}", product.SchedulePriceChange).Methods(http.MethodPost)
+	useM.HandleFunc("/price/{productId}", product.GetProductPrices).Methods(http.MethodGet)
+	useM.HandleFunc("/review/{productId}", product.CreateReview).Methods(http.MethodPost)
+	useM.HandleFunc("/review/{productId}", product.GetReviews).Methods(http.MethodGet)
+	useM.HandleFunc("/{productId:[0-9]+}/related", product.GetRelatedProducts).Methods(http.MethodGet)
 
 	return r
 }
@@ -0,0 +1,19 @@
+package scheduler
+
+import (
+	"fmt"
+	"service_product/internal/usecase"
+	"time"
+)
+
+func PriceScheduler(usecase usecase.ProductUsecase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		for range ticker.C {
+			if err := usecase.ApplyScheduledPrices(); err != nil {
+				fmt.Println("apply scheduled price failed:", err)
+			}
+		}
+	}()
+}
@@ -3,20 +3,26 @@ package dto
 import "time"
 
 type CreateProductReq struct {
-	Email   string `json:"-"`
-	UserID  uint   `json:"-"`
-	StoreID uint   `json:"-"`
-	Name    string `json:"name"`
-	Stock   int    `json:"stock"`
+	Email    string `json:"-"`
+	UserID   uint   `json:"-"`
+	StoreID  uint   `json:"-"`
+	Name     string `json:"name"`
+	Stock    int    `json:"stock"`
+	Price    int64  `json:"price"`
+	Weight   int    `json:"weight"`
+	Category string `json:"category"`
 }
 
 type UpdateProductReq struct {
-	Email   string `json:"-"`
-	ID      uint   `json:"-"`
-	UserID  uint   `json:"-"`
-	StoreID uint   `json:"-"`
-	Name    string `json:"name"`
-	Stock   int    `json:"stock"`
+	Email    string `json:"-"`
+	ID       uint   `json:"-"`
+	UserID   uint   `json:"-"`
+	StoreID  uint   `json:"-"`
+	Version  uint   `json:"-"`
+	Name     string `json:"name"`
+	Stock    int    `json:"stock"`
+	Weight   int    `json:"weight"`
+	Category string `json:"category"`
 }
 
 type Product struct {
@@ -24,18 +30,137 @@ type Product struct {
 	ID        uint      `json:"id"`
 	Name      string    `json:"name"`
 	Stock     int       `json:"stock"`
+	Price     int64     `json:"price"`
+	Weight    int       `json:"weight"`
+	Category  string    `json:"category"`
+	Version   uint      `json:"version"`
 	CreatedAt time.Time `json:"created_at"`
+
+	RatingAverage float64 `json:"rating_average"`
+	RatingCount   int     `json:"rating_count"`
 }
 
 type ProductKafka struct {
 	Name      string
 	Stock     int
+	Price     int64
 	StoreID   uint
 	CreatedAt time.Time
 }
 
+type ProductDetailRequestKafka struct {
+	CorrelationID string `json:"correlation_id"`
+	ProductIDs    []uint `json:"product_ids"`
+}
+
+type ProductDetailKafka struct {
+	ProductID uint   `json:"product_id"`
+	StoreID   uint   `json:"store_id"`
+	Name      string `json:"name"`
+	Price     int64  `json:"price"`
+	Stock     int    `json:"stock"`
+	Weight    int    `json:"weight"`
+	Category  string `json:"category"`
+	Deleted   bool   `json:"deleted"`
+}
+
 type ValidationProductKafka struct {
 	ProductId uint
 	Stock     int
+	Price     int64
 	Deleted   bool
 }
+
+// price
+type SchedulePriceReq struct {
+	Email         string    `json:"-"`
+	UserID        uint      `json:"-"`
+	StoreID       uint      `json:"-"`
+	ProductID     uint      `json:"-"`
+	Price         int64     `json:"price"`
+	EffectiveFrom time.Time `json:"effective_from"`
+}
+
+type PriceHistory struct {
+	OldPrice  int64     `json:"old_price"`
+	NewPrice  int64     `json:"new_price"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+type ScheduledPrice struct {
+	ID            uint      `json:"id"`
+	ProductID     uint      `json:"product_id"`
+	Price         int64     `json:"price"`
+	EffectiveFrom time.Time `json:"effective_from"`
+}
+
+type ProductPrices struct {
+	ProductID    uint             `json:"product_id"`
+	CurrentPrice int64            `json:"current_price"`
+	History      []PriceHistory   `json:"history"`
+	Scheduled    []ScheduledPrice `json:"scheduled"`
+}
+
+type PriceChangedKafka struct {
+	ScheduleID uint      `json:"-"`
+	ProductID  uint      `json:"product_id"`
+	StoreID    uint      `json:"store_id"`
+	OldPrice   int64     `json:"old_price"`
+	NewPrice   int64     `json:"new_price"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+// review
+type CreateReviewReq struct {
+	UserID    uint   `json:"-"`
+	ProductID uint   `json:"-"`
+	Rating    int    `json:"rating"`
+	Text      string `json:"text"`
+}
+
+type Review struct {
+	ID        uint      `json:"id"`
+	ProductID uint      `json:"product_id"`
+	UserID    uint      `json:"user_id"`
+	Rating    int       `json:"rating"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CartItemPaidKafka struct {
+	CartItemID     uint      `json:"cart_item_id"`
+	UserID         uint      `json:"user_id"`
+	ProductID      uint      `json:"product_id"`
+	PurchaseAmount int       `json:"purchase_amount"`
+	PaidAt         time.Time `json:"paid_at"`
+}
+
+// saga
+type StockItemKafka struct {
+	ProductID uint `json:"product_id"`
+	Quantity  int  `json:"quantity"`
+}
+
+type StockReserveKafka struct {
+	CorrelationID string           `json:"correlation_id"`
+	OrderID       uint             `json:"order_id"`
+	Items         []StockItemKafka `json:"items"`
+}
+
+type StockReleaseKafka struct {
+	CorrelationID string `json:"correlation_id"`
+	OrderID       uint   `json:"order_id"`
+}
+
+type StockRestockKafka struct {
+	ReturnID  uint `json:"return_id"`
+	OrderID   uint `json:"order_id"`
+	ProductID uint `json:"product_id"`
+	Quantity  int  `json:"quantity"`
+}
+
+type SagaReplyKafka struct {
+	CorrelationID string `json:"correlation_id"`
+	Success       bool   `json:"success"`
+	Reason        string `json:"reason,omitempty"`
+}
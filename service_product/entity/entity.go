@@ -6,8 +6,99 @@ type Product struct {
 	ID    uint   `gorm:"primaryKey"`
 	Name  string `gorm:"not null"`
 	Stock int    `gorm:"not null"`
+	Price int64  `gorm:"not null;default:0"`
+
+	//berat dalam gram, dipakai service cart untuk ongkir
+	Weight int `gorm:"not null;default:0"`
+
+	//kategori untuk tarif pajak, kosong berarti kategori umum
+	Category string `gorm:"type:varchar(50);not null;default:''"`
+
+	//review
+	RatingAverage float64 `gorm:"not null;default:0"`
+	RatingCount   int     `gorm:"not null;default:0"`
+
+	//best seller
+	SoldCount int `gorm:"not null;default:0"`
+
+	//optimistic lock
+	Version uint `gorm:"not null;default:1"`
 
 	//store
 	StoreID   uint `gorm:"index"`
 	CreatedAt time.Time
 }
+
+type PriceHistory struct {
+	ID        uint  `gorm:"primaryKey"`
+	ProductID uint  `gorm:"index"`
+	OldPrice  int64 `gorm:"not null"`
+	NewPrice  int64 `gorm:"not null"`
+	ChangedAt time.Time
+}
+
+// jadwal yang sudah diterapkan juga jadi outbox event product-price-changed,
+// Published false sampai event berhasil dikirim
+type ScheduledPrice struct {
+	ID            uint `gorm:"primaryKey"`
+	ProductID     uint `gorm:"index"`
+	StoreID       uint
+	Price         int64 `gorm:"not null"`
+	OldPrice      int64
+	EffectiveFrom time.Time `gorm:"index"`
+	AppliedAt     *time.Time
+	Published     bool `gorm:"index;not null;default:true"`
+	CreatedAt     time.Time
+}
+
+type Review struct {
+	ID        uint   `gorm:"primaryKey"`
+	ProductID uint   `gorm:"uniqueIndex:idx_review_product_user"`
+	UserID    uint   `gorm:"uniqueIndex:idx_review_product_user"`
+	Rating    int    `gorm:"not null"`
+	Text      string `gorm:"type:text"`
+	CreatedAt time.Time
+}
+
+// proyeksi lokal dari event cart-item-paid
+type VerifiedPurchase struct {
+	ID        uint `gorm:"primaryKey"`
+	UserID    uint `gorm:"uniqueIndex:idx_purchase_user_product"`
+	ProductID uint `gorm:"uniqueIndex:idx_purchase_user_product"`
+	CreatedAt time.Time
+}
+
+// satu baris per cart item yang dibayar, dipakai untuk membentuk sesi belanja
+type PurchaseLog struct {
+	ID         uint `gorm:"primaryKey"`
+	CartItemID uint `gorm:"uniqueIndex"`
+	UserID     uint `gorm:"index:idx_purchase_log_user_paid"`
+	ProductID  uint
+	PaidAt     time.Time `gorm:"index:idx_purchase_log_user_paid"`
+}
+
+type CoPurchase struct {
+	ProductID        uint `gorm:"primaryKey"`
+	RelatedProductID uint `gorm:"primaryKey"`
+	Frequency        int  `gorm:"not null;default:0"`
+}
+
+// stok yang ditahan oleh saga checkout di service cart
+type StockReservation struct {
+	ID        uint   `gorm:"primaryKey"`
+	SagaID    string `gorm:"type:varchar(36);index"`
+	ProductID uint   `gorm:"index"`
+	Quantity  int    `gorm:"not null"`
+	Released  bool   `gorm:"default:false"`
+	CreatedAt time.Time
+}
+
+// stok yang dikembalikan dari retur yang sudah direfund, satu baris per retur
+type StockRestock struct {
+	ID        uint `gorm:"primaryKey"`
+	ReturnID  uint `gorm:"uniqueIndex"`
+	OrderID   uint `gorm:"index"`
+	ProductID uint `gorm:"index"`
+	Quantity  int  `gorm:"not null"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrNotFound dikembalikan loader ketika data memang tidak ada,
+// supaya cache bisa menyimpan negative entry.
+var ErrNotFound = errors.New("data tidak ditemukan")
+
+const negativeValue = "__not_found__"
+
+type Stats struct {
+	Hits         uint64 `json:"hits"`
+	NegativeHits uint64 `json:"negative_hits"`
+	Misses       uint64 `json:"misses"`
+}
+
+type ReadThrough struct {
+	redis       *redis.Client
+	group       singleflight.Group
+	ttl         time.Duration
+	negativeTTL time.Duration
+
+	hits         atomic.Uint64
+	negativeHits atomic.Uint64
+	misses       atomic.Uint64
+}
+
+func NewReadThrough(redis *redis.Client, ttl, negativeTTL time.Duration) *ReadThrough {
+	return &ReadThrough{
+		redis:       redis,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+	}
+}
+
+// Get membaca key dari redis ke out. Saat miss, load dipanggil sekali saja
+// untuk semua request yang bersamaan pada key yang sama, lalu hasilnya disimpan.
+func (c *ReadThrough) Get(ctx context.Context, key string, out interface{}, load func() (interface{}, error)) error {
+	cached, err := c.redis.Get(ctx, key).Result()
+	if err == nil {
+		if cached == negativeValue {
+			c.negativeHits.Add(1)
+			return ErrNotFound
+		}
+		if err := json.Unmarshal([]byte(cached), out); err == nil {
+			c.hits.Add(1)
+			return nil
+		}
+	} else if err != redis.Nil {
+		log.Printf("cache: redis get %s: %v", key, err)
+	}
+
+	c.misses.Add(1)
+	data, err, _ := c.group.Do(key, func() (interface{}, error) {
+		value, err := load()
+		if errors.Is(err, ErrNotFound) {
+			if err := c.redis.Set(ctx, key, negativeValue, c.negativeTTL).Err(); err != nil {
+				log.Printf("cache: redis set %s: %v", key, err)
+			}
+			return nil, ErrNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.redis.Set(ctx, key, data, jitter(c.ttl)).Err(); err != nil {
+			log.Printf("cache: redis set %s: %v", key, err)
+		}
+		return data, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data.([]byte), out)
+}
+
+func (c *ReadThrough) Invalidate(ctx context.Context, keys ...string) error {
+	return c.redis.Del(ctx, keys...).Err()
+}
+
+func (c *ReadThrough) Stats() Stats {
+	return Stats{
+		Hits:         c.hits.Load(),
+		NegativeHits: c.negativeHits.Load(),
+		Misses:       c.misses.Load(),
+	}
+}
+
+// jitter menambah sampai 10% ttl supaya key tidak expire bersamaan.
+func jitter(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return ttl
+	}
+	return ttl + time.Duration(rand.Int63n(int64(ttl)/10+1))
+}
@@ -9,4 +9,10 @@ var (
 	ErrNoStore          = errors.New("tidak ada store")
 	ErrNoTopic          = errors.New("bukan ada topic ini")
 	ErrFailedKafkaWrite = errors.New("gagal  mengirim message ")
+	ErrNoProduct        = errors.New("tidak ada product")
+	ErrInvalidETag      = errors.New("etag tidak valid")
+	ErrVersionConflict  = errors.New("data sudah diubah, version tidak cocok")
+	ErrNotBuyer         = errors.New("hanya pembeli yang bisa memberi review")
+	ErrAlreadyReviewed  = errors.New("kau sudah memberi review product ini")
+	ErrStockNotEnough   = errors.New("stok product tidak cukup")
 )
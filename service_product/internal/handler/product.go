@@ -8,6 +8,8 @@ import (
 	"service_product/helper/utils"
 	"service_product/internal/usecase"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 )
@@ -37,6 +39,19 @@ func (h *StoreHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 		utils.WriteError(w, http.StatusBadRequest, "invalid stock")
 		return
 	}
+	if req.Weight < 0 {
+		utils.WriteError(w, http.StatusBadRequest, "invalid weight")
+		return
+	}
+	req.Category = strings.ToLower(strings.TrimSpace(req.Category))
+	if len(req.Category) > 50 {
+		utils.WriteError(w, http.StatusBadRequest, "invalid category")
+		return
+	}
+	if req.Price < 0 {
+		utils.WriteError(w, http.StatusBadRequest, "invalid price")
+		return
+	}
 
 	params := mux.Vars(r)
 	paramsStoreId, _ := strconv.Atoi(params["storeId"])
@@ -71,6 +86,17 @@ func (h *StoreHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 	paramsProductId, _ := strconv.Atoi(params["productId"])
 	paramsStoreId, _ := strconv.Atoi(params["storeId"])
 
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		utils.WriteError(w, http.StatusPreconditionRequired, "header If-Match wajib diisi")
+		return
+	}
+	version, err := utils.ParseETag(ifMatch)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	var req dto.UpdateProductReq
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utils.WriteError(w, http.StatusBadRequest, "invalid body")
@@ -80,22 +106,39 @@ func (h *StoreHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 		utils.WriteError(w, http.StatusBadRequest, "invalid stock")
 		return
 	}
+	if req.Weight < 0 {
+		utils.WriteError(w, http.StatusBadRequest, "invalid weight")
+		return
+	}
+	req.Category = strings.ToLower(strings.TrimSpace(req.Category))
+	if len(req.Category) > 50 {
+		utils.WriteError(w, http.StatusBadRequest, "invalid category")
+		return
+	}
 
 	req.Email = claims.Email
 	req.ID = uint(paramsProductId)
 	req.StoreID = uint(paramsStoreId)
 	req.UserID = claims.UserID
+	req.Version = version
 	if err := h.shopUsecase.UpdateProduct(&req); err != nil {
 		switch err {
 		case utils.ErrNotAdmin:
 			utils.WriteError(w, http.StatusUnauthorized, "bukan admin")
 			return
+		case utils.ErrNoProduct:
+			utils.WriteError(w, http.StatusNotFound, "product tidak ditemukan")
+			return
+		case utils.ErrVersionConflict:
+			utils.WriteError(w, http.StatusPreconditionFailed, "product sudah diubah, ambil ulang data terbaru")
+			return
 		default:
 			utils.WriteError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 	}
 
+	w.Header().Set("ETag", utils.FormatETag(req.Version+1))
 	utils.WriteJSON(w, http.StatusOK, nil)
 }
 
@@ -165,6 +208,182 @@ func (h *StoreHandler) GetThisProduct(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response, err := h.shopUsecase.GetProduct(uint(paramsProductId))
+	if err != nil {
+		switch err {
+		case utils.ErrNoProduct:
+			utils.WriteError(w, http.StatusNotFound, "product tidak ditemukan")
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	w.Header().Set("ETag", utils.FormatETag(response.Version))
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *StoreHandler) GetCacheStats(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	_, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, h.shopUsecase.CacheStats())
+}
+
+func (h *StoreHandler) SchedulePriceChange(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsStoreId, err := strconv.Atoi(params["storeId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+	paramsProductId, err := strconv.Atoi(params["productId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	var req dto.SchedulePriceReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	if req.Price < 0 {
+		utils.WriteError(w, http.StatusBadRequest, "invalid price")
+		return
+	}
+	// tanpa effective_from harga berlaku pada jalannya scheduler berikutnya
+	if req.EffectiveFrom.IsZero() {
+		req.EffectiveFrom = time.Now()
+	}
+
+	req.Email = claims.Email
+	req.UserID = claims.UserID
+	req.StoreID = uint(paramsStoreId)
+	req.ProductID = uint(paramsProductId)
+	response, err := h.shopUsecase.SchedulePriceChange(&req)
+	if err != nil {
+		switch err {
+		case utils.ErrNotAdmin:
+			utils.WriteError(w, http.StatusUnauthorized, "bukan admin")
+			return
+		case utils.ErrNoProduct:
+			utils.WriteError(w, http.StatusNotFound, "product tidak ditemukan")
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *StoreHandler) GetProductPrices(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	_, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsProductId, err := strconv.Atoi(params["productId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	response, err := h.shopUsecase.GetProductPrices(uint(paramsProductId))
+	if err != nil {
+		switch err {
+		case utils.ErrNoProduct:
+			utils.WriteError(w, http.StatusNotFound, "product tidak ditemukan")
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *StoreHandler) CreateReview(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsProductId, err := strconv.Atoi(params["productId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	var req dto.CreateReviewReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	if req.Rating < 1 || req.Rating > 5 {
+		utils.WriteError(w, http.StatusBadRequest, "rating harus 1 sampai 5")
+		return
+	}
+
+	req.UserID = claims.UserID
+	req.ProductID = uint(paramsProductId)
+	response, err := h.shopUsecase.CreateReview(&req)
+	if err != nil {
+		switch err {
+		case utils.ErrNotBuyer:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		case utils.ErrAlreadyReviewed:
+			utils.WriteError(w, http.StatusConflict, err.Error())
+			return
+		case utils.ErrNoProduct:
+			utils.WriteError(w, http.StatusNotFound, "product tidak ditemukan")
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *StoreHandler) GetReviews(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	_, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsProductId, err := strconv.Atoi(params["productId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	response, err := h.shopUsecase.GetReviews(uint(paramsProductId))
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -172,3 +391,42 @@ func (h *StoreHandler) GetThisProduct(w http.ResponseWriter, r *http.Request) {
 
 	utils.WriteJSON(w, http.StatusOK, response)
 }
+
+func (h *StoreHandler) GetRelatedProducts(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	_, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsProductId, err := strconv.Atoi(params["productId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	limit := 5
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > 50 {
+			utils.WriteError(w, http.StatusBadRequest, "limit harus 1 sampai 50")
+			return
+		}
+	}
+
+	response, err := h.shopUsecase.GetRelatedProducts(uint(paramsProductId), limit)
+	if err != nil {
+		switch err {
+		case utils.ErrNoProduct:
+			utils.WriteError(w, http.StatusNotFound, "product tidak ditemukan")
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
@@ -7,11 +7,14 @@ import (
 	"fmt"
 	"service_product/dto"
 	"service_product/entity"
-	"strconv"
+	"service_product/helper/cache"
+	"service_product/helper/utils"
 	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProductRepo interface {
@@ -20,9 +23,32 @@ type ProductRepo interface {
 	CreateProduct(req *dto.CreateProductReq) (*dto.Product, error)
 	UpdateProduct(req *dto.UpdateProductReq) (*dto.Product, error)
 	DeleteProduct(id uint) error
+	CacheStats() cache.Stats
+
+	//price
+	CreateScheduledPrice(req *dto.SchedulePriceReq) (*dto.ScheduledPrice, error)
+	GetProductPrices(productId uint) (*dto.ProductPrices, error)
+	ApplyDuePrices(now time.Time) error
+	GetUnpublishedPrices(limit int) ([]dto.PriceChangedKafka, error)
+	MarkPricePublished(scheduleId uint) error
+
+	//review
+	CreateReview(req *dto.CreateReviewReq) (*dto.Review, error)
+	GetReviews(productId uint) ([]dto.Review, error)
+	RecordPurchase(userId, productId uint) error
+
+	//recommendation
+	RecordCoPurchase(paid *dto.CartItemPaidKafka) error
+	GetRelatedProducts(productId uint, limit int) ([]dto.Product, error)
+
+	//saga
+	ReserveStock(sagaId string, items []dto.StockItemKafka) error
+	ReleaseStock(sagaId string) error
+	RestockProduct(req *dto.StockRestockKafka) error
 
 	//kafka
 	GetProductByStoreId(storeId uint) ([]dto.ProductKafka, error)
+	GetProductDetails(productIds []uint) ([]dto.ProductDetailKafka, error)
 	ProductValidation(productId uint) (*dto.ValidationProductKafka, error)
 	WaitForResponse(correlationID string, out interface{}) error
 }
@@ -30,77 +56,115 @@ type ProductRepo interface {
 type productRepo struct {
 	db    *gorm.DB
 	redis *redis.Client
+	cache *cache.ReadThrough
 }
 
 func NewStoreRepo(db *gorm.DB, redis *redis.Client) ProductRepo {
-	return &productRepo{db, redis}
+	return &productRepo{db, redis, cache.NewReadThrough(redis, 30*time.Minute, 30*time.Second)}
 }
 
 var ctx = context.Background()
 
+const allProductKey = "products:all"
+
+// pembelian user yang berjarak kurang dari ini dianggap satu sesi belanja
+const purchaseSessionWindow = time.Hour
+
+func productKey(id uint) string {
+	return fmt.Sprintf("product:%d", id)
+}
+
+func toProductDTO(p *entity.Product) dto.Product {
+	return dto.Product{
+		ID:        p.ID,
+		StoreID:   p.StoreID,
+		Name:      p.Name,
+		Stock:     p.Stock,
+		Price:     p.Price,
+		Weight:    p.Weight,
+		Category:  p.Category,
+		Version:   p.Version,
+		CreatedAt: p.CreatedAt,
+
+		RatingAverage: p.RatingAverage,
+		RatingCount:   p.RatingCount,
+	}
+}
+
 func (r *productRepo) CreateProduct(req *dto.CreateProductReq) (*dto.Product, error) {
 	newProduct := entity.Product{
-		Name:    req.Name,
-		StoreID: req.StoreID,
-		Stock:   req.Stock,
+		Name:     req.Name,
+		StoreID:  req.StoreID,
+		Stock:    req.Stock,
+		Price:    req.Price,
+		Weight:   req.Weight,
+		Category: req.Category,
+		Version:  1,
 	}
 
-	if err := r.db.Create(&newProduct).Error; err != nil {
+	tx := r.db.Begin()
+	if err := tx.Create(&newProduct).Error; err != nil {
+		tx.Rollback()
 		return nil, err
 	}
 
-	key := fmt.Sprintf("product:%d", newProduct.ID)
+	history := entity.PriceHistory{
+		ProductID: newProduct.ID,
+		NewPrice:  newProduct.Price,
+		ChangedAt: newProduct.CreatedAt,
+	}
+	if err := tx.Create(&history).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
 
-	_, err := r.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
-		pipe.HSet(ctx, key, map[string]interface{}{
-			"name":       newProduct.Name,
-			"store_id":   newProduct.StoreID,
-			"stock":      newProduct.Stock,
-			"created_at": newProduct.CreatedAt,
-		})
-		pipe.Expire(ctx, key, 30*time.Minute)
-		pipe.Del(ctx, "products:all")
-		return nil
-	})
-	if err != nil {
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	// hapus juga negative entry kalau id ini pernah dicari
+	if err := r.cache.Invalidate(ctx, productKey(newProduct.ID), allProductKey); err != nil {
 		return nil, fmt.Errorf("redis: %v", err)
 	}
 
-	return &dto.Product{
-		ID:        newProduct.ID,
-		Name:      newProduct.Name,
-		Stock:     newProduct.Stock,
-		CreatedAt: newProduct.CreatedAt,
-	}, nil
+	product := toProductDTO(&newProduct)
+	return &product, nil
 }
 
 func (r *productRepo) UpdateProduct(req *dto.UpdateProductReq) (*dto.Product, error) {
-	if err := r.db.Model(&entity.Product{}).Where("id = ?", req.ID).Updates(map[string]interface{}{
-		"name":  req.Name,
-		"stock": req.Stock,
-	}).Error; err != nil {
-		return nil, err
+	result := r.db.Model(&entity.Product{}).Where("id = ? AND version = ?", req.ID, req.Version).Updates(map[string]interface{}{
+		"name":     req.Name,
+		"stock":    req.Stock,
+		"weight":   req.Weight,
+		"category": req.Category,
+		"version":  gorm.Expr("version + 1"),
+	})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		var count int64
+		if err := r.db.Model(&entity.Product{}).Where("id = ?", req.ID).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, utils.ErrNoProduct
+		}
+		return nil, utils.ErrVersionConflict
 	}
 
-	key := fmt.Sprintf("product:%d", req.ID)
-
-	_, err := r.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
-		pipe.HSet(ctx, key,
-			"name", req.Name,
-			"stock", req.Stock,
-		)
-		pipe.Del(ctx, "products:all")
-		return nil
-	})
-	if err != nil {
+	if err := r.cache.Invalidate(ctx, productKey(req.ID), allProductKey); err != nil {
 		return nil, fmt.Errorf("redis: %v", err)
 	}
 
 	return &dto.Product{
-		ID:      req.ID,
-		StoreID: req.StoreID,
-		Name:    req.Name,
-		Stock:   req.Stock,
+		ID:       req.ID,
+		StoreID:  req.StoreID,
+		Name:     req.Name,
+		Stock:    req.Stock,
+		Weight:   req.Weight,
+		Category: req.Category,
+		Version:  req.Version + 1,
 	}, nil
 }
 
@@ -115,16 +179,11 @@ func (r *productRepo) DeleteProduct(id uint) error {
 		return err
 	}
 
-	tx.Commit()
-
-	key := fmt.Sprintf("product:%d", id)
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
 
-	_, err := r.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
-		pipe.HDel(ctx, key)
-		pipe.Del(ctx, "products:all")
-		return nil
-	})
-	if err != nil {
+	if err := r.cache.Invalidate(ctx, productKey(id), allProductKey); err != nil {
 		return fmt.Errorf("redis: %v", err)
 	}
 
@@ -132,92 +191,104 @@ func (r *productRepo) DeleteProduct(id uint) error {
 }
 
 func (r *productRepo) GetProduct(id uint) (*dto.Product, error) {
-	key := fmt.Sprintf("product:%d", id)
-
-	var product entity.Product
-	data, err := r.redis.HGetAll(ctx, key).Result()
-	if err == nil {
-		stock, _ := strconv.Atoi(data["stock"])
-		storeID, _ := strconv.Atoi(data["store_id"])
-		createdAt, _ := time.Parse(time.RFC3339, data["created_at"])
-
-		product := dto.Product{
-			ID:        id,
-			Name:      data["name"],
-			Stock:     stock,
-			StoreID:   uint(storeID),
-			CreatedAt: createdAt,
+	var product dto.Product
+	err := r.cache.Get(ctx, productKey(id), &product, func() (interface{}, error) {
+		var p entity.Product
+		if err := r.db.First(&p, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, cache.ErrNotFound
+			}
+			return nil, err
 		}
-
-		fmt.Println("data dari redis")
-		return &product, nil
+		return toProductDTO(&p), nil
+	})
+	if errors.Is(err, cache.ErrNotFound) {
+		return nil, utils.ErrNoProduct
 	}
-
-	if err := r.db.First(&product, id).Error; err != nil {
+	if err != nil {
 		return nil, err
 	}
 
-	fmt.Println("data dari mysql")
-	return &dto.Product{
-		ID:        product.ID,
-		StoreID:   product.StoreID,
-		Name:      product.Name,
-		Stock:     product.Stock,
-		CreatedAt: product.CreatedAt,
-	}, nil
+	return &product, nil
 }
 
 func (r *productRepo) GetAllProduct() ([]dto.Product, error) {
-	cached, err := r.redis.Get(ctx, "products:all").Result()
-	if err == nil {
-		var products []dto.Product
-		if json.Unmarshal([]byte(cached), &products) == nil {
-			fmt.Println("data dari redis")
-			return products, nil
+	var result []dto.Product
+	err := r.cache.Get(ctx, allProductKey, &result, func() (interface{}, error) {
+		var products []entity.Product
+		if err := r.db.Find(&products).Error; err != nil {
+			return nil, err
 		}
-	}
 
-	var products []entity.Product
-	if err := r.db.Find(&products).Error; err != nil {
+		result := make([]dto.Product, 0, len(products))
+		for i := range products {
+			result = append(result, toProductDTO(&products[i]))
+		}
+		return result, nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	var result []dto.Product
-	for _, p := range products {
-		result = append(result, dto.Product{
-			ID:        p.ID,
-			StoreID:   p.StoreID,
-			Name:      p.Name,
-			Stock:     p.Stock,
-			CreatedAt: p.CreatedAt,
-		})
-	}
-
-	jsonData, _ := json.Marshal(result)
-	if err := r.redis.Set(ctx, "products:all", jsonData, 30*time.Minute); err != nil {
-		return nil, fmt.Errorf("redis: %v", err)
-	}
-
-	fmt.Println("data dari mysql")
 	return result, nil
 }
 
+func (r *productRepo) CacheStats() cache.Stats {
+	return r.cache.Stats()
+}
+
 func (r *productRepo) GetProductByStoreId(storeId uint) ([]dto.ProductKafka, error) {
 	var products []dto.ProductKafka
-	if err := r.db.Model(&entity.Product{}).Select("id, name, stock, created_at").Where("store_id = ?", storeId).Order("created_at ASC").Find(&products).Error; err != nil {
+	if err := r.db.Model(&entity.Product{}).Select("id, name, stock, price, created_at").Where("store_id = ?", storeId).Order("created_at ASC").Find(&products).Error; err != nil {
 		return nil, err
 	}
 
 	return products, nil
 }
 
+func (r *productRepo) GetProductDetails(productIds []uint) ([]dto.ProductDetailKafka, error) {
+	var products []entity.Product
+	if len(productIds) > 0 {
+		if err := r.db.Select("id", "store_id", "name", "price", "stock", "weight", "category").Where("id IN ?", productIds).Find(&products).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	found := make(map[uint]entity.Product, len(products))
+	for _, p := range products {
+		found[p.ID] = p
+	}
+
+	// id yang tidak ditemukan dianggap sudah dihapus
+	result := make([]dto.ProductDetailKafka, 0, len(productIds))
+	for _, id := range productIds {
+		p, ok := found[id]
+		if !ok {
+			result = append(result, dto.ProductDetailKafka{ProductID: id, Deleted: true})
+			continue
+		}
+		result = append(result, dto.ProductDetailKafka{
+			ProductID: p.ID,
+			StoreID:   p.StoreID,
+			Name:      p.Name,
+			Price:     p.Price,
+			Stock:     p.Stock,
+			Weight:    p.Weight,
+			Category:  p.Category,
+		})
+	}
+
+	return result, nil
+}
+
 func (r *productRepo) ProductValidation(productId uint) (*dto.ValidationProductKafka, error) {
 	var product entity.Product
-	err := r.db.Model(&entity.Product{}).Select("stock").Where("id = ?", productId).First(&product).Error
+	err := r.db.Model(&entity.Product{}).Select("stock", "price").Where("id = ?", productId).First(&product).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return &dto.ValidationProductKafka{
-			Deleted: true,
-			Stock:   0,
+			ProductId: productId,
+			Deleted:   true,
+			Stock:     0,
 		}, nil
 	}
 	if err != nil {
@@ -225,11 +296,494 @@ func (r *productRepo) ProductValidation(productId uint) (*dto.ValidationProductK
 	}
 
 	return &dto.ValidationProductKafka{
-		Deleted: false,
-		Stock:   product.Stock,
+		ProductId: productId,
+		Deleted:   false,
+		Stock:     product.Stock,
+		Price:     product.Price,
+	}, nil
+}
+
+func (r *productRepo) CreateScheduledPrice(req *dto.SchedulePriceReq) (*dto.ScheduledPrice, error) {
+	var count int64
+	if err := r.db.Model(&entity.Product{}).Where("id = ? AND store_id = ?", req.ProductID, req.StoreID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, utils.ErrNoProduct
+	}
+
+	scheduled := entity.ScheduledPrice{
+		ProductID:     req.ProductID,
+		Price:         req.Price,
+		EffectiveFrom: req.EffectiveFrom,
+	}
+	if err := r.db.Create(&scheduled).Error; err != nil {
+		return nil, err
+	}
+
+	return &dto.ScheduledPrice{
+		ID:            scheduled.ID,
+		ProductID:     scheduled.ProductID,
+		Price:         scheduled.Price,
+		EffectiveFrom: scheduled.EffectiveFrom,
+	}, nil
+}
+
+func (r *productRepo) GetProductPrices(productId uint) (*dto.ProductPrices, error) {
+	var product entity.Product
+	if err := r.db.Select("id", "price").First(&product, productId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrNoProduct
+		}
+		return nil, err
+	}
+
+	history := []dto.PriceHistory{}
+	if err := r.db.Model(&entity.PriceHistory{}).Select("old_price", "new_price", "changed_at").Where("product_id = ?", productId).Order("changed_at DESC").Find(&history).Error; err != nil {
+		return nil, err
+	}
+
+	scheduled := []dto.ScheduledPrice{}
+	if err := r.db.Model(&entity.ScheduledPrice{}).Select("id", "product_id", "price", "effective_from").Where("product_id = ? AND applied_at IS NULL", productId).Order("effective_from ASC").Find(&scheduled).Error; err != nil {
+		return nil, err
+	}
+
+	return &dto.ProductPrices{
+		ProductID:    product.ID,
+		CurrentPrice: product.Price,
+		History:      history,
+		Scheduled:    scheduled,
+	}, nil
+}
+
+// ApplyDuePrices menerapkan jadwal yang sudah jatuh tempo. Event tidak dikirim
+// di sini, jadwal ditandai belum dipublish dalam transaksi yang sama lalu
+// dikirim lewat GetUnpublishedPrices.
+func (r *productRepo) ApplyDuePrices(now time.Time) error {
+	tx := r.db.Begin()
+
+	// SKIP LOCKED supaya beberapa instance scheduler tidak mengambil jadwal yang sama
+	var due []entity.ScheduledPrice
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("applied_at IS NULL AND effective_from <= ?", now).
+		Order("effective_from ASC").
+		Find(&due).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var keys []string
+	for _, s := range due {
+		var product entity.Product
+		err := tx.Select("id", "store_id", "price").First(&product, s.ProductID).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			tx.Rollback()
+			return err
+		}
+
+		// product yang sudah dihapus cukup ditandai applied, tidak ada event
+		updates := map[string]interface{}{"applied_at": now}
+		if err == nil {
+			if err := tx.Model(&entity.Product{}).Where("id = ?", product.ID).Updates(map[string]interface{}{
+				"price":   s.Price,
+				"version": gorm.Expr("version + 1"),
+			}).Error; err != nil {
+				tx.Rollback()
+				return err
+			}
+
+			history := entity.PriceHistory{
+				ProductID: product.ID,
+				OldPrice:  product.Price,
+				NewPrice:  s.Price,
+				ChangedAt: now,
+			}
+			if err := tx.Create(&history).Error; err != nil {
+				tx.Rollback()
+				return err
+			}
+
+			updates["store_id"] = product.StoreID
+			updates["old_price"] = product.Price
+			updates["published"] = false
+			keys = append(keys, productKey(product.ID))
+		}
+
+		if err := tx.Model(&entity.ScheduledPrice{}).Where("id = ?", s.ID).Updates(updates).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	if len(keys) > 0 {
+		if err := r.cache.Invalidate(ctx, append(keys, allProductKey)...); err != nil {
+			return fmt.Errorf("redis: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// GetUnpublishedPrices urut waktu diterapkan supaya event satu product tidak tertukar
+func (r *productRepo) GetUnpublishedPrices(limit int) ([]dto.PriceChangedKafka, error) {
+	var schedules []entity.ScheduledPrice
+	if err := r.db.Where("published = ? AND applied_at IS NOT NULL", false).
+		Order("applied_at ASC, id ASC").
+		Limit(limit).
+		Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+
+	changes := make([]dto.PriceChangedKafka, 0, len(schedules))
+	for _, s := range schedules {
+		changes = append(changes, dto.PriceChangedKafka{
+			ScheduleID: s.ID,
+			ProductID:  s.ProductID,
+			StoreID:    s.StoreID,
+			OldPrice:   s.OldPrice,
+			NewPrice:   s.Price,
+			ChangedAt:  *s.AppliedAt,
+		})
+	}
+	return changes, nil
+}
+
+func (r *productRepo) MarkPricePublished(scheduleId uint) error {
+	return r.db.Model(&entity.ScheduledPrice{}).Where("id = ?", scheduleId).Update("published", true).Error
+}
+
+// isDuplicateKey untuk error unique index mysql (1062)
+func isDuplicateKey(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
+
+func (r *productRepo) CreateReview(req *dto.CreateReviewReq) (*dto.Review, error) {
+	var count int64
+	if err := r.db.Model(&entity.VerifiedPurchase{}).Where("user_id = ? AND product_id = ?", req.UserID, req.ProductID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, utils.ErrNotBuyer
+	}
+
+	// product dikunci dulu supaya review product yang sama diproses bergantian,
+	// jadi cek review ganda dan hitung ulang agregat melihat review yang lain
+	tx := r.db.Begin()
+	var product entity.Product
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", req.ProductID).First(&product).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrNoProduct
+		}
+		return nil, err
+	}
+
+	if err := tx.Model(&entity.Review{}).Where("user_id = ? AND product_id = ?", req.UserID, req.ProductID).Count(&count).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if count > 0 {
+		tx.Rollback()
+		return nil, utils.ErrAlreadyReviewed
+	}
+
+	review := entity.Review{
+		ProductID: req.ProductID,
+		UserID:    req.UserID,
+		Rating:    req.Rating,
+		Text:      req.Text,
+	}
+	if err := tx.Create(&review).Error; err != nil {
+		tx.Rollback()
+		if isDuplicateKey(err) {
+			return nil, utils.ErrAlreadyReviewed
+		}
+		return nil, err
+	}
+
+	// hitung ulang dari tabel review supaya agregat selalu konsisten
+	var aggregate struct {
+		Average float64
+		Count   int
+	}
+	if err := tx.Model(&entity.Review{}).Select("COALESCE(AVG(rating), 0) AS average, COUNT(*) AS count").Where("product_id = ?", req.ProductID).Scan(&aggregate).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Model(&entity.Product{}).Where("id = ?", req.ProductID).Updates(map[string]interface{}{
+		"rating_average": aggregate.Average,
+		"rating_count":   aggregate.Count,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	if err := r.cache.Invalidate(ctx, productKey(req.ProductID), allProductKey); err != nil {
+		return nil, fmt.Errorf("redis: %v", err)
+	}
+
+	return &dto.Review{
+		ID:        review.ID,
+		ProductID: review.ProductID,
+		UserID:    review.UserID,
+		Rating:    review.Rating,
+		Text:      review.Text,
+		CreatedAt: review.CreatedAt,
 	}, nil
 }
 
+func (r *productRepo) GetReviews(productId uint) ([]dto.Review, error) {
+	reviews := []dto.Review{}
+	if err := r.db.Model(&entity.Review{}).Where("product_id = ?", productId).Order("created_at DESC").Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+func (r *productRepo) RecordPurchase(userId, productId uint) error {
+	purchase := entity.VerifiedPurchase{
+		UserID:    userId,
+		ProductID: productId,
+	}
+
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&purchase).Error
+}
+
+func (r *productRepo) RecordCoPurchase(paid *dto.CartItemPaidKafka) error {
+	tx := r.db.Begin()
+
+	purchaseLog := entity.PurchaseLog{
+		CartItemID: paid.CartItemID,
+		UserID:     paid.UserID,
+		ProductID:  paid.ProductID,
+		PaidAt:     paid.PaidAt,
+	}
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&purchaseLog)
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+	// event yang sama sudah pernah diproses
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return nil
+	}
+
+	if err := tx.Model(&entity.Product{}).Where("id = ?", paid.ProductID).Update("sold_count", gorm.Expr("sold_count + ?", paid.PurchaseAmount)).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var related []uint
+	if err := tx.Model(&entity.PurchaseLog{}).
+		Where("user_id = ? AND product_id <> ? AND paid_at BETWEEN ? AND ?", paid.UserID, paid.ProductID, paid.PaidAt.Add(-purchaseSessionWindow), paid.PaidAt.Add(purchaseSessionWindow)).
+		Distinct().
+		Pluck("product_id", &related).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, id := range related {
+		pairs := []entity.CoPurchase{
+			{ProductID: paid.ProductID, RelatedProductID: id, Frequency: 1},
+			{ProductID: id, RelatedProductID: paid.ProductID, Frequency: 1},
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]interface{}{"frequency": gorm.Expr("frequency + 1")}),
+		}).Create(&pairs).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *productRepo) GetRelatedProducts(productId uint, limit int) ([]dto.Product, error) {
+	var product entity.Product
+	if err := r.db.Select("id", "store_id").First(&product, productId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrNoProduct
+		}
+		return nil, err
+	}
+
+	var products []entity.Product
+	if err := r.db.Model(&entity.Product{}).
+		Joins("JOIN co_purchases ON co_purchases.related_product_id = products.id").
+		Where("co_purchases.product_id = ?", productId).
+		Order("co_purchases.frequency DESC").
+		Limit(limit).
+		Find(&products).Error; err != nil {
+		return nil, err
+	}
+
+	// cold start: lengkapi dengan best seller dari store yang sama
+	if len(products) < limit {
+		exclude := []uint{productId}
+		for _, p := range products {
+			exclude = append(exclude, p.ID)
+		}
+
+		var bestSellers []entity.Product
+		if err := r.db.Where("store_id = ? AND id NOT IN ?", product.StoreID, exclude).
+			Order("sold_count DESC").
+			Limit(limit - len(products)).
+			Find(&bestSellers).Error; err != nil {
+			return nil, err
+		}
+		products = append(products, bestSellers...)
+	}
+
+	result := make([]dto.Product, 0, len(products))
+	for i := range products {
+		result = append(result, toProductDTO(&products[i]))
+	}
+
+	return result, nil
+}
+
+func (r *productRepo) ReserveStock(sagaId string, items []dto.StockItemKafka) error {
+	tx := r.db.Begin()
+
+	// command yang sama bisa terkirim ulang saat service cart restart
+	var count int64
+	if err := tx.Model(&entity.StockReservation{}).Where("saga_id = ?", sagaId).Count(&count).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if count > 0 {
+		tx.Rollback()
+		return nil
+	}
+
+	keys := []string{allProductKey}
+	for _, item := range items {
+		result := tx.Model(&entity.Product{}).Where("id = ? AND stock >= ?", item.ProductID, item.Quantity).Updates(map[string]interface{}{
+			"stock":   gorm.Expr("stock - ?", item.Quantity),
+			"version": gorm.Expr("version + 1"),
+		})
+		if result.Error != nil {
+			tx.Rollback()
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			tx.Rollback()
+			return utils.ErrStockNotEnough
+		}
+
+		reservation := entity.StockReservation{
+			SagaID:    sagaId,
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		}
+		if err := tx.Create(&reservation).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		keys = append(keys, productKey(item.ProductID))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	if err := r.cache.Invalidate(ctx, keys...); err != nil {
+		return fmt.Errorf("redis: %v", err)
+	}
+	return nil
+}
+
+func (r *productRepo) ReleaseStock(sagaId string) error {
+	tx := r.db.Begin()
+
+	var reservations []entity.StockReservation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("saga_id = ? AND released = ?", sagaId, false).Find(&reservations).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if len(reservations) == 0 {
+		tx.Rollback()
+		return nil
+	}
+
+	keys := []string{allProductKey}
+	for _, reservation := range reservations {
+		if err := tx.Model(&entity.Product{}).Where("id = ?", reservation.ProductID).Updates(map[string]interface{}{
+			"stock":   gorm.Expr("stock + ?", reservation.Quantity),
+			"version": gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		keys = append(keys, productKey(reservation.ProductID))
+	}
+
+	if err := tx.Model(&entity.StockReservation{}).Where("saga_id = ?", sagaId).Update("released", true).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	if err := r.cache.Invalidate(ctx, keys...); err != nil {
+		return fmt.Errorf("redis: %v", err)
+	}
+	return nil
+}
+
+// RestockProduct idempotent per retur, pesan yang terkirim ulang tidak menambah stok dua kali
+func (r *productRepo) RestockProduct(req *dto.StockRestockKafka) error {
+	tx := r.db.Begin()
+
+	restock := entity.StockRestock{
+		ReturnID:  req.ReturnID,
+		OrderID:   req.OrderID,
+		ProductID: req.ProductID,
+		Quantity:  req.Quantity,
+	}
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&restock)
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return nil
+	}
+
+	// product yang sudah dihapus tetap dicatat tapi stoknya tidak diubah
+	if err := tx.Model(&entity.Product{}).Where("id = ?", req.ProductID).Updates(map[string]interface{}{
+		"stock":   gorm.Expr("stock + ?", req.Quantity),
+		"version": gorm.Expr("version + 1"),
+	}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	if err := r.cache.Invalidate(ctx, allProductKey, productKey(req.ProductID)); err != nil {
+		return fmt.Errorf("redis: %v", err)
+	}
+	return nil
+}
+
 func (u *productRepo) WaitForResponse(correlationID string, out interface{}) error {
 	key := fmt.Sprintf("response:%s", correlationID)
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -3,11 +3,14 @@ package usecase
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"service_product/dto"
+	"service_product/helper/cache"
 	"service_product/helper/utils"
 	"service_product/internal/repository"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -23,10 +26,30 @@ type ProductUsecase interface {
 	CreateProduct(req *dto.CreateProductReq) error
 	UpdateProduct(req *dto.UpdateProductReq) error
 	DeleteProduct(userId, storeId, id uint, email string) error
+	CacheStats() cache.Stats
+
+	//price
+	SchedulePriceChange(req *dto.SchedulePriceReq) (*dto.ScheduledPrice, error)
+	GetProductPrices(productId uint) (*dto.ProductPrices, error)
+	ApplyScheduledPrices() error
+
+	//review
+	CreateReview(req *dto.CreateReviewReq) (*dto.Review, error)
+	GetReviews(productId uint) ([]dto.Review, error)
+	RecordPurchase(paid *dto.CartItemPaidKafka) error
+
+	//recommendation
+	GetRelatedProducts(productId uint, limit int) ([]dto.Product, error)
+
+	//saga
+	ReserveStock(req *dto.StockReserveKafka) error
+	ReleaseStock(req *dto.StockReleaseKafka) error
+	RestockProduct(req *dto.StockRestockKafka) error
 
 	//kafka
 	SendProductsResponse(storeId uint, correlation_id string) error
 	SendValidationCartResponse(productId uint, correlation_id string) error
+	SendProductDetailsResponse(productIds []uint, correlation_id string) error
 	WriteKafkaMessage(topic string, key string, payload interface{}) error
 }
 
@@ -148,8 +171,9 @@ func (u *productUsecase) UpdateProduct(req *dto.UpdateProductReq) error {
 		"action":         "update",
 		"message":        message,
 	}
+	// update sudah tersimpan, notifikasi yang gagal cukup dicatat supaya ETag baru tetap sampai ke client
 	if err := u.WriteKafkaMessage("notification-request", corrID, payloadtwo); err != nil {
-		return err
+		log.Printf("notifikasi update product %d gagal dikirim: %v", req.ID, err)
 	}
 	return nil
 }
@@ -174,13 +198,19 @@ func (u *productUsecase) DeleteProduct(userId, storeId, id uint, email string) e
 		return utils.ErrNotAdmin
 	}
 
+	// event deleted baru dikirim setelah baris terhapus, kalau tidak cart dan wishlist
+	// sudah menandai product terhapus padahal productnya masih ada
+	if err := u.productRepo.DeleteProduct(id); err != nil {
+		return err
+	}
+
 	payloadtwo := map[string]interface{}{
 		"correlation_id": corrID,
 		"deleted":        true,
 		"stock":          0,
 		"product_id":     id,
 	}
-	if err := u.WriteKafkaMessage("validation-product-response", corrID, payloadtwo); err != nil {
+	if err := u.WriteKafkaMessage("product-validation-response", corrID, payloadtwo); err != nil {
 		return err
 	}
 
@@ -188,13 +218,13 @@ func (u *productUsecase) DeleteProduct(userId, storeId, id uint, email string) e
 		"correlation_id": corrID,
 		"email":          email,
 		"service":        "product",
-		"action":         "update",
+		"action":         "delete",
 		"message":        id,
 	}
 	if err := u.WriteKafkaMessage("notification-request", corrID, payloadthree); err != nil {
-		return err
+		log.Printf("notifikasi hapus product %d gagal dikirim: %v", id, err)
 	}
-	return u.productRepo.DeleteProduct(id)
+	return nil
 }
 
 func (u *productUsecase) GetAllProduct() ([]dto.Product, error) {
@@ -205,6 +235,109 @@ func (u *productUsecase) GetProduct(id uint) (*dto.Product, error) {
 	return u.productRepo.GetProduct(id)
 }
 
+func (u *productUsecase) CacheStats() cache.Stats {
+	return u.productRepo.CacheStats()
+}
+
+func (u *productUsecase) SchedulePriceChange(req *dto.SchedulePriceReq) (*dto.ScheduledPrice, error) {
+	corrID := uuid.NewString()
+
+	payload := map[string]interface{}{
+		"store_id":       req.StoreID,
+		"user_id":        req.UserID,
+		"correlation_id": corrID,
+	}
+	if err := u.WriteKafkaMessage("store-validation-request", corrID, payload); err != nil {
+		return nil, err
+	}
+
+	var isValid bool
+	if err := u.productRepo.WaitForResponse(corrID, &isValid); err != nil {
+		return nil, err
+	}
+	if !isValid {
+		return nil, utils.ErrNotAdmin
+	}
+
+	return u.productRepo.CreateScheduledPrice(req)
+}
+
+func (u *productUsecase) GetProductPrices(productId uint) (*dto.ProductPrices, error) {
+	return u.productRepo.GetProductPrices(productId)
+}
+
+// ApplyScheduledPrices menerapkan jadwal lalu mengirim semua perubahan harga
+// yang belum terkirim, termasuk sisa tick sebelumnya. Pengiriman berhenti di
+// kegagalan pertama supaya urutan event tetap, sisanya dicoba lagi tick berikutnya.
+func (u *productUsecase) ApplyScheduledPrices() error {
+	errApply := u.productRepo.ApplyDuePrices(time.Now())
+
+	changes, err := u.productRepo.GetUnpublishedPrices(100)
+	if err != nil {
+		return errors.Join(errApply, err)
+	}
+
+	for _, change := range changes {
+		key := strconv.FormatUint(uint64(change.ProductID), 10)
+		if err := u.WriteKafkaMessage("product-price-changed", key, change); err != nil {
+			return errors.Join(errApply, err)
+		}
+		if err := u.productRepo.MarkPricePublished(change.ScheduleID); err != nil {
+			return errors.Join(errApply, err)
+		}
+	}
+
+	return errApply
+}
+
+func (u *productUsecase) CreateReview(req *dto.CreateReviewReq) (*dto.Review, error) {
+	return u.productRepo.CreateReview(req)
+}
+
+func (u *productUsecase) GetReviews(productId uint) ([]dto.Review, error) {
+	return u.productRepo.GetReviews(productId)
+}
+
+func (u *productUsecase) RecordPurchase(paid *dto.CartItemPaidKafka) error {
+	if err := u.productRepo.RecordPurchase(paid.UserID, paid.ProductID); err != nil {
+		return err
+	}
+
+	return u.productRepo.RecordCoPurchase(paid)
+}
+
+func (u *productUsecase) GetRelatedProducts(productId uint, limit int) ([]dto.Product, error) {
+	return u.productRepo.GetRelatedProducts(productId, limit)
+}
+
+func (u *productUsecase) ReserveStock(req *dto.StockReserveKafka) error {
+	reply := dto.SagaReplyKafka{
+		CorrelationID: req.CorrelationID,
+		Success:       true,
+	}
+
+	if err := u.productRepo.ReserveStock(req.CorrelationID, req.Items); err != nil {
+		if err != utils.ErrStockNotEnough {
+			return err
+		}
+		reply.Success = false
+		reply.Reason = err.Error()
+	}
+
+	return u.WriteKafkaMessage("stock-reserve-response", req.CorrelationID, reply)
+}
+
+func (u *productUsecase) ReleaseStock(req *dto.StockReleaseKafka) error {
+	return u.productRepo.ReleaseStock(req.CorrelationID)
+}
+
+func (u *productUsecase) RestockProduct(req *dto.StockRestockKafka) error {
+	if req.ReturnID == 0 || req.Quantity <= 0 {
+		return nil
+	}
+	return u.productRepo.RestockProduct(req)
+}
+
 func (u *productUsecase) SendProductsResponse(storeId uint, correlation_id string) error {
 	products, err := u.productRepo.GetProductByStoreId(storeId)
 	if err != nil {
@@ -223,6 +356,24 @@ func (u *productUsecase) SendProductsResponse(storeId uint, correlation_id strin
 	return nil
 }
 
+func (u *productUsecase) SendProductDetailsResponse(productIds []uint, correlation_id string) error {
+	products, err := u.productRepo.GetProductDetails(productIds)
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"correlation_id": correlation_id,
+		"data":           products,
+	}
+
+	if err := u.WriteKafkaMessage("products-detail-response", correlation_id, payload); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (u *productUsecase) SendValidationCartResponse(productId uint, correlation_id string) error {
 	result, err := u.productRepo.ProductValidation(productId)
 	if err != nil {
@@ -233,10 +384,11 @@ func (u *productUsecase) SendValidationCartResponse(productId uint, correlation_
 		"correlation_id": correlation_id,
 		"deleted":        result.Deleted,
 		"stock":          result.Stock,
+		"price":          result.Price,
 		"product_id":     result.ProductId,
 	}
 
-	if err := u.WriteKafkaMessage("validation-product-response", correlation_id, payload); err != nil {
+	if err := u.WriteKafkaMessage("product-validation-response", correlation_id, payload); err != nil {
 		return err
 	}
 
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"service_store/dto"
 	"service_store/internal/usecase"
 	"time"
 
@@ -73,8 +74,10 @@ func ValidationRequestConsumer(usecase usecase.StoreUsecase, breaker *gobreaker.
 			}
 
 			corrID := payload["correlation_id"].(string)
-			userId, _ := payload["user_id"].(uint)
-			storeId, _ := payload["store_id"].(uint)
+			// angka dari json selalu float64
+			userIdRaw, _ := payload["user_id"].(float64)
+			storeIdRaw, _ := payload["store_id"].(float64)
+			userId, storeId := uint(userIdRaw), uint(storeIdRaw)
 
 			_, errBreaker := breaker.Execute(func() (interface{}, error) {
 				if err := usecase.SendValidationResponse(userId, storeId, corrID); err != nil {
@@ -90,3 +93,109 @@ func ValidationRequestConsumer(usecase usecase.StoreUsecase, breaker *gobreaker.
 		}
 	}()
 }
+
+// consumer send response
+func StoresDetailRequestConsumer(usecase usecase.StoreUsecase, breaker *gobreaker.CircuitBreaker) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   "stores-detail-request",
+		GroupID: "store-service",
+	})
+
+	go func() {
+		for {
+			msg, err := r.ReadMessage(context.Background())
+			if err != nil {
+				fmt.Println("error reading message:", err)
+				continue
+			}
+
+			var payload struct {
+				CorrelationID string `json:"correlation_id"`
+				StoreIDs      []uint `json:"store_ids"`
+			}
+			if err := json.Unmarshal(msg.Value, &payload); err != nil {
+				fmt.Println("error unmarshaling:", err)
+				continue
+			}
+
+			_, errBreaker := breaker.Execute(func() (interface{}, error) {
+				return nil, usecase.SendStoresDetail(payload.StoreIDs, payload.CorrelationID)
+			})
+			if errBreaker != nil {
+				fmt.Println("send stores detail failed or breaker open:", errBreaker)
+				continue
+			}
+		}
+	}()
+}
+
+// consumer read model order toko
+func StoreOrderEventConsumer(usecase usecase.OrderUsecase, breaker *gobreaker.CircuitBreaker) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   "store-order-event",
+		GroupID: "store-service",
+	})
+
+	go func() {
+		for {
+			msg, err := r.ReadMessage(context.Background())
+			if err != nil {
+				fmt.Println("error reading message:", err)
+				continue
+			}
+
+			var payload dto.StoreOrderEventKafka
+			if err := json.Unmarshal(msg.Value, &payload); err != nil {
+				fmt.Println("error unmarshaling:", err)
+				continue
+			}
+
+			_, errBreaker := breaker.Execute(func() (interface{}, error) {
+				return nil, usecase.SaveStoreOrder(&payload)
+			})
+			if errBreaker != nil {
+				fmt.Println("save store order failed or breaker open:", errBreaker)
+				continue
+			}
+		}
+	}()
+}
+
+// consumer get response
+func StoreShipmentResponseConsumer(redisClient *redis.Client, breaker *gobreaker.CircuitBreaker) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   "store-shipment-response",
+		GroupID: "store-service",
+	})
+
+	go func() {
+		for {
+			msg, err := r.ReadMessage(context.Background())
+			if err != nil {
+				fmt.Println("error reading message:", err)
+				continue
+			}
+
+			var payload struct {
+				CorrelationID string          `json:"correlation_id"`
+				Data          json.RawMessage `json:"data"`
+			}
+			if err := json.Unmarshal(msg.Value, &payload); err != nil {
+				fmt.Println("error unmarshaling:", err)
+				continue
+			}
+
+			key := fmt.Sprintf("response:%s", payload.CorrelationID)
+			_, errBreaker := breaker.Execute(func() (interface{}, error) {
+				return redisClient.Set(context.Background(), key, []byte(payload.Data), 10*time.Second).Result()
+			})
+			if errBreaker != nil {
+				fmt.Println("Redis SET failed or breaker open:", errBreaker)
+				continue
+			}
+		}
+	}()
+}
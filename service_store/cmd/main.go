@@ -35,12 +35,31 @@ func main() {
 			Topic:    "store-validation-response",
 			Balancer: &kafka.LeastBytes{},
 		}),
+		"stores-detail-response": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "stores-detail-response",
+			Balancer: &kafka.LeastBytes{},
+		}),
+		"store-shipment-request": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "store-shipment-request",
+			Balancer: &kafka.LeastBytes{},
+		}),
+		"notification-request": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "notification-request",
+			Balancer: &kafka.LeastBytes{},
+		}),
 	}
 
 	storeRepo := repository.NewStoreRepo(db, rdb)
 	storeUC := usecase.NewStoreUsecase(storeRepo, kafkaWriter)
 	storeHandler := handler.NewStoreHandler(storeUC)
 
+	orderRepo := repository.NewOrderRepo(db)
+	orderUC := usecase.NewOrderUsecase(orderRepo, storeRepo, kafkaWriter)
+	orderHandler := handler.NewOrderHandler(orderUC)
+
 	breaker := gobreaker.NewCircuitBreaker(gobreaker.Settings{
 		Name:        "ConsumerBreaker",
 		MaxRequests: 3,
@@ -52,8 +71,11 @@ func main() {
 	})
 	go kafkaconsumer.ProductResponseConsumer(rdb, breaker)
 	go kafkaconsumer.ValidationRequestConsumer(storeUC, breaker)
+	go kafkaconsumer.StoresDetailRequestConsumer(storeUC, breaker)
+	go kafkaconsumer.StoreOrderEventConsumer(orderUC, breaker)
+	go kafkaconsumer.StoreShipmentResponseConsumer(rdb, breaker)
 
-	r := route.SetupRoute(storeHandler)
+	r := route.SetupRoute(storeHandler, orderHandler)
 
 	port := os.Getenv("PORT")
 	if port == "" {
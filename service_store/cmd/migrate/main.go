@@ -13,7 +13,7 @@ func main() {
 		log.Fatal(err)
 	}
 
-	if err := db.AutoMigrate(&entity.Store{}); err != nil {
+	if err := db.AutoMigrate(&entity.Store{}, &entity.StoreOrder{}, &entity.StoreOrderItem{}); err != nil {
 		log.Fatal(err)
 	}
 
@@ -8,7 +8,7 @@ import (
 	"github.com/gorilla/mux"
 )
 
-func SetupRoute(store *handler.StoreHandler) *mux.Router {
+func SetupRoute(store *handler.StoreHandler, order *handler.OrderHandler) *mux.Router {
 	r := mux.NewRouter()
 
 	useM := r.PathPrefix("/store").Subrouter()
@@ -19,5 +19,10 @@ func SetupRoute(store *handler.StoreHandler) *mux.Router {
 	useM.HandleFunc("/delete/{storeId}", store.DeleteStore).Methods(http.MethodDelete)
 	useM.HandleFunc("/get/{storeId}", store.GetMyStore).Methods(http.MethodGet)
 	useM.HandleFunc("/get", store.GetAllStore).Methods(http.MethodGet)
+
+	//order toko
+	useM.HandleFunc("/{storeId}/orders", order.GetStoreOrders).Methods(http.MethodGet)
+	useM.HandleFunc("/{storeId}/orders/{orderId}", order.GetStoreOrder).Methods(http.MethodGet)
+	useM.HandleFunc("/{storeId}/orders/{orderId}/fulfilment", order.UpdateFulfilment).Methods(http.MethodPut)
 	return r
 }
@@ -0,0 +1,93 @@
+package dto
+
+import "time"
+
+// order toko
+type StoreOrderItem struct {
+	OrderItemID    uint   `json:"order_item_id"`
+	ProductID      uint   `json:"product_id"`
+	ProductName    string `json:"product_name"`
+	UnitPrice      int64  `json:"unit_price"`
+	Quantity       int    `json:"quantity"`
+	Subtotal       int64  `json:"subtotal"`
+	DiscountAmount int64  `json:"discount_amount"`
+	TaxAmount      int64  `json:"tax_amount"`
+}
+
+type StoreOrder struct {
+	OrderID          uint             `json:"order_id"`
+	StoreID          uint             `json:"store_id"`
+	BuyerID          uint             `json:"buyer_id"`
+	Status           string           `json:"status"`
+	Subtotal         int64            `json:"subtotal"`
+	DiscountAmount   int64            `json:"discount_amount"`
+	TaxAmount        int64            `json:"tax_amount"`
+	ShipmentID       uint             `json:"shipment_id"`
+	FulfilmentStatus string           `json:"fulfilment_status"`
+	ShippingMethod   string           `json:"shipping_method"`
+	ShippingCost     int64            `json:"shipping_cost"`
+	TrackingNumber   string           `json:"tracking_number"`
+	Recipient        string           `json:"recipient"`
+	City             string           `json:"city"`
+	Province         string           `json:"province"`
+	PackedAt         *time.Time       `json:"packed_at"`
+	ShippedAt        *time.Time       `json:"shipped_at"`
+	DeliveredAt      *time.Time       `json:"delivered_at"`
+	Items            []StoreOrderItem `json:"items"`
+	OrderedAt        time.Time        `json:"ordered_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+}
+
+type StoreOrderFilter struct {
+	UserID           uint
+	StoreID          uint
+	Status           string
+	FulfilmentStatus string
+	//from inklusif, to eksklusif
+	From *time.Time
+	To   *time.Time
+}
+
+type FulfilmentReq struct {
+	UserID         uint   `json:"-"`
+	StoreID        uint   `json:"-"`
+	OrderID        uint   `json:"-"`
+	Status         string `json:"status"`
+	TrackingNumber string `json:"tracking_number"`
+}
+
+// kafka
+type ShipmentKafka struct {
+	ID             uint       `json:"id"`
+	Method         string     `json:"method"`
+	Cost           int64      `json:"cost"`
+	Status         string     `json:"status"`
+	TrackingNumber string     `json:"tracking_number"`
+	Recipient      string     `json:"recipient"`
+	City           string     `json:"city"`
+	Province       string     `json:"province"`
+	PackedAt       *time.Time `json:"packed_at"`
+	ShippedAt      *time.Time `json:"shipped_at"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+}
+
+type StoreOrderEventKafka struct {
+	Event          string           `json:"event"`
+	OrderID        uint             `json:"order_id"`
+	StoreID        uint             `json:"store_id"`
+	BuyerID        uint             `json:"buyer_id"`
+	Status         string           `json:"status"`
+	Items          []StoreOrderItem `json:"items"`
+	Subtotal       int64            `json:"subtotal"`
+	DiscountAmount int64            `json:"discount_amount"`
+	TaxAmount      int64            `json:"tax_amount"`
+	Shipment       *ShipmentKafka   `json:"shipment"`
+	OrderedAt      time.Time        `json:"ordered_at"`
+	OccurredAt     time.Time        `json:"occurred_at"`
+}
+
+type StoreShipmentReplyKafka struct {
+	Success  bool           `json:"success"`
+	Reason   string         `json:"reason"`
+	Shipment *ShipmentKafka `json:"shipment"`
+}
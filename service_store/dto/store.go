@@ -7,19 +7,24 @@ type CreateStoreReq struct {
 	Email   string `json:"-"`
 	AdminID uint   `json:"-"`
 	Name    string `json:"name"`
+	Region  string `json:"region"`
 }
 
 type UpdateStoreReq struct {
-	Email  string `json:"-"`
-	UserID uint   `json:"-"`
-	ID     uint   `json:"-"`
-	Name   string `json:"name"`
+	Email   string `json:"-"`
+	UserID  uint   `json:"-"`
+	ID      uint   `json:"-"`
+	Version uint   `json:"-"`
+	Name    string `json:"name"`
+	Region  string `json:"region"`
 }
 
 type Store struct {
 	ID        uint      `json:"id"`
 	Name      string    `json:"name"`
 	AdminID   uint      `json:"admin_id"`
+	Region    string    `json:"region"`
+	Version   uint      `json:"version"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -28,6 +33,7 @@ type Product struct {
 	ID        uint      `json:"id"`
 	Name      string    `json:"name"`
 	Stock     int       `json:"stock"`
+	Price     int64     `json:"price"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -9,6 +9,67 @@ type Store struct {
 	//admin
 	AdminID uint `gorm:"index;unique"`
 
+	//wilayah pajak, contoh "id" atau "id-jk"
+	Region string `gorm:"type:varchar(20);not null;default:'id'"`
+
+	//optimistic lock
+	Version uint `gorm:"not null;default:1"`
+
 	//product
 	CreatedAt time.Time
 }
+
+// status pengiriman yang dikirim service cart
+const (
+	FulfilmentPending   = "pending"
+	FulfilmentPacked    = "packed"
+	FulfilmentShipped   = "shipped"
+	FulfilmentDelivered = "delivered"
+)
+
+// StoreOrder read model order per toko, diisi dari event service cart dan
+// hanya dipakai untuk halaman order seller
+type StoreOrder struct {
+	ID      uint   `gorm:"primaryKey"`
+	OrderID uint   `gorm:"not null;uniqueIndex:idx_store_order"`
+	StoreID uint   `gorm:"not null;uniqueIndex:idx_store_order;index:idx_store_order_date"`
+	BuyerID uint   `gorm:"not null"`
+	Status  string `gorm:"type:varchar(20);not null;index"`
+
+	Subtotal       int64 `gorm:"not null;default:0"`
+	DiscountAmount int64 `gorm:"not null;default:0"`
+	TaxAmount      int64 `gorm:"not null;default:0"`
+
+	//pengiriman toko ini
+	ShipmentID       uint
+	FulfilmentStatus string `gorm:"type:varchar(20);index"`
+	ShippingMethod   string `gorm:"type:varchar(50)"`
+	ShippingCost     int64  `gorm:"not null;default:0"`
+	TrackingNumber   string `gorm:"type:varchar(64)"`
+	Recipient        string `gorm:"type:varchar(100)"`
+	City             string `gorm:"type:varchar(100)"`
+	Province         string `gorm:"type:varchar(100)"`
+	PackedAt         *time.Time
+	ShippedAt        *time.Time
+	DeliveredAt      *time.Time
+
+	Items []StoreOrderItem `gorm:"constraint:OnDelete:CASCADE"`
+
+	OrderedAt time.Time `gorm:"not null;index:idx_store_order_date"`
+	//waktu event terakhir yang diterapkan, event yang lebih lama diabaikan
+	EventAt   time.Time `gorm:"not null"`
+	UpdatedAt time.Time
+}
+
+type StoreOrderItem struct {
+	ID             uint   `gorm:"primaryKey"`
+	StoreOrderID   uint   `gorm:"not null;index"`
+	OrderItemID    uint   `gorm:"not null"`
+	ProductID      uint   `gorm:"not null"`
+	ProductName    string `gorm:"type:varchar(255)"`
+	UnitPrice      int64  `gorm:"not null"`
+	Quantity       int    `gorm:"not null"`
+	Subtotal       int64  `gorm:"not null"`
+	DiscountAmount int64  `gorm:"not null;default:0"`
+	TaxAmount      int64  `gorm:"not null;default:0"`
+}
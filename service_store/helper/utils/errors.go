@@ -9,4 +9,8 @@ var (
 	ErrNoStore          = errors.New("tidak ada store")
 	ErrNoTopic          = errors.New("bukan ada topic ini")
 	ErrFailedKafkaWrite = errors.New("gagal  mengirim message ")
+	ErrInvalidETag      = errors.New("etag tidak valid")
+	ErrVersionConflict  = errors.New("data sudah diubah, version tidak cocok")
+	ErrOrderNotFound    = errors.New("order tidak ditemukan")
+	ErrFulfilment       = errors.New("status pengiriman tidak bisa diubah")
 )
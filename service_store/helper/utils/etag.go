@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func FormatETag(version uint) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+func ParseETag(etag string) (uint, error) {
+	etag = strings.TrimSpace(etag)
+	etag = strings.TrimPrefix(etag, "W/")
+	etag = strings.Trim(etag, `"`)
+
+	version, err := strconv.ParseUint(etag, 10, 64)
+	if err != nil || version == 0 {
+		return 0, ErrInvalidETag
+	}
+
+	return uint(version), nil
+}
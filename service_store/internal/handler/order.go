@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"service_store/dto"
+	"service_store/entity"
+	"service_store/helper/middleware"
+	"service_store/helper/utils"
+	"service_store/internal/usecase"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const dateLayout = "2006-01-02"
+
+// status order dari service cart
+var orderStatuses = map[string]bool{
+	"pending":   true,
+	"paid":      true,
+	"cancelled": true,
+	"fulfilled": true,
+}
+
+var fulfilmentStatuses = map[string]bool{
+	entity.FulfilmentPending:   true,
+	entity.FulfilmentPacked:    true,
+	entity.FulfilmentShipped:   true,
+	entity.FulfilmentDelivered: true,
+}
+
+type OrderHandler struct {
+	orderUsecase usecase.OrderUsecase
+}
+
+func NewOrderHandler(orderUsecase usecase.OrderUsecase) *OrderHandler {
+	return &OrderHandler{orderUsecase}
+}
+
+// parseOrderFilter membaca status, fulfilment_status, from dan to (YYYY-MM-DD).
+// Tanggal to ikut dihitung sampai akhir hari.
+func parseOrderFilter(r *http.Request, filter *dto.StoreOrderFilter) string {
+	query := r.URL.Query()
+
+	filter.Status = strings.ToLower(strings.TrimSpace(query.Get("status")))
+	if filter.Status != "" && !orderStatuses[filter.Status] {
+		return "status tidak valid"
+	}
+	filter.FulfilmentStatus = strings.ToLower(strings.TrimSpace(query.Get("fulfilment_status")))
+	if filter.FulfilmentStatus != "" && !fulfilmentStatuses[filter.FulfilmentStatus] {
+		return "fulfilment_status tidak valid"
+	}
+
+	if raw := query.Get("from"); raw != "" {
+		from, err := time.Parse(dateLayout, raw)
+		if err != nil {
+			return "from harus berformat YYYY-MM-DD"
+		}
+		filter.From = &from
+	}
+	if raw := query.Get("to"); raw != "" {
+		to, err := time.Parse(dateLayout, raw)
+		if err != nil {
+			return "to harus berformat YYYY-MM-DD"
+		}
+		to = to.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return "from tidak boleh setelah to"
+	}
+
+	return ""
+}
+
+func (h *OrderHandler) GetStoreOrders(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsStoreId, err := strconv.Atoi(params["storeId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	filter := dto.StoreOrderFilter{UserID: claims.UserID, StoreID: uint(paramsStoreId)}
+	if msg := parseOrderFilter(r, &filter); msg != "" {
+		utils.WriteError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	response, err := h.orderUsecase.GetStoreOrders(&filter)
+	if err != nil {
+		switch err {
+		case utils.ErrNotAdmin:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *OrderHandler) GetStoreOrder(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsStoreId, err := strconv.Atoi(params["storeId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+	paramsOrderId, err := strconv.Atoi(params["orderId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	response, err := h.orderUsecase.GetStoreOrder(claims.UserID, uint(paramsStoreId), uint(paramsOrderId))
+	if err != nil {
+		switch err {
+		case utils.ErrNotAdmin:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		case utils.ErrOrderNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *OrderHandler) UpdateFulfilment(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsStoreId, err := strconv.Atoi(params["storeId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+	paramsOrderId, err := strconv.Atoi(params["orderId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	var req dto.FulfilmentReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	req.Status = strings.ToLower(strings.TrimSpace(req.Status))
+	if req.Status == entity.FulfilmentPending || !fulfilmentStatuses[req.Status] {
+		utils.WriteError(w, http.StatusBadRequest, "status harus packed, shipped atau delivered")
+		return
+	}
+	req.TrackingNumber = strings.TrimSpace(req.TrackingNumber)
+	if len(req.TrackingNumber) > 64 {
+		utils.WriteError(w, http.StatusBadRequest, "tracking_number maksimal 64 karakter")
+		return
+	}
+
+	req.UserID = claims.UserID
+	req.StoreID = uint(paramsStoreId)
+	req.OrderID = uint(paramsOrderId)
+	response, err := h.orderUsecase.UpdateFulfilment(&req)
+	if err != nil {
+		switch err {
+		case utils.ErrNotAdmin:
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		case utils.ErrOrderNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		case utils.ErrFulfilment:
+			utils.WriteError(w, http.StatusConflict, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
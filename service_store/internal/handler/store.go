@@ -8,6 +8,7 @@ import (
 	"service_store/helper/utils"
 	"service_store/internal/usecase"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 )
@@ -21,6 +22,20 @@ func NewStoreHandler(storeUsecase usecase.StoreUsecase) *StoreHandler {
 }
 
 // store
+// normalizeRegion menerima kode wilayah huruf kecil, angka dan strip, maksimal 20 karakter
+func normalizeRegion(region string) (string, bool) {
+	region = strings.ToLower(strings.TrimSpace(region))
+	if len(region) > 20 {
+		return "", false
+	}
+	for _, c := range region {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+			return "", false
+		}
+	}
+	return region, true
+}
+
 func (h *StoreHandler) GetMyStore(w http.ResponseWriter, r *http.Request) {
 	claimsRaw := r.Context().Value(middleware.UserContextKey)
 	_, ok := claimsRaw.(*utils.JWTCLAIMS)
@@ -48,6 +63,7 @@ func (h *StoreHandler) GetMyStore(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	w.Header().Set("ETag", utils.FormatETag(response.Store.Version))
 	utils.WriteJSON(w, http.StatusOK, response)
 }
 
@@ -81,6 +97,15 @@ func (h *StoreHandler) CreateStore(w http.ResponseWriter, r *http.Request) {
 		utils.WriteError(w, http.StatusBadRequest, "invalid body")
 		return
 	}
+	region, ok := normalizeRegion(req.Region)
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, "region tidak valid")
+		return
+	}
+	if region == "" {
+		region = "id"
+	}
+	req.Region = region
 
 	req.Email = claims.Email
 	req.AdminID = claims.UserID
@@ -100,11 +125,29 @@ func (h *StoreHandler) UpdateStore(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		utils.WriteError(w, http.StatusPreconditionRequired, "header If-Match wajib diisi")
+		return
+	}
+	version, err := utils.ParseETag(ifMatch)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	var req dto.UpdateStoreReq
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utils.WriteError(w, http.StatusBadRequest, "invalid body")
 		return
 	}
+	// region kosong berarti tidak diubah
+	region, ok := normalizeRegion(req.Region)
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, "region tidak valid")
+		return
+	}
+	req.Region = region
 
 	params := mux.Vars(r)
 	paramsStoreId, err := strconv.Atoi(params["storeId"])
@@ -116,17 +159,25 @@ func (h *StoreHandler) UpdateStore(w http.ResponseWriter, r *http.Request) {
 	req.Email = claims.Email
 	req.ID = uint(paramsStoreId)
 	req.UserID = claims.UserID
+	req.Version = version
 	if err := h.storeUscase.UpdateStore(&req); err != nil {
 		switch err {
 		case utils.ErrNotAdmin:
 			utils.WriteError(w, http.StatusForbidden, "bukan admin")
 			return
+		case utils.ErrVersionConflict:
+			utils.WriteError(w, http.StatusPreconditionFailed, "store sudah diubah, ambil ulang data terbaru")
+			return
+		case utils.ErrNoStore:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
 		default:
 			utils.WriteError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 	}
 
+	w.Header().Set("ETag", utils.FormatETag(req.Version+1))
 	utils.WriteJSON(w, http.StatusOK, nil)
 }
 
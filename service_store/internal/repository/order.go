@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"errors"
+	"service_store/dto"
+	"service_store/entity"
+	"service_store/helper/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OrderRepo interface {
+	SaveStoreOrder(event *dto.StoreOrderEventKafka) error
+	GetStoreOrders(filter *dto.StoreOrderFilter) ([]dto.StoreOrder, error)
+	GetStoreOrder(storeId, orderId uint) (*dto.StoreOrder, error)
+}
+
+type orderRepo struct {
+	db *gorm.DB
+}
+
+func NewOrderRepo(db *gorm.DB) OrderRepo {
+	return &orderRepo{db}
+}
+
+func toStoreOrderDTO(o *entity.StoreOrder) dto.StoreOrder {
+	items := make([]dto.StoreOrderItem, 0, len(o.Items))
+	for _, item := range o.Items {
+		items = append(items, dto.StoreOrderItem{
+			OrderItemID:    item.OrderItemID,
+			ProductID:      item.ProductID,
+			ProductName:    item.ProductName,
+			UnitPrice:      item.UnitPrice,
+			Quantity:       item.Quantity,
+			Subtotal:       item.Subtotal,
+			DiscountAmount: item.DiscountAmount,
+			TaxAmount:      item.TaxAmount,
+		})
+	}
+
+	return dto.StoreOrder{
+		OrderID:          o.OrderID,
+		StoreID:          o.StoreID,
+		BuyerID:          o.BuyerID,
+		Status:           o.Status,
+		Subtotal:         o.Subtotal,
+		DiscountAmount:   o.DiscountAmount,
+		TaxAmount:        o.TaxAmount,
+		ShipmentID:       o.ShipmentID,
+		FulfilmentStatus: o.FulfilmentStatus,
+		ShippingMethod:   o.ShippingMethod,
+		ShippingCost:     o.ShippingCost,
+		TrackingNumber:   o.TrackingNumber,
+		Recipient:        o.Recipient,
+		City:             o.City,
+		Province:         o.Province,
+		PackedAt:         o.PackedAt,
+		ShippedAt:        o.ShippedAt,
+		DeliveredAt:      o.DeliveredAt,
+		Items:            items,
+		OrderedAt:        o.OrderedAt,
+		UpdatedAt:        o.UpdatedAt,
+	}
+}
+
+// SaveStoreOrder menimpa read model dengan snapshot dari event. Event boleh datang
+// berulang atau terlambat, snapshot yang lebih lama dari yang tersimpan diabaikan.
+func (r *orderRepo) SaveStoreOrder(event *dto.StoreOrderEventKafka) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var order entity.StoreOrder
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("order_id = ? AND store_id = ?", event.OrderID, event.StoreID).First(&order).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if order.ID != 0 && order.EventAt.After(event.OccurredAt) {
+			return nil
+		}
+
+		order.OrderID = event.OrderID
+		order.StoreID = event.StoreID
+		order.BuyerID = event.BuyerID
+		order.Status = event.Status
+		order.Subtotal = event.Subtotal
+		order.DiscountAmount = event.DiscountAmount
+		order.TaxAmount = event.TaxAmount
+		order.OrderedAt = event.OrderedAt
+		order.EventAt = event.OccurredAt
+		if shipment := event.Shipment; shipment != nil {
+			order.ShipmentID = shipment.ID
+			order.FulfilmentStatus = shipment.Status
+			order.ShippingMethod = shipment.Method
+			order.ShippingCost = shipment.Cost
+			order.TrackingNumber = shipment.TrackingNumber
+			order.Recipient = shipment.Recipient
+			order.City = shipment.City
+			order.Province = shipment.Province
+			order.PackedAt = shipment.PackedAt
+			order.ShippedAt = shipment.ShippedAt
+			order.DeliveredAt = shipment.DeliveredAt
+		}
+
+		order.Items = nil
+		if err := tx.Save(&order).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("store_order_id = ?", order.ID).Delete(&entity.StoreOrderItem{}).Error; err != nil {
+			return err
+		}
+		if len(event.Items) == 0 {
+			return nil
+		}
+
+		items := make([]entity.StoreOrderItem, 0, len(event.Items))
+		for _, item := range event.Items {
+			items = append(items, entity.StoreOrderItem{
+				StoreOrderID:   order.ID,
+				OrderItemID:    item.OrderItemID,
+				ProductID:      item.ProductID,
+				ProductName:    item.ProductName,
+				UnitPrice:      item.UnitPrice,
+				Quantity:       item.Quantity,
+				Subtotal:       item.Subtotal,
+				DiscountAmount: item.DiscountAmount,
+				TaxAmount:      item.TaxAmount,
+			})
+		}
+		return tx.Create(&items).Error
+	})
+}
+
+func (r *orderRepo) GetStoreOrders(filter *dto.StoreOrderFilter) ([]dto.StoreOrder, error) {
+	query := r.db.Preload("Items").Where("store_id = ?", filter.StoreID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.FulfilmentStatus != "" {
+		query = query.Where("fulfilment_status = ?", filter.FulfilmentStatus)
+	}
+	if filter.From != nil {
+		query = query.Where("ordered_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("ordered_at < ?", *filter.To)
+	}
+
+	var orders []entity.StoreOrder
+	if err := query.Order("ordered_at DESC").Find(&orders).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.StoreOrder, 0, len(orders))
+	for i := range orders {
+		result = append(result, toStoreOrderDTO(&orders[i]))
+	}
+
+	return result, nil
+}
+
+func (r *orderRepo) GetStoreOrder(storeId, orderId uint) (*dto.StoreOrder, error) {
+	var order entity.StoreOrder
+	if err := r.db.Preload("Items").Where("store_id = ? AND order_id = ?", storeId, orderId).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrOrderNotFound
+		}
+		return nil, err
+	}
+
+	response := toStoreOrderDTO(&order)
+	return &response, nil
+}
@@ -18,6 +18,7 @@ import (
 
 type StoreRepo interface {
 	IsUserAdminStore(userId, storeId uint) (bool, error)
+	GetStoresByIds(ids []uint) ([]dto.Store, error)
 	GetMyStore(id uint) (*dto.Store, error)
 	GetAllStore() ([]dto.Store, error)
 	CreateStore(req *dto.CreateStoreReq) (*dto.Store, error)
@@ -48,6 +49,15 @@ func (r *storeRepo) IsUserAdminStore(userId, storeId uint) (bool, error) {
 	return count > 0, nil
 }
 
+func (r *storeRepo) GetStoresByIds(ids []uint) ([]dto.Store, error) {
+	var stores []dto.Store
+	if err := r.db.Model(&entity.Store{}).Select("id", "name", "admin_id", "region", "version", "created_at").Where("id IN ?", ids).Find(&stores).Error; err != nil {
+		return nil, err
+	}
+
+	return stores, nil
+}
+
 func (r *storeRepo) GetMyStore(id uint) (*dto.Store, error) {
 	// Ambil data store
 	var store entity.Store
@@ -62,6 +72,8 @@ func (r *storeRepo) GetMyStore(id uint) (*dto.Store, error) {
 		ID:        store.ID,
 		Name:      store.Name,
 		AdminID:   store.AdminID,
+		Region:    store.Region,
+		Version:   store.Version,
 		CreatedAt: store.CreatedAt,
 	}, nil
 }
@@ -80,7 +92,7 @@ func (r *storeRepo) GetAllStore() ([]dto.Store, error) {
 
 	log.Println("data dari mysql")
 	var shops []dto.Store
-	if err := r.db.Model(&entity.Store{}).Select("id", "name", "admin_id", "created_at").Find(&shops).Error; err != nil {
+	if err := r.db.Model(&entity.Store{}).Select("id", "name", "admin_id", "region", "version", "created_at").Find(&shops).Error; err != nil {
 
 		return nil, err
 	}
@@ -97,6 +109,8 @@ func (r *storeRepo) CreateStore(req *dto.CreateStoreReq) (*dto.Store, error) {
 	newStore := entity.Store{
 		Name:    req.Name,
 		AdminID: req.AdminID,
+		Region:  req.Region,
+		Version: 1,
 	}
 
 	if err := r.db.Model(&entity.Store{}).Create(&newStore).Error; err != nil {
@@ -112,15 +126,34 @@ func (r *storeRepo) CreateStore(req *dto.CreateStoreReq) (*dto.Store, error) {
 		ID:        newStore.ID,
 		AdminID:   newStore.AdminID,
 		Name:      newStore.Name,
+		Region:    newStore.Region,
+		Version:   newStore.Version,
 		CreatedAt: time.Now(),
 	}, nil
 }
 
 func (r *storeRepo) UpdateStore(req *dto.UpdateStoreReq) (*dto.Store, error) {
-	if err := r.db.Model(&entity.Store{}).Where("id = ?", req.ID).Updates(map[string]interface{}{
-		"name": req.Name,
-	}).Error; err != nil {
-		return nil, err
+	values := map[string]interface{}{
+		"name":    req.Name,
+		"version": gorm.Expr("version + 1"),
+	}
+	if req.Region != "" {
+		values["region"] = req.Region
+	}
+	result := r.db.Model(&entity.Store{}).Where("id = ? AND version = ?", req.ID, req.Version).Updates(values)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		// store bisa saja dihapus setelah dicek, jangan dilaporkan sebagai konflik version
+		var count int64
+		if err := r.db.Model(&entity.Store{}).Where("id = ?", req.ID).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, utils.ErrNoStore
+		}
+		return nil, utils.ErrVersionConflict
 	}
 
 	key := fmt.Sprintln("store:all")
@@ -131,6 +164,8 @@ func (r *storeRepo) UpdateStore(req *dto.UpdateStoreReq) (*dto.Store, error) {
 		ID:      req.ID,
 		AdminID: req.UserID,
 		Name:    req.Name,
+		Region:  req.Region,
+		Version: req.Version + 1,
 	}, nil
 }
 
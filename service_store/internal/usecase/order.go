@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"service_store/dto"
+	"service_store/helper/utils"
+	"service_store/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/sony/gobreaker"
+)
+
+type OrderUsecase interface {
+	GetStoreOrders(filter *dto.StoreOrderFilter) ([]dto.StoreOrder, error)
+	GetStoreOrder(userId, storeId, orderId uint) (*dto.StoreOrder, error)
+	UpdateFulfilment(req *dto.FulfilmentReq) (*dto.StoreOrder, error)
+
+	//kafka
+	SaveStoreOrder(event *dto.StoreOrderEventKafka) error
+	WriteKafkaMessage(topic string, key string, payload interface{}) error
+}
+
+type orderUsecase struct {
+	orderRepo    repository.OrderRepo
+	storeRepo    repository.StoreRepo
+	kafka        map[string]*kafka.Writer
+	writeBreaker *gobreaker.CircuitBreaker
+}
+
+func NewOrderUsecase(orderRepo repository.OrderRepo, storeRepo repository.StoreRepo, kafka map[string]*kafka.Writer) OrderUsecase {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "OrderProducerBreaker",
+		MaxRequests: 5,
+		Interval:    30 * time.Second,
+		Timeout:     5 * time.Second,
+		OnStateChange: func(name string, from, to gobreaker.State) {
+			log.Printf("[Circuit Breaker: %s] status berubah dari %s ➜ %s\n", name, from.String(), to.String())
+		},
+	})
+
+	return &orderUsecase{orderRepo, storeRepo, kafka, cb}
+}
+
+func (u *orderUsecase) WriteKafkaMessage(topic string, key string, payload interface{}) error {
+	writer, ok := u.kafka[topic]
+	if !ok {
+		return utils.ErrNoTopic
+	}
+
+	value, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	msg := kafka.Message{
+		Key:   []byte(key),
+		Value: value,
+	}
+
+	_, err = u.writeBreaker.Execute(func() (interface{}, error) {
+		return nil, writer.WriteMessages(context.Background(), msg)
+	})
+
+	if err != nil {
+		return fmt.Errorf("kafka write failed or circuit open: %w", err)
+	}
+
+	return nil
+}
+
+func (u *orderUsecase) checkAdmin(userId, storeId uint) error {
+	valid, err := u.storeRepo.IsUserAdminStore(userId, storeId)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return utils.ErrNotAdmin
+	}
+	return nil
+}
+
+func (u *orderUsecase) SaveStoreOrder(event *dto.StoreOrderEventKafka) error {
+	return u.orderRepo.SaveStoreOrder(event)
+}
+
+func (u *orderUsecase) GetStoreOrders(filter *dto.StoreOrderFilter) ([]dto.StoreOrder, error) {
+	if err := u.checkAdmin(filter.UserID, filter.StoreID); err != nil {
+		return nil, err
+	}
+
+	return u.orderRepo.GetStoreOrders(filter)
+}
+
+func (u *orderUsecase) GetStoreOrder(userId, storeId, orderId uint) (*dto.StoreOrder, error) {
+	if err := u.checkAdmin(userId, storeId); err != nil {
+		return nil, err
+	}
+
+	return u.orderRepo.GetStoreOrder(storeId, orderId)
+}
+
+// UpdateFulfilment meneruskan perubahan status pengiriman ke service cart yang
+// memegang data shipment. Read model ikut diperbarui lewat event dari cart,
+// jadi response ini memakai data shipment dari balasan cart.
+func (u *orderUsecase) UpdateFulfilment(req *dto.FulfilmentReq) (*dto.StoreOrder, error) {
+	if err := u.checkAdmin(req.UserID, req.StoreID); err != nil {
+		return nil, err
+	}
+
+	order, err := u.orderRepo.GetStoreOrder(req.StoreID, req.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	corrID := uuid.NewString()
+	payload := map[string]interface{}{
+		"correlation_id":  corrID,
+		"store_id":        req.StoreID,
+		"order_id":        req.OrderID,
+		"status":          req.Status,
+		"tracking_number": req.TrackingNumber,
+	}
+	if err := u.WriteKafkaMessage("store-shipment-request", corrID, payload); err != nil {
+		return nil, utils.ErrFailedKafkaWrite
+	}
+
+	var reply dto.StoreShipmentReplyKafka
+	if err := u.storeRepo.WaitForResponse(corrID, &reply); err != nil {
+		return nil, err
+	}
+	if !reply.Success || reply.Shipment == nil {
+		log.Printf("order %d toko %d: fulfilment ditolak: %s", req.OrderID, req.StoreID, reply.Reason)
+		return nil, utils.ErrFulfilment
+	}
+
+	shipment := reply.Shipment
+	order.ShipmentID = shipment.ID
+	order.FulfilmentStatus = shipment.Status
+	order.TrackingNumber = shipment.TrackingNumber
+	order.PackedAt = shipment.PackedAt
+	order.ShippedAt = shipment.ShippedAt
+	order.DeliveredAt = shipment.DeliveredAt
+	return order, nil
+}
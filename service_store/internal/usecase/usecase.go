@@ -27,6 +27,7 @@ type StoreUsecase interface {
 	//kafka
 	GetMyStore(storeId uint) (*dto.StoreAndProduct, error)
 	SendValidationResponse(userId, storeId uint, correlationID string) error
+	SendStoresDetail(storeIds []uint, correlationID string) error
 	WriteKafkaMessage(topic string, key string, payload interface{}) error
 }
 
@@ -136,6 +137,11 @@ func (u *storeUsecase) CreateStore(req *dto.CreateStoreReq) error {
 func (u *storeUsecase) UpdateStore(req *dto.UpdateStoreReq) error {
 	corrId := uuid.NewString()
 
+	// store yang tidak ada dijawab 404, bukan 403 dari cek admin
+	if _, err := u.storeRepo.GetMyStore(req.ID); err != nil {
+		return err
+	}
+
 	valid, err := u.storeRepo.IsUserAdminStore(req.UserID, req.ID)
 	if err != nil {
 		return err
@@ -158,8 +164,10 @@ func (u *storeUsecase) UpdateStore(req *dto.UpdateStoreReq) error {
 		"message":        message,
 	}
 
+	// version sudah naik di db, gagal kirim notifikasi tidak boleh membuat PUT gagal
+	// karena client jadi tidak tahu ETag barunya
 	if err := u.WriteKafkaMessage("notification-request", corrId, payload); err != nil {
-		return utils.ErrFailedKafkaWrite
+		log.Printf("notifikasi update store %d gagal dikirim: %v", req.ID, err)
 	}
 
 	return nil
@@ -204,3 +212,22 @@ func (u *storeUsecase) SendValidationResponse(userId, storeId uint, correlationI
 
 	return nil
 }
+
+// SendStoresDetail dipakai service lain yang butuh nama toko, misalnya invoice order
+func (u *storeUsecase) SendStoresDetail(storeIds []uint, correlationID string) error {
+	stores := []dto.Store{}
+	if len(storeIds) > 0 {
+		found, err := u.storeRepo.GetStoresByIds(storeIds)
+		if err != nil {
+			return err
+		}
+		stores = found
+	}
+
+	payload := map[string]interface{}{
+		"correlation_id": correlationID,
+		"data":           stores,
+	}
+
+	return u.WriteKafkaMessage("stores-detail-response", correlationID, payload)
+}
@@ -0,0 +1,84 @@
+package kafkaconsumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"service_user/internal/usecase"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sony/gobreaker"
+)
+
+// consumer send response
+func AddressRequestConsumer(usecase usecase.AddressUsecase, breaker *gobreaker.CircuitBreaker) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   "address-request",
+		GroupID: "user-service",
+	})
+
+	go func() {
+		for {
+			msg, err := r.ReadMessage(context.Background())
+			if err != nil {
+				fmt.Println("error reading message:", err)
+				continue
+			}
+
+			var payload struct {
+				CorrelationID string `json:"correlation_id"`
+				UserID        uint   `json:"user_id"`
+				AddressID     uint   `json:"address_id"`
+			}
+			if err := json.Unmarshal(msg.Value, &payload); err != nil {
+				fmt.Println("error unmarshaling:", err)
+				continue
+			}
+
+			_, errBreaker := breaker.Execute(func() (interface{}, error) {
+				return nil, usecase.SendAddressResponse(payload.UserID, payload.AddressID, payload.CorrelationID)
+			})
+			if errBreaker != nil {
+				fmt.Println("send address failed or breaker open:", errBreaker)
+				continue
+			}
+		}
+	}()
+}
+
+func UserContactRequestConsumer(usecase usecase.AuthUsecase, breaker *gobreaker.CircuitBreaker) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   "user-contact-request",
+		GroupID: "user-service",
+	})
+
+	go func() {
+		for {
+			msg, err := r.ReadMessage(context.Background())
+			if err != nil {
+				fmt.Println("error reading message:", err)
+				continue
+			}
+
+			var payload struct {
+				CorrelationID string `json:"correlation_id"`
+				UserIDs       []uint `json:"user_ids"`
+			}
+			if err := json.Unmarshal(msg.Value, &payload); err != nil {
+				fmt.Println("error unmarshaling:", err)
+				continue
+			}
+
+			_, errBreaker := breaker.Execute(func() (interface{}, error) {
+				return nil, usecase.SendUserContactsResponse(payload.UserIDs, payload.CorrelationID)
+			})
+			if errBreaker != nil {
+				fmt.Println("send user contact failed or breaker open:", errBreaker)
+				continue
+			}
+		}
+	}()
+}
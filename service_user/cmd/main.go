@@ -6,13 +6,16 @@ import (
 	"net/http"
 	"os"
 	"service_user/cmd/database"
+	kafkaconsumer "service_user/cmd/kafka_consumer"
 	"service_user/cmd/route"
+	"time"
 
 	"service_user/internal/handler"
 	"service_user/internal/repository"
 	"service_user/internal/usecase"
 
 	"github.com/segmentio/kafka-go"
+	"github.com/sony/gobreaker"
 )
 
 func main() {
@@ -30,11 +33,47 @@ func main() {
 			Topic:    "notification-request",
 			Balancer: &kafka.LeastBytes{},
 		}),
+		"guest-cart-merge": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "guest-cart-merge",
+			Balancer: &kafka.LeastBytes{},
+		}),
+		"address-response": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "address-response",
+			Balancer: &kafka.LeastBytes{},
+		}),
+		"user-locale-updated": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "user-locale-updated",
+			Balancer: &kafka.Hash{},
+		}),
+		"user-contact-response": kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  []string{os.Getenv("KAFKA_BROKER")},
+			Topic:    "user-contact-response",
+			Balancer: &kafka.LeastBytes{},
+		}),
 	}
 	authUC := usecase.NewAuthUsecase(authRepo, writer)
 	authDelivery := handler.NewAuthHandler(authUC)
 
-	r := route.SetupRoute(authDelivery)
+	addressRepo := repository.NewAddressRepo(db)
+	addressUC := usecase.NewAddressUsecase(addressRepo, writer)
+	addressHandler := handler.NewAddressHandler(addressUC)
+
+	breaker := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "ConsumerBreaker",
+		MaxRequests: 3,
+		Interval:    20 * time.Second,
+		Timeout:     5 * time.Second,
+		OnStateChange: func(name string, from, to gobreaker.State) {
+			log.Printf("[Circuit Breaker: %s] status berubah dari %s ➜ %s\n", name, from.String(), to.String())
+		},
+	})
+	go kafkaconsumer.AddressRequestConsumer(addressUC, breaker)
+	go kafkaconsumer.UserContactRequestConsumer(authUC, breaker)
+
+	r := route.SetupRoute(authDelivery, addressHandler)
 
 	port := os.Getenv("PORT")
 	if port == "" {
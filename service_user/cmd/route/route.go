@@ -2,16 +2,31 @@ package route
 
 import (
 	"net/http"
+	"service_user/helper/middleware"
 	"service_user/internal/handler"
 
 	"github.com/gorilla/mux"
 )
 
-func SetupRoute(user *handler.AuthHandler) *mux.Router {
+func SetupRoute(user *handler.AuthHandler, address *handler.AddressHandler) *mux.Router {
 	r := mux.NewRouter()
 
 	r.HandleFunc("/login", user.Login).Methods(http.MethodPost)
 	r.HandleFunc("/register", user.Register).Methods(http.MethodPost)
 
+	userM := r.PathPrefix("/user").Subrouter()
+	userM.Use(middleware.AuthMiddleware)
+
+	userM.HandleFunc("/locale", user.UpdateLocale).Methods(http.MethodPut)
+
+	addressM := r.PathPrefix("/address").Subrouter()
+	addressM.Use(middleware.AuthMiddleware)
+
+	addressM.HandleFunc("/create", address.CreateAddress).Methods(http.MethodPost)
+	addressM.HandleFunc("/me", address.GetMyAddresses).Methods(http.MethodGet)
+	addressM.HandleFunc("/update/{addressId}", address.UpdateAddress).Methods(http.MethodPut)
+	addressM.HandleFunc("/delete/{addressId}", address.DeleteAddress).Methods(http.MethodDelete)
+	addressM.HandleFunc("/default/{addressId}", address.SetDefaultAddress).Methods(http.MethodPut)
+
 	return r
 }
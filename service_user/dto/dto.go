@@ -5,9 +5,54 @@ type RegisterReq struct {
 	Name     string `json:"username"`
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	Locale   string `json:"locale"`
+}
+
+type LocaleReq struct {
+	UserID uint   `json:"-"`
+	Email  string `json:"-"`
+	Locale string `json:"locale"`
 }
 
 type LoginReq struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+
+	//cart guest yang digabung ke cart user setelah login
+	GuestToken string `json:"guest_token,omitempty"`
+}
+
+// kontak user untuk service lain, tanpa password
+type UserContact struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Locale   string `json:"locale"`
+}
+
+// address
+type AddressReq struct {
+	UserID     uint   `json:"-"`
+	ID         uint   `json:"-"`
+	Label      string `json:"label"`
+	Recipient  string `json:"recipient"`
+	Phone      string `json:"phone"`
+	Street     string `json:"street"`
+	City       string `json:"city"`
+	Province   string `json:"province"`
+	PostalCode string `json:"postal_code"`
+	IsDefault  bool   `json:"is_default"`
+}
+
+type Address struct {
+	ID         uint   `json:"id"`
+	UserID     uint   `json:"user_id"`
+	Label      string `json:"label"`
+	Recipient  string `json:"recipient"`
+	Phone      string `json:"phone"`
+	Street     string `json:"street"`
+	City       string `json:"city"`
+	Province   string `json:"province"`
+	PostalCode string `json:"postal_code"`
+	IsDefault  bool   `json:"is_default"`
 }
@@ -1,8 +1,29 @@
 package entity
 
+import "time"
+
 type User struct {
 	ID       uint   `gorm:"primaryKey"`
 	Username string `gorm:"unique;not null"`
 	Email    string `gorm:"unique;not null"`
 	Password string `gorm:"not null"`
+
+	//bahasa email notifikasi, "id" atau "en"
+	Locale string `gorm:"type:varchar(5);not null;default:'id'"`
+}
+
+// buku alamat pengiriman, satu alamat default per user
+type Address struct {
+	ID         uint   `gorm:"primaryKey"`
+	UserID     uint   `gorm:"index"`
+	Label      string `gorm:"type:varchar(50);not null"`
+	Recipient  string `gorm:"type:varchar(100);not null"`
+	Phone      string `gorm:"type:varchar(20);not null"`
+	Street     string `gorm:"type:varchar(255);not null"`
+	City       string `gorm:"type:varchar(100);not null"`
+	Province   string `gorm:"type:varchar(100);not null"`
+	PostalCode string `gorm:"type:varchar(10);not null"`
+	IsDefault  bool   `gorm:"not null;default:false"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
 }
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"service_user/helper/utils"
+
+	"strings"
+)
+
+type key int
+
+const UserContextKey key = 0
+
+func AuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader := r.Header.Get("Authorization")
+		if authHeader == "" {
+			utils.WriteError(w, http.StatusUnauthorized, "tak ada token")
+			return
+		}
+
+		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+		claims, err := utils.ValidateJWT(tokenString)
+		if err != nil {
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), UserContextKey, claims)
+		next.ServeHTTP(w, r.WithContext(ctx))
+
+	})
+}
@@ -8,4 +8,6 @@ var (
 	ErrInvalidWriter     = errors.New("writer salah")
 	ErrFailedKafkaWriter = errors.New("gagal writer kafka")
 	ErrNoTopic           = errors.New("bukan ada topic ini")
+	ErrAddressNotFound   = errors.New("alamat tidak ditemukan")
+	ErrInvalidLocale     = errors.New("locale harus id atau en")
 )
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"service_user/dto"
+	"service_user/helper/middleware"
+	"service_user/helper/utils"
+	"service_user/internal/usecase"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+type AddressHandler struct {
+	addressUsecase usecase.AddressUsecase
+}
+
+func NewAddressHandler(addressUsecase usecase.AddressUsecase) *AddressHandler {
+	return &AddressHandler{addressUsecase}
+}
+
+// validateAddress mengembalikan pesan error kosong kalau body valid
+func validateAddress(req *dto.AddressReq) string {
+	required := map[string]string{
+		"label":       req.Label,
+		"recipient":   req.Recipient,
+		"phone":       req.Phone,
+		"street":      req.Street,
+		"city":        req.City,
+		"province":    req.Province,
+		"postal_code": req.PostalCode,
+	}
+	for _, field := range []string{"label", "recipient", "phone", "street", "city", "province", "postal_code"} {
+		if strings.TrimSpace(required[field]) == "" {
+			return field + " wajib diisi"
+		}
+	}
+	if len(req.Label) > 50 || len(req.Recipient) > 100 || len(req.Phone) > 20 || len(req.Street) > 255 ||
+		len(req.City) > 100 || len(req.Province) > 100 || len(req.PostalCode) > 10 {
+		return "isi alamat terlalu panjang"
+	}
+	return ""
+}
+
+func (h *AddressHandler) CreateAddress(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	var req dto.AddressReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	if msg := validateAddress(&req); msg != "" {
+		utils.WriteError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	req.UserID = claims.UserID
+	response, err := h.addressUsecase.CreateAddress(&req)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *AddressHandler) GetMyAddresses(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	response, err := h.addressUsecase.GetMyAddresses(claims.UserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *AddressHandler) UpdateAddress(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsAddressId, err := strconv.Atoi(params["addressId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	var req dto.AddressReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	if msg := validateAddress(&req); msg != "" {
+		utils.WriteError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	req.ID = uint(paramsAddressId)
+	req.UserID = claims.UserID
+	response, err := h.addressUsecase.UpdateAddress(&req)
+	if err != nil {
+		switch err {
+		case utils.ErrAddressNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *AddressHandler) DeleteAddress(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsAddressId, err := strconv.Atoi(params["addressId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	if err := h.addressUsecase.DeleteAddress(claims.UserID, uint(paramsAddressId)); err != nil {
+		switch err {
+		case utils.ErrAddressNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
+
+func (h *AddressHandler) SetDefaultAddress(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	params := mux.Vars(r)
+	paramsAddressId, err := strconv.Atoi(params["addressId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "params tidak ditemukan")
+		return
+	}
+
+	if err := h.addressUsecase.SetDefaultAddress(claims.UserID, uint(paramsAddressId)); err != nil {
+		switch err {
+		case utils.ErrAddressNotFound:
+			utils.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
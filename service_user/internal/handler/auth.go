@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"net/http"
 	"service_user/dto"
+	"service_user/helper/middleware"
 	"service_user/helper/utils"
 	"service_user/internal/usecase"
+	"strings"
 )
 
 type AuthHandler struct {
@@ -16,6 +18,18 @@ func NewAuthHandler(authUsecase usecase.AuthUsecase) *AuthHandler {
 	return &AuthHandler{authUsecase}
 }
 
+// normalizeLocale menerima bahasa yang punya template email, kosong berarti "id"
+func normalizeLocale(locale string) (string, bool) {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	switch locale {
+	case "":
+		return "id", true
+	case "id", "en":
+		return locale, true
+	}
+	return "", false
+}
+
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req dto.LoginReq
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -47,6 +61,13 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	locale, ok := normalizeLocale(req.Locale)
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrInvalidLocale.Error())
+		return
+	}
+	req.Locale = locale
+
 	if err := h.authUsecase.Register(&req); err != nil {
 		switch err {
 		case utils.ErrInvalidEmail:
@@ -60,3 +81,35 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 	utils.WriteJSON(w, http.StatusOK, nil)
 }
+
+func (h *AuthHandler) UpdateLocale(w http.ResponseWriter, r *http.Request) {
+	claimsRaw := r.Context().Value(middleware.UserContextKey)
+	claims, ok := claimsRaw.(*utils.JWTCLAIMS)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "protected api")
+		return
+	}
+
+	var req dto.LocaleReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	locale, ok := normalizeLocale(req.Locale)
+	if !ok || req.Locale == "" {
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrInvalidLocale.Error())
+		return
+	}
+
+	req.UserID = claims.UserID
+	req.Email = claims.Email
+	req.Locale = locale
+	if err := h.authUsecase.UpdateLocale(&req); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]string{
+		"locale": locale,
+	})
+}
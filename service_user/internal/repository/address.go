@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"errors"
+	"service_user/dto"
+	"service_user/entity"
+	"service_user/helper/utils"
+
+	"gorm.io/gorm"
+)
+
+type AddressRepo interface {
+	CreateAddress(req *dto.AddressReq) (*dto.Address, error)
+	GetMyAddresses(userId uint) ([]dto.Address, error)
+	GetAddress(userId, id uint) (*dto.Address, error)
+	UpdateAddress(req *dto.AddressReq) (*dto.Address, error)
+	DeleteAddress(userId, id uint) error
+	SetDefaultAddress(userId, id uint) error
+}
+
+type addressRepo struct {
+	db *gorm.DB
+}
+
+func NewAddressRepo(db *gorm.DB) AddressRepo {
+	return &addressRepo{db}
+}
+
+func toAddressDTO(a *entity.Address) dto.Address {
+	return dto.Address{
+		ID:         a.ID,
+		UserID:     a.UserID,
+		Label:      a.Label,
+		Recipient:  a.Recipient,
+		Phone:      a.Phone,
+		Street:     a.Street,
+		City:       a.City,
+		Province:   a.Province,
+		PostalCode: a.PostalCode,
+		IsDefault:  a.IsDefault,
+	}
+}
+
+func clearDefault(tx *gorm.DB, userId uint) error {
+	return tx.Model(&entity.Address{}).Where("user_id = ? AND is_default = ?", userId, true).Update("is_default", false).Error
+}
+
+// alamat pertama user otomatis jadi default
+func (r *addressRepo) CreateAddress(req *dto.AddressReq) (*dto.Address, error) {
+	address := entity.Address{
+		UserID:     req.UserID,
+		Label:      req.Label,
+		Recipient:  req.Recipient,
+		Phone:      req.Phone,
+		Street:     req.Street,
+		City:       req.City,
+		Province:   req.Province,
+		PostalCode: req.PostalCode,
+		IsDefault:  req.IsDefault,
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&entity.Address{}).Where("user_id = ?", req.UserID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			address.IsDefault = true
+		}
+		if address.IsDefault {
+			if err := clearDefault(tx, req.UserID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&address).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toAddressDTO(&address)
+	return &response, nil
+}
+
+func (r *addressRepo) GetMyAddresses(userId uint) ([]dto.Address, error) {
+	var addresses []entity.Address
+	if err := r.db.Where("user_id = ?", userId).Order("is_default DESC, id ASC").Find(&addresses).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.Address, 0, len(addresses))
+	for i := range addresses {
+		result = append(result, toAddressDTO(&addresses[i]))
+	}
+
+	return result, nil
+}
+
+func (r *addressRepo) GetAddress(userId, id uint) (*dto.Address, error) {
+	var address entity.Address
+	if err := r.db.Where("id = ? AND user_id = ?", id, userId).First(&address).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrAddressNotFound
+		}
+		return nil, err
+	}
+
+	response := toAddressDTO(&address)
+	return &response, nil
+}
+
+func (r *addressRepo) UpdateAddress(req *dto.AddressReq) (*dto.Address, error) {
+	var address entity.Address
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND user_id = ?", req.ID, req.UserID).First(&address).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.ErrAddressNotFound
+			}
+			return err
+		}
+
+		// default hanya bisa dipindah, tidak bisa dilepas lewat update
+		isDefault := address.IsDefault || req.IsDefault
+		if req.IsDefault && !address.IsDefault {
+			if err := clearDefault(tx, req.UserID); err != nil {
+				return err
+			}
+		}
+
+		address.Label = req.Label
+		address.Recipient = req.Recipient
+		address.Phone = req.Phone
+		address.Street = req.Street
+		address.City = req.City
+		address.Province = req.Province
+		address.PostalCode = req.PostalCode
+		address.IsDefault = isDefault
+		return tx.Save(&address).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toAddressDTO(&address)
+	return &response, nil
+}
+
+// kalau alamat default dihapus, alamat tertua berikutnya jadi default
+func (r *addressRepo) DeleteAddress(userId, id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var address entity.Address
+		if err := tx.Where("id = ? AND user_id = ?", id, userId).First(&address).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.ErrAddressNotFound
+			}
+			return err
+		}
+
+		if err := tx.Delete(&address).Error; err != nil {
+			return err
+		}
+		if !address.IsDefault {
+			return nil
+		}
+
+		var next entity.Address
+		err := tx.Where("user_id = ?", userId).Order("id ASC").First(&next).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&next).Update("is_default", true).Error
+	})
+}
+
+func (r *addressRepo) SetDefaultAddress(userId, id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&entity.Address{}).Where("id = ? AND user_id = ?", id, userId).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return utils.ErrAddressNotFound
+		}
+
+		if err := clearDefault(tx, userId); err != nil {
+			return err
+		}
+		return tx.Model(&entity.Address{}).Where("id = ?", id).Update("is_default", true).Error
+	})
+}
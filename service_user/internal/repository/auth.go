@@ -10,6 +10,8 @@ import (
 type AuthRepo interface {
 	Register(req *dto.RegisterReq) error
 	LoginEmail(email string) (*entity.User, error)
+	GetUserContacts(ids []uint) ([]dto.UserContact, error)
+	UpdateLocale(userId uint, locale string) error
 }
 
 type authRepo struct {
@@ -25,6 +27,7 @@ func (r *authRepo) Register(req *dto.RegisterReq) error {
 		Email:    req.Email,
 		Password: req.Password,
 		Username: req.Name,
+		Locale:   req.Locale,
 	}
 
 	return r.db.Model(&entity.User{}).Create(&newUser).Error
@@ -37,3 +40,25 @@ func (r *authRepo) LoginEmail(email string) (*entity.User, error) {
 	}
 	return &user, nil
 }
+
+func (r *authRepo) GetUserContacts(ids []uint) ([]dto.UserContact, error) {
+	var users []entity.User
+	if err := r.db.Model(&entity.User{}).Select("id", "username", "email", "locale").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.UserContact, 0, len(users))
+	for _, user := range users {
+		result = append(result, dto.UserContact{
+			ID:       user.ID,
+			Username: user.Username,
+			Email:    user.Email,
+			Locale:   user.Locale,
+		})
+	}
+	return result, nil
+}
+
+func (r *authRepo) UpdateLocale(userId uint, locale string) error {
+	return r.db.Model(&entity.User{}).Where("id = ?", userId).Update("locale", locale).Error
+}
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"service_user/dto"
+	"service_user/helper/utils"
+	"service_user/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sony/gobreaker"
+)
+
+type AddressUsecase interface {
+	CreateAddress(req *dto.AddressReq) (*dto.Address, error)
+	GetMyAddresses(userId uint) ([]dto.Address, error)
+	UpdateAddress(req *dto.AddressReq) (*dto.Address, error)
+	DeleteAddress(userId, id uint) error
+	SetDefaultAddress(userId, id uint) error
+
+	//kafka
+	SendAddressResponse(userId, addressId uint, correlationID string) error
+	WriteKafkaMessage(topic string, key string, payload interface{}) error
+}
+
+type addressUsecase struct {
+	addressRepo  repository.AddressRepo
+	kafka        map[string]*kafka.Writer
+	writeBreaker *gobreaker.CircuitBreaker
+}
+
+func NewAddressUsecase(addressRepo repository.AddressRepo, kafka map[string]*kafka.Writer) AddressUsecase {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "AddressProducerBreaker",
+		MaxRequests: 5,
+		Interval:    30 * time.Second,
+		Timeout:     5 * time.Second,
+		OnStateChange: func(name string, from, to gobreaker.State) {
+			log.Printf("[Circuit Breaker: %s] status berubah dari %s ➜ %s\n", name, from.String(), to.String())
+		},
+	})
+	return &addressUsecase{addressRepo, kafka, cb}
+}
+
+func (u *addressUsecase) WriteKafkaMessage(topic string, key string, payload interface{}) error {
+	writer, ok := u.kafka[topic]
+	if !ok {
+		return utils.ErrNoTopic
+	}
+
+	value, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	msg := kafka.Message{
+		Key:   []byte(key),
+		Value: value,
+	}
+
+	_, err = u.writeBreaker.Execute(func() (interface{}, error) {
+		return nil, writer.WriteMessages(context.Background(), msg)
+	})
+
+	if err != nil {
+		return fmt.Errorf("kafka write failed or circuit open: %w", err)
+	}
+
+	return nil
+}
+
+func trimAddress(req *dto.AddressReq) {
+	req.Label = strings.TrimSpace(req.Label)
+	req.Recipient = strings.TrimSpace(req.Recipient)
+	req.Phone = strings.TrimSpace(req.Phone)
+	req.Street = strings.TrimSpace(req.Street)
+	req.City = strings.TrimSpace(req.City)
+	req.Province = strings.TrimSpace(req.Province)
+	req.PostalCode = strings.TrimSpace(req.PostalCode)
+}
+
+func (u *addressUsecase) CreateAddress(req *dto.AddressReq) (*dto.Address, error) {
+	trimAddress(req)
+	return u.addressRepo.CreateAddress(req)
+}
+
+func (u *addressUsecase) GetMyAddresses(userId uint) ([]dto.Address, error) {
+	return u.addressRepo.GetMyAddresses(userId)
+}
+
+func (u *addressUsecase) UpdateAddress(req *dto.AddressReq) (*dto.Address, error) {
+	trimAddress(req)
+	return u.addressRepo.UpdateAddress(req)
+}
+
+func (u *addressUsecase) DeleteAddress(userId, id uint) error {
+	return u.addressRepo.DeleteAddress(userId, id)
+}
+
+func (u *addressUsecase) SetDefaultAddress(userId, id uint) error {
+	return u.addressRepo.SetDefaultAddress(userId, id)
+}
+
+// SendAddressResponse dipakai checkout service cart. addressId 0 berarti alamat default,
+// data null kalau alamat tidak ada atau bukan milik user
+func (u *addressUsecase) SendAddressResponse(userId, addressId uint, correlationID string) error {
+	var address *dto.Address
+	if addressId == 0 {
+		addresses, err := u.addressRepo.GetMyAddresses(userId)
+		if err != nil {
+			return err
+		}
+		if len(addresses) > 0 && addresses[0].IsDefault {
+			address = &addresses[0]
+		}
+	} else {
+		found, err := u.addressRepo.GetAddress(userId, addressId)
+		if err != nil && err != utils.ErrAddressNotFound {
+			return err
+		}
+		address = found
+	}
+
+	payload := map[string]interface{}{
+		"correlation_id": correlationID,
+		"data":           address,
+	}
+
+	return u.WriteKafkaMessage("address-response", correlationID, payload)
+}
@@ -19,6 +19,10 @@ import (
 type AuthUsecase interface {
 	Register(req *dto.RegisterReq) error
 	Login(req *dto.LoginReq) (string, error)
+	UpdateLocale(req *dto.LocaleReq) error
+
+	//kafka
+	SendUserContactsResponse(userIds []uint, correlationID string) error
 }
 
 type authUsecase struct {
@@ -82,14 +86,19 @@ func (u *authUsecase) Register(req *dto.RegisterReq) error {
 		return err
 	}
 
+	if err := u.publishLocale(req.Email, req.Locale); err != nil {
+		return err
+	}
+
 	corrId := uuid.NewString()
 
 	data := map[string]interface{}{
 		"correlation_id": corrId,
 		"email":          req.Email,
+		"locale":         req.Locale,
 		"service":        "user",
 		"action":         "register",
-		"message":        req.Email,
+		"message":        req.Name,
 	}
 	if err := u.WriteKafkaMessage("notification-request", corrId, data); err != nil {
 		return err
@@ -117,5 +126,49 @@ func (u *authUsecase) Login(req *dto.LoginReq) (string, error) {
 		return "", err
 	}
 
+	if req.GuestToken != "" {
+		payload := map[string]interface{}{
+			"user_id":     user.ID,
+			"email":       user.Email,
+			"guest_token": req.GuestToken,
+		}
+		// login tetap berhasil walau merge gagal, user bisa merge manual di service cart
+		if err := u.WriteKafkaMessage("guest-cart-merge", uuid.NewString(), payload); err != nil {
+			log.Printf("publish guest cart merge: %v", err)
+		}
+	}
+
 	return jwt, nil
 }
+
+// publishLocale memberi tahu service notification bahasa email untuk alamat ini
+func (u *authUsecase) publishLocale(email, locale string) error {
+	payload := map[string]interface{}{
+		"email":  email,
+		"locale": locale,
+	}
+	return u.WriteKafkaMessage("user-locale-updated", email, payload)
+}
+
+func (u *authUsecase) UpdateLocale(req *dto.LocaleReq) error {
+	if err := u.authRepo.UpdateLocale(req.UserID, req.Locale); err != nil {
+		return err
+	}
+
+	return u.publishLocale(req.Email, req.Locale)
+}
+
+// SendUserContactsResponse dipakai service lain yang perlu mengirim notifikasi ke user tertentu
+func (u *authUsecase) SendUserContactsResponse(userIds []uint, correlationID string) error {
+	contacts, err := u.authRepo.GetUserContacts(userIds)
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"correlation_id": correlationID,
+		"data":           contacts,
+	}
+
+	return u.WriteKafkaMessage("user-contact-response", correlationID, payload)
+}